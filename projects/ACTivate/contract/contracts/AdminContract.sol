@@ -16,6 +16,38 @@ contract DataContract {
         string topic
     );
 
+    // Compact notification: the payload is raw bytes instead of a hex string,
+    // and what it carries is told by a typed version
+    event DataSentToTargetV2(
+        address indexed from,
+        address indexed to,
+        uint8 version,
+        bytes publisherKey,
+        bytes32 historyRef,
+        bytes payload
+    );
+
+    // Length of a compressed secp256k1 public key
+    uint256 internal constant PUBLISHER_KEY_LENGTH = 33;
+
+    // Upper bound on targets per call, keeps a batch well inside the block gas limit
+    uint256 internal constant MAX_TARGETS = 100;
+
+    // EIP-712 typed request a sender signs so a relayer can pay for the event
+    bytes32 public constant SEND_DATA_TYPEHASH = keccak256(
+        "SendDataToTarget(address from,address target,bytes32 owner,bytes32 actref,string topic,uint256 nonce,uint256 deadline)"
+    );
+    bytes32 private constant DOMAIN_TYPEHASH = keccak256(
+        "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+    );
+    bytes32 private constant NAME_HASH = keccak256("DataContract");
+    bytes32 private constant VERSION_HASH = keccak256("1");
+    // secp256k1n / 2, signatures with a higher s are malleable copies of valid ones
+    uint256 private constant MAX_SIGNATURE_S = 0x7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF5D576E7357A4501DDFE92F46681B20A0;
+
+    // Next signed request nonce of each sender, a request is only relayed once
+    mapping(address => uint256) public nonces;
+
     /**
      * @dev Constructor - no special initialization needed
      */
@@ -42,4 +74,157 @@ contract DataContract {
         // Emit event with the data (from = msg.sender, the actual caller)
         emit DataSentToTarget(msg.sender, target, ownerParam, actref, topic);
     }
+
+    /**
+     * @dev Public function to emit data to several targets in one transaction,
+     * one DataSentToTarget event per target
+     * Anyone can call this function
+     * @param targets The target addresses
+     * @param ownerParam First 32-byte data parameter representing owner, shared by all events
+     * @param actref Second 32-byte data parameter representing action reference, shared by all events
+     * @param topics One topic per target, so each can be encrypted for its own target
+     */
+    function sendDataToTargets(
+        address[] calldata targets,
+        bytes32 ownerParam,
+        bytes32 actref,
+        string[] calldata topics
+    ) external {
+        require(targets.length > 0, "DataContract: no targets");
+        require(targets.length <= MAX_TARGETS, "DataContract: too many targets");
+        require(targets.length == topics.length, "DataContract: targets and topics length mismatch");
+
+        for (uint256 i = 0; i < targets.length; i++) {
+            require(targets[i] != address(0), "DataContract: target cannot be zero address");
+            emit DataSentToTarget(msg.sender, targets[i], ownerParam, actref, topics[i]);
+        }
+    }
+
+    /**
+     * @dev Emits a compact notification to a target
+     * Anyone can call this function
+     * @param target The target address
+     * @param version Format of the payload, never zero
+     * @param publisherKey The publisher's 33-byte compressed public key, or empty when the payload hides it
+     * @param historyRef The ACT history reference, or zero when the payload hides it
+     * @param payload The notification
+     */
+    function sendDataToTargetV2(
+        address target,
+        uint8 version,
+        bytes calldata publisherKey,
+        bytes32 historyRef,
+        bytes calldata payload
+    ) external {
+        _checkV2(version, publisherKey);
+        require(target != address(0), "DataContract: target cannot be zero address");
+        emit DataSentToTargetV2(msg.sender, target, version, publisherKey, historyRef, payload);
+    }
+
+    /**
+     * @dev Emits a compact notification to several targets in one transaction,
+     * one DataSentToTargetV2 event per target
+     * Anyone can call this function
+     * @param targets The target addresses
+     * @param version Format of the payloads, shared by all events
+     * @param publisherKey The publisher's compressed public key or empty, shared by all events
+     * @param historyRef The ACT history reference or zero, shared by all events
+     * @param payloads One payload per target, so each can be encrypted for its own target
+     */
+    function sendDataToTargetsV2(
+        address[] calldata targets,
+        uint8 version,
+        bytes calldata publisherKey,
+        bytes32 historyRef,
+        bytes[] calldata payloads
+    ) external {
+        _checkV2(version, publisherKey);
+        require(targets.length > 0, "DataContract: no targets");
+        require(targets.length <= MAX_TARGETS, "DataContract: too many targets");
+        require(targets.length == payloads.length, "DataContract: targets and payloads length mismatch");
+
+        for (uint256 i = 0; i < targets.length; i++) {
+            require(targets[i] != address(0), "DataContract: target cannot be zero address");
+            emit DataSentToTargetV2(msg.sender, targets[i], version, publisherKey, historyRef, payloads[i]);
+        }
+    }
+
+    function _checkV2(uint8 version, bytes calldata publisherKey) private pure {
+        require(version != 0, "DataContract: version cannot be zero");
+        require(
+            publisherKey.length == 0 || publisherKey.length == PUBLISHER_KEY_LENGTH,
+            "DataContract: publisher key must be empty or 33 bytes"
+        );
+    }
+
+    /**
+     * @dev EIP-712 domain separator of the signed requests on this chain
+     */
+    function DOMAIN_SEPARATOR() public view returns (bytes32) {
+        return keccak256(abi.encode(DOMAIN_TYPEHASH, NAME_HASH, VERSION_HASH, block.chainid, address(this)));
+    }
+
+    /**
+     * @dev Emits data to a target on behalf of a sender who signed the request,
+     * so a relayer can pay the gas. The event's from is the signer, not the relayer.
+     * Anyone can call this function
+     * @param from The sender who signed the request
+     * @param target The target address
+     * @param ownerParam First 32-byte data parameter representing owner
+     * @param actref Second 32-byte data parameter representing action reference
+     * @param topic String parameter for the topic
+     * @param deadline Timestamp after which the request can no longer be relayed
+     * @param signature The sender's 65-byte EIP-712 signature of the request with its current nonce
+     */
+    function sendDataToTargetWithSig(
+        address from,
+        address target,
+        bytes32 ownerParam,
+        bytes32 actref,
+        string calldata topic,
+        uint256 deadline,
+        bytes calldata signature
+    ) external {
+        require(block.timestamp <= deadline, "DataContract: request expired");
+        require(target != address(0), "DataContract: target cannot be zero address");
+
+        bytes32 digest = _sendDataDigest(from, target, ownerParam, actref, keccak256(bytes(topic)), deadline);
+        require(from != address(0) && _recover(digest, signature) == from, "DataContract: invalid signature");
+
+        emit DataSentToTarget(from, target, ownerParam, actref, topic);
+    }
+
+    /**
+     * @dev EIP-712 digest of a request, using up the sender's current nonce
+     */
+    function _sendDataDigest(
+        address from,
+        address target,
+        bytes32 ownerParam,
+        bytes32 actref,
+        bytes32 topicHash,
+        uint256 deadline
+    ) private returns (bytes32) {
+        bytes32 structHash = keccak256(
+            abi.encode(SEND_DATA_TYPEHASH, from, target, ownerParam, actref, topicHash, nonces[from]++, deadline)
+        );
+        return keccak256(abi.encodePacked("\x19\x01", DOMAIN_SEPARATOR(), structHash));
+    }
+
+    /**
+     * @dev Recovers the signer of a digest, the zero address for malformed or
+     * malleable signatures
+     */
+    function _recover(bytes32 digest, bytes calldata signature) private pure returns (address) {
+        if (signature.length != 65) {
+            return address(0);
+        }
+        bytes32 r = bytes32(signature[0:32]);
+        bytes32 s = bytes32(signature[32:64]);
+        uint8 v = uint8(signature[64]);
+        if (uint256(s) > MAX_SIGNATURE_S || (v != 27 && v != 28)) {
+            return address(0);
+        }
+        return ecrecover(digest, v, r, s);
+    }
 }
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+/**
+ * @title GroupRegistry
+ * @dev Where members of a group find its current ACT state. A group is
+ * published by its admin, the Swarm node whose key publishes the ACT, and
+ * only the admin can update it. Group IDs are derived from the admin's
+ * address, so nobody can take another admin's group.
+ */
+contract GroupRegistry {
+    struct Group {
+        address admin;
+        uint64 version;
+        bytes32 historyRef;
+        // 64-byte uncompressed public key of the admin, members download with it
+        bytes adminKey;
+        // encrypted grantee list reference, only the admin can read it
+        bytes granteeListRef;
+    }
+
+    mapping(bytes32 => Group) private groups;
+
+    event GroupCreated(bytes32 indexed groupId, address indexed admin, bytes32 salt);
+    event GroupUpdated(bytes32 indexed groupId, uint64 version, bytes32 historyRef, bytes granteeListRef);
+    event AdminTransferred(bytes32 indexed groupId, address indexed previousAdmin, address indexed newAdmin);
+
+    modifier onlyAdmin(bytes32 id) {
+        require(groups[id].admin != address(0), "GroupRegistry: unknown group");
+        require(groups[id].admin == msg.sender, "GroupRegistry: caller is not the admin");
+        _;
+    }
+
+    /**
+     * @dev ID of the group an admin creates with a salt
+     */
+    function groupId(address admin, bytes32 salt) public pure returns (bytes32) {
+        return keccak256(abi.encode(admin, salt));
+    }
+
+    /**
+     * @dev Creates a group with the caller as admin
+     * @param salt Tells the caller's groups apart
+     * @param adminKey The caller's 64-byte uncompressed public key
+     * @param historyRef Reference of the group's ACT history
+     * @param granteeListRef Encrypted reference of the group's grantee list
+     * @return id The new group's ID, groupId(msg.sender, salt)
+     */
+    function createGroup(
+        bytes32 salt,
+        bytes calldata adminKey,
+        bytes32 historyRef,
+        bytes calldata granteeListRef
+    ) external returns (bytes32 id) {
+        require(_keyAddress(adminKey) == msg.sender, "GroupRegistry: admin key is not the caller's");
+        id = groupId(msg.sender, salt);
+        Group storage group = groups[id];
+        require(group.admin == address(0), "GroupRegistry: group exists");
+
+        group.admin = msg.sender;
+        group.adminKey = adminKey;
+        emit GroupCreated(id, msg.sender, salt);
+        _update(group, id, historyRef, granteeListRef);
+    }
+
+    /**
+     * @dev Publishes the group's new ACT state, after grantees were added or removed
+     * Only the admin can call this function
+     */
+    function updateGroup(bytes32 id, bytes32 historyRef, bytes calldata granteeListRef) external onlyAdmin(id) {
+        _update(groups[id], id, historyRef, granteeListRef);
+    }
+
+    /**
+     * @dev Hands the group to another node, which publishes its next ACT state
+     * Only the admin can call this function
+     * @param newAdminKey The new admin's 64-byte uncompressed public key
+     */
+    function transferAdmin(bytes32 id, bytes calldata newAdminKey) external onlyAdmin(id) {
+        address newAdmin = _keyAddress(newAdminKey);
+        Group storage group = groups[id];
+        emit AdminTransferred(id, group.admin, newAdmin);
+        group.admin = newAdmin;
+        group.adminKey = newAdminKey;
+    }
+
+    /**
+     * @dev Current state of a group
+     */
+    function getGroup(bytes32 id)
+        external
+        view
+        returns (address admin, bytes memory adminKey, uint64 version, bytes32 historyRef, bytes memory granteeListRef)
+    {
+        Group storage group = groups[id];
+        require(group.admin != address(0), "GroupRegistry: unknown group");
+        return (group.admin, group.adminKey, group.version, group.historyRef, group.granteeListRef);
+    }
+
+    function _update(Group storage group, bytes32 id, bytes32 historyRef, bytes calldata granteeListRef) private {
+        require(historyRef != bytes32(0), "GroupRegistry: history ref cannot be zero");
+        group.version++;
+        group.historyRef = historyRef;
+        group.granteeListRef = granteeListRef;
+        emit GroupUpdated(id, group.version, historyRef, granteeListRef);
+    }
+
+    /**
+     * @dev Address of a 64-byte uncompressed public key
+     */
+    function _keyAddress(bytes calldata key) private pure returns (address) {
+        require(key.length == 64, "GroupRegistry: key must be a 64-byte uncompressed public key");
+        return address(uint160(uint256(keccak256(key))));
+    }
+}
@@ -55,7 +55,7 @@ Please either:
    export PRIVATE_KEY="0x..."
 
 2. Or update the constants in LoadDeploymentConfig() function with your deployment values.
-   You can find these values in the deployments/constants.go file after deployment.`)
+   You can find these values in deployments/sepolia-deployment.json after deployment.`)
 	}
 
 	fmt.Printf("🔗 RPC URL: %s\n", rpcURL)
@@ -0,0 +1,31 @@
+import { ethers, network } from "hardhat";
+import { updateGroupRegistry } from "./networkRegistry";
+
+async function main(): Promise<void> {
+  console.log(`=== GroupRegistry Deployment (${network.name}) ===\n`);
+
+  const [deployer] = await ethers.getSigners();
+  const chainId = Number((await ethers.provider.getNetwork()).chainId);
+  console.log(`👤 Deployer address: ${deployer.address}`);
+  console.log(`💰 Deployer balance: ${ethers.formatEther(await ethers.provider.getBalance(deployer.address))}`);
+
+  console.log("\n🚀 Deploying GroupRegistry...");
+  const GroupRegistry = await ethers.getContractFactory("GroupRegistry");
+  const registry = await GroupRegistry.deploy();
+  console.log(`📋 Transaction submitted: ${registry.deploymentTransaction()?.hash}`);
+  await registry.waitForDeployment();
+
+  const address = await registry.getAddress();
+  console.log("\n✅ Deployment successful!");
+  console.log(`📍 Contract address: ${address}`);
+
+  // Point the app at the new registry
+  updateGroupRegistry(network.name, chainId, address);
+}
+
+main()
+  .then(() => process.exit(0))
+  .catch((error) => {
+    console.error("❌ Deployment failed:", error);
+    process.exit(1);
+  });
@@ -1,6 +1,7 @@
 import { ethers } from "hardhat";
 import * as fs from "fs";
 import * as path from "path";
+import { syncNetworkRegistry } from "./networkRegistry";
 
 async function main() {
   console.log("🚀 Deploying DataContract to Chiado Testnet...");
@@ -36,11 +37,13 @@ async function main() {
   console.log("✅ DataContract deployed to:", contractAddress);
 
   // Get deployment transaction details
+  // the block number is only known from the receipt of the mined transaction
   const deploymentTx = contract.deploymentTransaction();
+  const deploymentReceipt = await deploymentTx?.wait();
   if (deploymentTx) {
     console.log("Transaction hash:", deploymentTx.hash);
-    console.log("Block number:", deploymentTx.blockNumber);
-    console.log("Gas used:", deploymentTx.gasLimit?.toString());
+    console.log("Block number:", deploymentReceipt?.blockNumber);
+    console.log("Gas used:", deploymentReceipt?.gasUsed?.toString());
   }
 
   // Verify the contract is deployed correctly
@@ -62,8 +65,8 @@ async function main() {
     contractAddress: contractAddress,
     deployerAddress: deployer.address,
     transactionHash: deploymentTx?.hash,
-    blockNumber: deploymentTx?.blockNumber,
-    gasUsed: deploymentTx?.gasLimit?.toString(),
+    blockNumber: deploymentReceipt?.blockNumber,
+    gasUsed: deploymentReceipt?.gasUsed?.toString(),
     timestamp: new Date().toISOString(),
     rpcUrl: process.env.CHIADO_RPC_URL || "https://rpc.chiadochain.net"
   };
@@ -77,6 +80,9 @@ async function main() {
     console.error("❌ Failed to save deployment info:", error);
   }
 
+  // Point the app at the new deployment
+  syncNetworkRegistry("chiado");
+
   // Generate environment variables file
   const envContent = `# Chiado Testnet Deployment Configuration
 # Generated on ${new Date().toISOString()}
@@ -96,33 +102,6 @@ CHIADO_BLOCK_EXPLORER=https://gnosis-chiado.blockscout.com
     console.error("❌ Failed to save environment file:", error);
   }
 
-  // Generate Go constants file
-  const goContent = `// Code generated by Chiado deployment script. DO NOT EDIT.
-// Generated on ${new Date().toISOString()}
-
-package main
-
-const (
-	// Chiado Testnet Configuration
-	ChiadoChainID      = 10200
-	ChiadoRPCURL       = "${process.env.CHIADO_RPC_URL || "https://rpc.chiadochain.net"}"
-	ChiadoExplorerURL  = "https://gnosis-chiado.blockscout.com"
-	
-	// Contract Deployment Info
-	ChiadoContractAddress = "${contractAddress}"
-	ChiadoDeployerAddress = "${deployer.address}"
-	ChiadoDeploymentBlock = ${deploymentTx?.blockNumber || 0}
-)
-`;
-
-  const goFile = path.join(deploymentsDir, "chiado-constants.go");
-  try {
-    fs.writeFileSync(goFile, goContent);
-    console.log("✅ Go constants saved to:", goFile);
-  } catch (error) {
-    console.error("❌ Failed to save Go constants:", error);
-  }
-
   console.log("\n🎉 Deployment completed successfully!");
   console.log("=======================================");
   console.log("Contract Address:", contractAddress);
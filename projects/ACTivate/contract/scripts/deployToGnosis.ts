@@ -1,6 +1,7 @@
 import { ethers } from "hardhat";
 import * as fs from "fs";
 import * as path from "path";
+import { syncNetworkRegistry } from "./networkRegistry";
 
 async function main() {
   console.log("🚀 Deploying DataContract to Gnosis Chain...");
@@ -37,11 +38,13 @@ async function main() {
   console.log("✅ DataContract deployed to:", contractAddress);
 
   // Get deployment transaction details
+  // the block number is only known from the receipt of the mined transaction
   const deploymentTx = contract.deploymentTransaction();
+  const deploymentReceipt = await deploymentTx?.wait();
   if (deploymentTx) {
     console.log("Transaction hash:", deploymentTx.hash);
-    console.log("Block number:", deploymentTx.blockNumber);
-    console.log("Gas used:", deploymentTx.gasLimit?.toString());
+    console.log("Block number:", deploymentReceipt?.blockNumber);
+    console.log("Gas used:", deploymentReceipt?.gasUsed?.toString());
   }
 
   // Verify the contract is deployed correctly
@@ -63,8 +66,8 @@ async function main() {
     contractAddress: contractAddress,
     deployerAddress: deployer.address,
     transactionHash: deploymentTx?.hash,
-    blockNumber: deploymentTx?.blockNumber,
-    gasUsed: deploymentTx?.gasLimit?.toString(),
+    blockNumber: deploymentReceipt?.blockNumber,
+    gasUsed: deploymentReceipt?.gasUsed?.toString(),
     timestamp: new Date().toISOString(),
     rpcUrl: process.env.GNOSIS_RPC_URL || "https://rpc.gnosischain.com"
   };
@@ -78,6 +81,9 @@ async function main() {
     console.error("❌ Failed to save deployment info:", error);
   }
 
+  // Point the app at the new deployment
+  syncNetworkRegistry("gnosis");
+
   // Generate environment variables file
   const envContent = `# Gnosis Chain Deployment Configuration
 # Generated on ${new Date().toISOString()}
@@ -97,33 +103,6 @@ GNOSIS_BLOCK_EXPLORER=https://gnosisscan.io
     console.error("❌ Failed to save environment file:", error);
   }
 
-  // Generate Go constants file
-  const goContent = `// Code generated by Gnosis deployment script. DO NOT EDIT.
-// Generated on ${new Date().toISOString()}
-
-package main
-
-const (
-	// Gnosis Chain Configuration
-	GnosisChainID      = 100
-	GnosisRPCURL       = "${process.env.GNOSIS_RPC_URL || "https://rpc.gnosischain.com"}"
-	GnosisExplorerURL  = "https://gnosisscan.io"
-	
-	// Contract Deployment Info
-	GnosisContractAddress = "${contractAddress}"
-	GnosisDeployerAddress = "${deployer.address}"
-	GnosisDeploymentBlock = ${deploymentTx?.blockNumber || 0}
-)
-`;
-
-  const goFile = path.join(deploymentsDir, "gnosis-constants.go");
-  try {
-    fs.writeFileSync(goFile, goContent);
-    console.log("✅ Go constants saved to:", goFile);
-  } catch (error) {
-    console.error("❌ Failed to save Go constants:", error);
-  }
-
   console.log("\n🎉 Deployment completed successfully!");
   console.log("=======================================");
   console.log("Contract Address:", contractAddress);
@@ -1,15 +1,17 @@
 import { ethers } from "hardhat";
 import * as fs from "fs";
 import * as path from "path";
+import { syncNetworkRegistry } from "./networkRegistry";
 
-// Configuration interface for Go integration
+// DeploymentConfig is the deployment record, the app's network registry is
+// derived from it
 interface DeploymentConfig {
   network: string;
   chainId: number;
+  contractName: string;
   contractAddress: string;
   contractABI: any[]; // eslint-disable-line @typescript-eslint/no-explicit-any
   deployerAddress: string;
-  privateKey: string;
   rpcUrl: string;
   blockNumber: number;
   transactionHash: string;
@@ -81,13 +83,14 @@ async function main(): Promise<void> {
   await dataContract.waitForDeployment();
 
   const contractAddress = await dataContract.getAddress();
-  const deploymentReceipt = dataContract.deploymentTransaction();
+  // the block number is only known from the receipt of the mined transaction
+  const deploymentReceipt = await dataContract.deploymentTransaction()?.wait();
   
   console.log("\n✅ Deployment successful!");
   console.log(`📍 Contract address: ${contractAddress}`);
   console.log(`🔗 Transaction hash: ${deploymentReceipt?.hash}`);
   console.log(`📦 Block number: ${deploymentReceipt?.blockNumber}`);
-  console.log(`⛽ Gas used: ${deploymentReceipt?.gasLimit?.toString()}`);
+  console.log(`⛽ Gas used: ${deploymentReceipt?.gasUsed?.toString()}`);
 
   // Verify contract functions
   console.log("\n🔍 Verifying contract deployment...");
@@ -109,18 +112,18 @@ async function main(): Promise<void> {
     process.exit(1);
   }
 
-  // Create deployment configuration for Go
+  // Create the deployment record, the private key stays in sepolia.env
   const deploymentConfig: DeploymentConfig = {
     network: network.name,
     chainId: Number(network.chainId),
+    contractName: "DataContract",
     contractAddress: contractAddress,
     contractABI: artifact.abi,
     deployerAddress: deployer.address,
-    privateKey: privateKey,
     rpcUrl: rpcUrl,
     blockNumber: deploymentReceipt?.blockNumber || 0,
     transactionHash: deploymentReceipt?.hash || "",
-    gasUsed: deploymentReceipt?.gasLimit?.toString() || "0",
+    gasUsed: deploymentReceipt?.gasUsed?.toString() || "0",
     deployedAt: new Date().toISOString(),
   };
 
@@ -136,28 +139,19 @@ async function main(): Promise<void> {
     fs.writeFileSync(jsonPath, JSON.stringify(deploymentConfig, null, 2));
     console.log(`💾 Deployment info saved to: ${jsonPath}`);
 
-    // 2. Go configuration file
-    const goConfigPath = path.join(deploymentsDir, `sepolia-config.go`);
-    const goConfig = generateGoConfig(deploymentConfig);
-    fs.writeFileSync(goConfigPath, goConfig);
-    console.log(`🐹 Go configuration saved to: ${goConfigPath}`);
-
-    // 3. Environment file for easy loading
+    // 2. Environment file for easy loading
     const envPath = path.join(deploymentsDir, `sepolia.env`);
-    const envContent = generateEnvFile(deploymentConfig);
+    const envContent = generateEnvFile(deploymentConfig, privateKey);
     fs.writeFileSync(envPath, envContent);
     console.log(`📄 Environment file saved to: ${envPath}`);
-
-    // 4. Go constants file
-    const goConstantsPath = path.join(deploymentsDir, `constants.go`);
-    const goConstants = generateGoConstants(deploymentConfig);
-    fs.writeFileSync(goConstantsPath, goConstants);
-    console.log(`🔧 Go constants file saved to: ${goConstantsPath}`);
   } catch (error) {
     console.error("❌ Failed to save deployment files:", error instanceof Error ? error.message : String(error));
     console.log("⚠️  Deployment was successful but files could not be saved.");
   }
 
+  // Point the app at the new deployment
+  syncNetworkRegistry("sepolia");
+
   console.log("\n🎉 Deployment completed successfully!");
   console.log("\n📋 Next steps:");
   console.log("1. Verify contract on Etherscan (optional):");
@@ -165,35 +159,12 @@ async function main(): Promise<void> {
   console.log("\n2. Test contract interaction:");
   console.log(`   CONTRACT_ADDRESS=${contractAddress} npm run interact`);
   console.log("\n3. Use in Go code:");
-  console.log(`   Copy deployments/constants.go to your Go project`);
+  console.log(`   The app reads screens/networks/sepolia.json, synced from the deployment record`);
   console.log(`   Or source deployments/sepolia.env in your environment`);
 
   console.log(`\n🔗 View on Etherscan: https://sepolia.etherscan.io/address/${contractAddress}`);
 }
 
-function generateGoConfig(config: DeploymentConfig): string {
-  return `package main
-
-import (
-	"encoding/json"
-	"log"
-)
-
-// DataContractConfig contains all deployment information for Sepolia testnet
-type DataContractConfig struct {
-	Network         string      \`json:"network"\`
-	ChainID         int64       \`json:"chainId"\`
-	ContractAddress string      \`json:"contractAddress"\`
-	ContractABI     interface{} \`json:"contractABI"\`
-	DeployerAddress string      \`json:"deployerAddress"\`
-	PrivateKey      string      \`json:"privateKey"\`
-	RPCUrl          string      \`json:"rpcUrl"\`
-	BlockNumber     int64       \`json:"blockNumber"\`
-	TransactionHash string      \`json:"transactionHash"\`
-	GasUsed         string      \`json:"gasUsed"\`
-	DeployedAt      string      \`json:"deployedAt"\`
-}
-
 // GetSepoliaConfig returns the deployment configuration for Sepolia testnet
 func GetSepoliaConfig() *DataContractConfig {
 	configJSON := \`${JSON.stringify(config, null, 2)}\`
@@ -211,7 +182,7 @@ const DataContractABI = \`${JSON.stringify(config.contractABI)}\`
 `;
 }
 
-function generateEnvFile(config: DeploymentConfig): string {
+function generateEnvFile(config: DeploymentConfig, privateKey: string): string {
   return `# DataContract Sepolia Deployment Configuration
 # Generated on ${config.deployedAt}
 
@@ -230,7 +201,7 @@ DEPLOYMENT_BLOCK=${config.blockNumber}
 GAS_USED=${config.gasUsed}
 
 # Private Key (Keep secure!)
-PRIVATE_KEY=${config.privateKey}
+PRIVATE_KEY=${privateKey}
 
 # Etherscan Links
 ETHERSCAN_CONTRACT_URL=https://sepolia.etherscan.io/address/${config.contractAddress}
@@ -238,41 +209,6 @@ ETHERSCAN_TX_URL=https://sepolia.etherscan.io/tx/${config.transactionHash}
 `;
 }
 
-function generateGoConstants(config: DeploymentConfig): string {
-  return `package main
-
-// DataContract Sepolia deployment constants
-// Generated on ${config.deployedAt}
-
-const (
-	// Network Configuration
-	SepoliaChainID = ${config.chainId}
-	SepoliaRPCURL  = "${config.rpcUrl}"
-	
-	// Contract Information
-	DataContractAddress = "${config.contractAddress}"
-	DeployerAddress     = "${config.deployerAddress}"
-	
-	// Transaction Information
-	DeploymentTxHash  = "${config.transactionHash}"
-	DeploymentBlock   = ${config.blockNumber}
-	GasUsed          = "${config.gasUsed}"
-)
-
-// Private key - Keep this secure and consider using environment variables
-const PrivateKey = "${config.privateKey}"
-
-// Contract ABI as JSON string
-const DataContractABI = \`${JSON.stringify(config.contractABI)}\`
-
-// Etherscan URLs
-const (
-	EtherscanContractURL = "https://sepolia.etherscan.io/address/${config.contractAddress}"
-	EtherscanTxURL      = "https://sepolia.etherscan.io/tx/${config.transactionHash}"
-)
-`;
-}
-
 // Execute the deployment
 main()
   .then(() => process.exit(0))
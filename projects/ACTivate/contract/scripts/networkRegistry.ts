@@ -0,0 +1,92 @@
+import * as fs from "fs";
+import * as path from "path";
+
+// The deployment records are the single source of where the contracts live.
+// The app's network registry, embedded into the app at build time, is derived
+// from them: run `npm run registry:sync` after editing a record by hand.
+const deploymentsDir = path.join(__dirname, "..", "deployments");
+const registryDir = path.join(__dirname, "..", "..", "screens", "networks");
+
+export interface RegistryDeployment {
+  chainId: number;
+  contractName?: string;
+  contractAddress: string;
+  transactionHash?: string;
+  blockNumber?: number | null;
+  groupRegistryAddress?: string;
+}
+
+function deploymentFile(network: string): string {
+  return path.join(deploymentsDir, `${network}-deployment.json`);
+}
+
+// syncNetworkRegistry copies the public deployment fields of a network's
+// deployment record into the app's entry for it. The RPC endpoint, explorer
+// and Swarm settings of the entry are kept. A block number of 0 makes the app
+// look the deployment block up by the transaction hash.
+export function syncNetworkRegistry(network: string): void {
+  const registryFile = path.join(registryDir, `${network}.json`);
+  if (!fs.existsSync(registryFile)) {
+    console.log(`⚠️  ${network} is not in the app's network registry, add ${registryFile} to use it in the app`);
+    return;
+  }
+
+  try {
+    const deployment: RegistryDeployment = JSON.parse(fs.readFileSync(deploymentFile(network), "utf8"));
+    const entry = JSON.parse(fs.readFileSync(registryFile, "utf8"));
+    if (entry.chainId !== deployment.chainId) {
+      throw new Error(`registry entry is chain ${entry.chainId}, deployment is chain ${deployment.chainId}`);
+    }
+    entry.contractName = deployment.contractName || entry.contractName;
+    entry.contractAddress = deployment.contractAddress;
+    if (deployment.transactionHash) {
+      entry.transactionHash = deployment.transactionHash;
+    } else {
+      delete entry.transactionHash;
+    }
+    entry.blockNumber = deployment.blockNumber || 0;
+    if (deployment.groupRegistryAddress) {
+      entry.groupRegistryAddress = deployment.groupRegistryAddress;
+    } else {
+      delete entry.groupRegistryAddress;
+    }
+    fs.writeFileSync(registryFile, JSON.stringify(entry, null, 2) + "\n");
+    console.log("📚 App network registry updated:", registryFile);
+  } catch (error) {
+    console.error("❌ Failed to update the app network registry:", error instanceof Error ? error.message : String(error));
+  }
+}
+
+// updateGroupRegistry records a new GroupRegistry deployment in the network's
+// deployment record and points the app's entry at it.
+export function updateGroupRegistry(network: string, chainId: number, address: string): void {
+  const file = deploymentFile(network);
+  if (!fs.existsSync(file)) {
+    console.log(`⚠️  ${network} has no deployment record, deploy the DataContract first`);
+    return;
+  }
+
+  try {
+    const deployment = JSON.parse(fs.readFileSync(file, "utf8"));
+    if (deployment.chainId !== chainId) {
+      throw new Error(`deployment record is chain ${deployment.chainId}, GroupRegistry is chain ${chainId}`);
+    }
+    deployment.groupRegistryAddress = address;
+    fs.writeFileSync(file, JSON.stringify(deployment, null, 2) + "\n");
+    console.log("💾 Deployment record updated:", file);
+  } catch (error) {
+    console.error("❌ Failed to update the deployment record:", error instanceof Error ? error.message : String(error));
+    return;
+  }
+  syncNetworkRegistry(network);
+}
+
+// Run directly, every app entry is synced from its deployment record
+if (require.main === module) {
+  for (const file of fs.readdirSync(registryDir)) {
+    const network = path.basename(file, ".json");
+    if (file.endsWith(".json") && fs.existsSync(deploymentFile(network))) {
+      syncNetworkRegistry(network);
+    }
+  }
+}
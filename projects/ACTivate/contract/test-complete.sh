@@ -111,32 +111,13 @@ print_success "TypeScript compilation check passed"
 echo ""
 
 # Step 6: Go Integration Files Check
-print_step "6" "Validating Go Integration Files"
+print_step "6" "Validating Deployment Files"
 
 if [ -d "deployments" ]; then
-    if [ -f "deployments/constants.go" ]; then
-        print_success "Go constants file exists"
-        
-        # Basic syntax check for Go file
-        if command -v go >/dev/null 2>&1; then
-            cd deployments
-            echo "package main" > temp_test.go
-            cat constants.go >> temp_test.go
-            echo "func main() {}" >> temp_test.go
-            
-            if go build temp_test.go 2>/dev/null; then
-                print_success "Go constants file has valid syntax"
-            else
-                print_warning "Go constants file has syntax issues"
-            fi
-            
-            rm -f temp_test.go temp_test
-            cd ..
-        else
-            print_warning "Go not installed, skipping syntax check"
-        fi
+    if [ -f "deployments/sepolia-deployment.json" ]; then
+        print_success "Deployment record exists"
     else
-        print_warning "Go constants file not found (run deployment first)"
+        print_warning "Deployment record not found (run deployment first)"
     fi
     
     if [ -f "deployments/sepolia.env" ]; then
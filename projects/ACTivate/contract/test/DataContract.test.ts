@@ -111,6 +111,194 @@ describe("DataContract", function () {
     });
   });
 
+  describe("sendDataToTargets", function () {
+    const ownerParam = ethers.encodeBytes32String("OWNER_001");
+    const actref = ethers.encodeBytes32String("ACTION_REF_123");
+
+    it("Should emit one event per target in a single transaction", async function () {
+      const targets = [targetAddress.address, user1.address, user2.address];
+      const topics = ["Topic for target", "Topic for user1", "Topic for user2"];
+
+      const tx = await dataContract.connect(user1).sendDataToTargets(targets, ownerParam, actref, topics);
+      const receipt = await tx.wait();
+      expect(receipt?.logs).to.have.length(3);
+
+      for (let i = 0; i < targets.length; i++) {
+        await expect(tx)
+          .to.emit(dataContract, "DataSentToTarget")
+          .withArgs(user1.address, targets[i], ownerParam, actref, topics[i]);
+      }
+    });
+
+    it("Should revert without targets", async function () {
+      await expect(
+        dataContract.sendDataToTargets([], ownerParam, actref, [])
+      ).to.be.revertedWith("DataContract: no targets");
+    });
+
+    it("Should revert if targets and topics differ in length", async function () {
+      await expect(
+        dataContract.sendDataToTargets([targetAddress.address, user1.address], ownerParam, actref, ["Only one"])
+      ).to.be.revertedWith("DataContract: targets and topics length mismatch");
+    });
+
+    it("Should revert if any target is zero address", async function () {
+      await expect(
+        dataContract.sendDataToTargets([targetAddress.address, ethers.ZeroAddress], ownerParam, actref, ["First", "Second"])
+      ).to.be.revertedWith("DataContract: target cannot be zero address");
+    });
+
+    it("Should revert with more than 100 targets", async function () {
+      const targets = Array(101).fill(targetAddress.address);
+      const topics = Array(101).fill("Topic");
+
+      await expect(
+        dataContract.sendDataToTargets(targets, ownerParam, actref, topics)
+      ).to.be.revertedWith("DataContract: too many targets");
+    });
+  });
+
+  describe("sendDataToTargetV2", function () {
+    const SEALED = 2;
+    const publisherKey = "0x02" + "11".repeat(32);
+    const historyRef = ethers.encodeBytes32String("HISTORY_REF");
+    const payload = "0x" + "ab".repeat(100);
+
+    it("Should emit the payload as bytes with its typed fields", async function () {
+      await expect(dataContract.connect(user1).sendDataToTargetV2(targetAddress.address, 1, publisherKey, historyRef, payload))
+        .to.emit(dataContract, "DataSentToTargetV2")
+        .withArgs(user1.address, targetAddress.address, 1, publisherKey, historyRef, payload);
+    });
+
+    it("Should allow sealed notifications without publisher key and history", async function () {
+      await expect(dataContract.sendDataToTargetV2(targetAddress.address, SEALED, "0x", ethers.ZeroHash, payload))
+        .to.emit(dataContract, "DataSentToTargetV2")
+        .withArgs(owner.address, targetAddress.address, SEALED, "0x", ethers.ZeroHash, payload);
+    });
+
+    it("Should revert with a zero version", async function () {
+      await expect(
+        dataContract.sendDataToTargetV2(targetAddress.address, 0, "0x", ethers.ZeroHash, payload)
+      ).to.be.revertedWith("DataContract: version cannot be zero");
+    });
+
+    it("Should revert with a publisher key that is not compressed", async function () {
+      await expect(
+        dataContract.sendDataToTargetV2(targetAddress.address, 1, "0x04" + "11".repeat(64), historyRef, payload)
+      ).to.be.revertedWith("DataContract: publisher key must be empty or 33 bytes");
+    });
+
+    it("Should revert if target is zero address", async function () {
+      await expect(
+        dataContract.sendDataToTargetV2(ethers.ZeroAddress, SEALED, "0x", ethers.ZeroHash, payload)
+      ).to.be.revertedWith("DataContract: target cannot be zero address");
+    });
+
+    it("Should emit one event per target in a single transaction", async function () {
+      const targets = [targetAddress.address, user1.address, user2.address];
+      const payloads = ["0x01", "0x0202", "0x030303"];
+
+      const tx = await dataContract.connect(user1).sendDataToTargetsV2(targets, SEALED, "0x", ethers.ZeroHash, payloads);
+      const receipt = await tx.wait();
+      expect(receipt?.logs).to.have.length(3);
+
+      for (let i = 0; i < targets.length; i++) {
+        await expect(tx)
+          .to.emit(dataContract, "DataSentToTargetV2")
+          .withArgs(user1.address, targets[i], SEALED, "0x", ethers.ZeroHash, payloads[i]);
+      }
+    });
+
+    it("Should revert if targets and payloads differ in length", async function () {
+      await expect(
+        dataContract.sendDataToTargetsV2([targetAddress.address, user1.address], SEALED, "0x", ethers.ZeroHash, ["0x01"])
+      ).to.be.revertedWith("DataContract: targets and payloads length mismatch");
+    });
+
+    it("Should take less calldata than the hex topic of the same payload", async function () {
+      const topic = payload.slice(2);
+      const v1 = dataContract.interface.encodeFunctionData("sendDataToTarget", [targetAddress.address, ethers.ZeroHash, ethers.ZeroHash, topic]);
+      const v2 = dataContract.interface.encodeFunctionData("sendDataToTargetV2", [targetAddress.address, SEALED, "0x", ethers.ZeroHash, payload]);
+      expect(ethers.dataLength(v2)).to.be.lessThan(ethers.dataLength(v1));
+    });
+  });
+
+  describe("sendDataToTargetWithSig", function () {
+    const ownerParam = ethers.encodeBytes32String("OWNER_001");
+    const actref = ethers.encodeBytes32String("ACTION_REF_123");
+    const topic = "Relayed Topic";
+    const types = {
+      SendDataToTarget: [
+        { name: "from", type: "address" },
+        { name: "target", type: "address" },
+        { name: "owner", type: "bytes32" },
+        { name: "actref", type: "bytes32" },
+        { name: "topic", type: "string" },
+        { name: "nonce", type: "uint256" },
+        { name: "deadline", type: "uint256" },
+      ],
+    };
+
+    async function signRequest(signer: SignerWithAddress, nonce: bigint, deadline: bigint) {
+      const { chainId } = await ethers.provider.getNetwork();
+      const domain = {
+        name: "DataContract",
+        version: "1",
+        chainId,
+        verifyingContract: await dataContract.getAddress(),
+      };
+      return signer.signTypedData(domain, types, {
+        from: signer.address,
+        target: targetAddress.address,
+        owner: ownerParam,
+        actref,
+        topic,
+        nonce,
+        deadline,
+      });
+    }
+
+    it("Should emit the event from the signer when a relayer submits it", async function () {
+      const deadline = BigInt(Math.floor(Date.now() / 1000) + 3600);
+      const signature = await signRequest(user1, 0n, deadline);
+
+      await expect(
+        dataContract.connect(user2).sendDataToTargetWithSig(user1.address, targetAddress.address, ownerParam, actref, topic, deadline, signature)
+      )
+        .to.emit(dataContract, "DataSentToTarget")
+        .withArgs(user1.address, targetAddress.address, ownerParam, actref, topic);
+      expect(await dataContract.nonces(user1.address)).to.equal(1n);
+    });
+
+    it("Should revert when a request is replayed", async function () {
+      const deadline = BigInt(Math.floor(Date.now() / 1000) + 3600);
+      const signature = await signRequest(user1, 0n, deadline);
+      await dataContract.connect(user2).sendDataToTargetWithSig(user1.address, targetAddress.address, ownerParam, actref, topic, deadline, signature);
+
+      await expect(
+        dataContract.connect(user2).sendDataToTargetWithSig(user1.address, targetAddress.address, ownerParam, actref, topic, deadline, signature)
+      ).to.be.revertedWith("DataContract: invalid signature");
+    });
+
+    it("Should revert when the signer is not the sender", async function () {
+      const deadline = BigInt(Math.floor(Date.now() / 1000) + 3600);
+      const signature = await signRequest(user2, 0n, deadline);
+
+      await expect(
+        dataContract.sendDataToTargetWithSig(user1.address, targetAddress.address, ownerParam, actref, topic, deadline, signature)
+      ).to.be.revertedWith("DataContract: invalid signature");
+    });
+
+    it("Should revert when the request expired", async function () {
+      const deadline = BigInt(Math.floor(Date.now() / 1000) - 3600);
+      const signature = await signRequest(user1, 0n, deadline);
+
+      await expect(
+        dataContract.sendDataToTargetWithSig(user1.address, targetAddress.address, ownerParam, actref, topic, deadline, signature)
+      ).to.be.revertedWith("DataContract: request expired");
+    });
+  });
+
   describe("Multi-User Access", function () {
     it("Should allow multiple users to call the function simultaneously", async function () {
       const ownerParam = ethers.encodeBytes32String("MULTI_USER");
@@ -0,0 +1,124 @@
+import { expect } from "chai";
+import { ethers } from "hardhat";
+import { GroupRegistry } from "../typechain-types";
+import { SignerWithAddress } from "@nomicfoundation/hardhat-ethers/signers";
+import { HDNodeWallet } from "ethers";
+
+describe("GroupRegistry", function () {
+  let registry: GroupRegistry;
+  let funder: SignerWithAddress;
+  let admin: HDNodeWallet;
+  let member: HDNodeWallet;
+
+  const salt = ethers.ZeroHash;
+  const history1 = ethers.keccak256(ethers.toUtf8Bytes("history 1"));
+  const history2 = ethers.keccak256(ethers.toUtf8Bytes("history 2"));
+  const granteeList = ethers.hexlify(ethers.randomBytes(64));
+
+  // the 64-byte uncompressed public key the registry stores
+  const adminKey = (wallet: HDNodeWallet) => "0x" + wallet.signingKey.publicKey.slice(4);
+
+  // wallets with known keys, funded by a hardhat account
+  async function fundedWallet(): Promise<HDNodeWallet> {
+    const wallet = ethers.Wallet.createRandom().connect(ethers.provider);
+    await funder.sendTransaction({ to: wallet.address, value: ethers.parseEther("1") });
+    return wallet;
+  }
+
+  beforeEach(async function () {
+    [funder] = await ethers.getSigners();
+    admin = await fundedWallet();
+    member = await fundedWallet();
+
+    const GroupRegistry = await ethers.getContractFactory("GroupRegistry");
+    registry = await GroupRegistry.deploy();
+    await registry.waitForDeployment();
+  });
+
+  async function createGroup(): Promise<string> {
+    await registry.connect(admin).createGroup(salt, adminKey(admin), history1, granteeList);
+    return registry.groupId(admin.address, salt);
+  }
+
+  describe("createGroup", function () {
+    it("Should create a group with the caller as admin", async function () {
+      const id = ethers.keccak256(ethers.AbiCoder.defaultAbiCoder().encode(["address", "bytes32"], [admin.address, salt]));
+      expect(await registry.groupId(admin.address, salt)).to.equal(id);
+
+      await expect(registry.connect(admin).createGroup(salt, adminKey(admin), history1, granteeList))
+        .to.emit(registry, "GroupCreated")
+        .withArgs(id, admin.address, salt)
+        .and.to.emit(registry, "GroupUpdated")
+        .withArgs(id, 1, history1, granteeList);
+
+      const group = await registry.getGroup(id);
+      expect(group.admin).to.equal(admin.address);
+      expect(group.adminKey).to.equal(adminKey(admin));
+      expect(group.version).to.equal(1);
+      expect(group.historyRef).to.equal(history1);
+      expect(group.granteeListRef).to.equal(granteeList);
+    });
+
+    it("Should reject another node's key", async function () {
+      await expect(
+        registry.connect(member).createGroup(salt, adminKey(admin), history1, granteeList)
+      ).to.be.revertedWith("GroupRegistry: admin key is not the caller's");
+    });
+
+    it("Should reject a taken group and a zero history", async function () {
+      await createGroup();
+      await expect(
+        registry.connect(admin).createGroup(salt, adminKey(admin), history2, granteeList)
+      ).to.be.revertedWith("GroupRegistry: group exists");
+      await expect(
+        registry.connect(admin).createGroup(ethers.id("other"), adminKey(admin), ethers.ZeroHash, granteeList)
+      ).to.be.revertedWith("GroupRegistry: history ref cannot be zero");
+    });
+  });
+
+  describe("updateGroup", function () {
+    it("Should let the admin publish a new state", async function () {
+      const id = await createGroup();
+      await expect(registry.connect(admin).updateGroup(id, history2, "0x"))
+        .to.emit(registry, "GroupUpdated")
+        .withArgs(id, 2, history2, "0x");
+      const group = await registry.getGroup(id);
+      expect(group.version).to.equal(2);
+      expect(group.historyRef).to.equal(history2);
+    });
+
+    it("Should reject everyone else", async function () {
+      const id = await createGroup();
+      await expect(registry.connect(member).updateGroup(id, history2, "0x")).to.be.revertedWith(
+        "GroupRegistry: caller is not the admin"
+      );
+      await expect(registry.connect(admin).updateGroup(ethers.id("unknown"), history2, "0x")).to.be.revertedWith(
+        "GroupRegistry: unknown group"
+      );
+    });
+  });
+
+  describe("transferAdmin", function () {
+    it("Should hand the group to the new admin", async function () {
+      const id = await createGroup();
+      await expect(registry.connect(admin).transferAdmin(id, adminKey(member)))
+        .to.emit(registry, "AdminTransferred")
+        .withArgs(id, admin.address, member.address);
+
+      await expect(registry.connect(admin).updateGroup(id, history2, "0x")).to.be.revertedWith(
+        "GroupRegistry: caller is not the admin"
+      );
+      await registry.connect(member).updateGroup(id, history2, "0x");
+      const group = await registry.getGroup(id);
+      expect(group.admin).to.equal(member.address);
+      expect(group.adminKey).to.equal(adminKey(member));
+    });
+
+    it("Should reject keys that are not 64 bytes", async function () {
+      const id = await createGroup();
+      await expect(registry.connect(admin).transferAdmin(id, member.signingKey.compressedPublicKey)).to.be.revertedWith(
+        "GroupRegistry: key must be a 64-byte uncompressed public key"
+      );
+    });
+  });
+});
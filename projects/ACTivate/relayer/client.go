@@ -0,0 +1,82 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Client talks to a relayer's HTTP API
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// NewClient returns a client of the relayer at url
+func NewClient(url string) *Client {
+	return &Client{
+		url:  strings.TrimSuffix(url, "/"),
+		http: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Nonce is the nonce the sender signs its next request with
+func (c *Client) Nonce(ctx context.Context, from common.Address) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/nonce/"+from.Hex(), nil)
+	if err != nil {
+		return 0, err
+	}
+	var resp nonceResponse
+	if err := c.do(req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Nonce, nil
+}
+
+// Relay submits a signed request, returning the hash of the relayer's transaction
+func (c *Client) Relay(ctx context.Context, request *Request) (common.Hash, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/relay", bytes.NewReader(body))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp relayResponse
+	if err := c.do(req, &resp); err != nil {
+		return common.Hash{}, err
+	}
+	return resp.TxHash, nil
+}
+
+func (c *Client) do(req *http.Request, v any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("relayer unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRequestSize))
+	if err != nil {
+		return fmt.Errorf("failed to read relayer response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("relayer: %s", errResp.Error)
+		}
+		return fmt.Errorf("relayer: %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid relayer response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Command relayer submits DataContract requests signed by senders without
+// gas, paying for them from its own account.
+//
+//	RELAYER_PRIVATE_KEY=0x... relayer -rpc https://rpc.gnosischain.com -contract 0x...
+//
+// It pays for any validly signed request, so it caps the requests per sender
+// and per client address and what it spends in a day. -allow restricts it to
+// known senders.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"activate/relayer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func main() {
+	rpcURL := flag.String("rpc", "https://rpc.gnosischain.com", "RPC endpoint of the chain")
+	contract := flag.String("contract", "", "DataContract address")
+	listen := flag.String("listen", ":8090", "address to serve the relayer API on")
+	maxTopic := flag.Int("max-topic", relayer.DefaultMaxTopicLength, "longest topic relayed, in bytes")
+	maxGas := flag.Uint64("max-gas", relayer.DefaultMaxGas, "most gas paid for one request")
+	maxRequests := flag.Int("max-requests", relayer.DefaultMaxRequestsPerDay, "most requests relayed per sender and per client address in a day")
+	maxSpend := flag.String("max-daily-spend", relayer.DefaultMaxDailySpend.String(), "most the relayer pays in a day, in wei")
+	allow := flag.String("allow", "", "comma separated senders to relay for, anyone if empty")
+	flag.Parse()
+
+	if !common.IsHexAddress(*contract) {
+		log.Fatal("-contract must be the DataContract address")
+	}
+	spendCap, ok := new(big.Int).SetString(*maxSpend, 10)
+	if !ok || spendCap.Sign() <= 0 {
+		log.Fatal("-max-daily-spend must be a positive amount of wei")
+	}
+	var allowlist []common.Address
+	for _, address := range strings.Split(*allow, ",") {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		if !common.IsHexAddress(address) {
+			log.Fatalf("-allow: %q is not an address", address)
+		}
+		allowlist = append(allowlist, common.HexToAddress(address))
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(os.Getenv("RELAYER_PRIVATE_KEY"), "0x"))
+	if err != nil {
+		log.Fatalf("RELAYER_PRIVATE_KEY must be the hex private key of the paying account: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", *rpcURL, err)
+	}
+	defer client.Close()
+
+	r, err := relayer.New(ctx, client, key, common.HexToAddress(*contract), relayer.Options{
+		MaxTopicLength:    *maxTopic,
+		MaxGas:            *maxGas,
+		Allowlist:         allowlist,
+		MaxRequestsPerDay: *maxRequests,
+		MaxDailySpend:     spendCap,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down: %v", err)
+		}
+	}()
+
+	log.Printf("Relaying for %s from %s on %s", *contract, r.Address().Hex(), *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,83 @@
+package relayer
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// DefaultMaxRequestsPerDay caps the requests relayed for one sender and
+	// those taken from one client address in a day
+	DefaultMaxRequestsPerDay = 50
+	// limitWindow is how long the request counts and the spending last
+	limitWindow = 24 * time.Hour
+)
+
+// DefaultMaxDailySpend caps what the relayer pays in a day, in wei
+var DefaultMaxDailySpend = big.NewInt(1e18)
+
+var (
+	ErrNotAllowed  = errors.New("sender is not allowed to use this relayer")
+	ErrRateLimited = errors.New("too many requests today")
+	ErrSpendCap    = errors.New("the relayer reached its daily spending cap")
+)
+
+// dailyLimits counts what the relayer did since the window started. Anyone
+// can sign requests with fresh keys, the client address and the spending cap
+// bound what they cost the relayer.
+type dailyLimits struct {
+	start   time.Time
+	spent   *big.Int
+	senders map[common.Address]int
+	clients map[string]int
+}
+
+// roll starts a new window once the current one is over
+func (l *dailyLimits) roll(now time.Time) {
+	if l.spent != nil && now.Sub(l.start) < limitWindow {
+		return
+	}
+	l.start = now
+	l.spent = new(big.Int)
+	l.senders = map[common.Address]int{}
+	l.clients = map[string]int{}
+}
+
+// takeClient counts a request from a client address, failing once it made
+// max requests in the window
+func (l *dailyLimits) takeClient(client string, max int, now time.Time) error {
+	l.roll(now)
+	if l.clients[client] >= max {
+		return ErrRateLimited
+	}
+	l.clients[client]++
+	return nil
+}
+
+// checkSender fails once max requests were relayed for the sender in the window
+func (l *dailyLimits) checkSender(from common.Address, max int, now time.Time) error {
+	l.roll(now)
+	if l.senders[from] >= max {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// checkSpend fails if paying cost would take the window over max
+func (l *dailyLimits) checkSpend(cost, max *big.Int, now time.Time) error {
+	l.roll(now)
+	if new(big.Int).Add(l.spent, cost).Cmp(max) > 0 {
+		return ErrSpendCap
+	}
+	return nil
+}
+
+// record counts a relayed request and what it can cost at most
+func (l *dailyLimits) record(from common.Address, cost *big.Int, now time.Time) {
+	l.roll(now)
+	l.senders[from]++
+	l.spent.Add(l.spent, cost)
+}
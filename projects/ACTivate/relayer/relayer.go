@@ -0,0 +1,320 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// DefaultMaxTopicLength caps the topic a relayed request can make the
+	// relayer pay for, sealed notifications are well below it
+	DefaultMaxTopicLength = 4096
+	// DefaultMaxGas caps the gas of a relayed call
+	DefaultMaxGas = 300_000
+	// pendingTimeout is how long a sender's next request waits for the
+	// previous one, a dropped transaction does not block the sender for good
+	pendingTimeout = 10 * time.Minute
+)
+
+// contractABI is the part of the DataContract the relayer calls
+const contractABI = `[
+	{"type":"function","name":"nonces","stateMutability":"view",
+	 "inputs":[{"name":"","type":"address"}],
+	 "outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"sendDataToTargetWithSig","stateMutability":"nonpayable",
+	 "inputs":[{"name":"from","type":"address"},{"name":"target","type":"address"},{"name":"ownerParam","type":"bytes32"},
+	 {"name":"actref","type":"bytes32"},{"name":"topic","type":"string"},{"name":"deadline","type":"uint256"},
+	 {"name":"signature","type":"bytes"}],
+	 "outputs":[]}
+]`
+
+var (
+	ErrExpired       = errors.New("request expired")
+	ErrNonce         = errors.New("request nonce is not the sender's current nonce")
+	ErrPending       = errors.New("the sender's previous request is not mined yet")
+	ErrInvalidTarget = errors.New("target cannot be the zero address")
+	ErrTopicTooLong  = errors.New("topic too long")
+	ErrReverted      = errors.New("request reverts")
+	ErrGasLimit      = errors.New("request needs too much gas")
+	ErrNoBaseFee     = errors.New("the chain has no base fee")
+)
+
+// rejected tells the errors of bad requests from those of the relayer
+func rejected(err error) bool {
+	for _, target := range []error{ErrInvalidSignature, ErrExpired, ErrNonce, ErrPending, ErrInvalidTarget, ErrTopicTooLong, ErrReverted, ErrGasLimit, ErrNotAllowed} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backend is the chain access the relayer needs
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// limited tells the errors of requests over a limit, they can be retried later
+func limited(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrSpendCap)
+}
+
+// Options are the limits on what the relayer pays for, zero values are the defaults
+type Options struct {
+	MaxTopicLength int
+	MaxGas         uint64
+	// Allowlist are the only senders relayed for, anyone if empty
+	Allowlist []common.Address
+	// MaxRequestsPerDay caps the requests of one sender, and of one client
+	// address, in a day
+	MaxRequestsPerDay int
+	// MaxDailySpend caps the most the relayer's transactions can cost in a
+	// day, in wei
+	MaxDailySpend *big.Int
+}
+
+// Relayer submits signed requests from its own account
+type Relayer struct {
+	backend  Backend
+	key      *ecdsa.PrivateKey
+	address  common.Address
+	contract common.Address
+	chainID  *big.Int
+	abi      abi.ABI
+	options  Options
+	allowed  map[common.Address]bool
+
+	// mu serializes sending, so the relayer's nonces are used in order
+	mu sync.Mutex
+	// sent is the last request relayed for each sender, the sender's next
+	// request waits until the contract has caught up
+	sent   map[common.Address]sentRequest
+	limits dailyLimits
+}
+
+type sentRequest struct {
+	nextNonce uint64
+	at        time.Time
+}
+
+// New returns a relayer paying from key for requests to the contract
+func New(ctx context.Context, backend Backend, key *ecdsa.PrivateKey, contract common.Address, options Options) (*Relayer, error) {
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		return nil, err
+	}
+	if options.MaxTopicLength == 0 {
+		options.MaxTopicLength = DefaultMaxTopicLength
+	}
+	if options.MaxGas == 0 {
+		options.MaxGas = DefaultMaxGas
+	}
+	if options.MaxRequestsPerDay == 0 {
+		options.MaxRequestsPerDay = DefaultMaxRequestsPerDay
+	}
+	if options.MaxDailySpend == nil || options.MaxDailySpend.Sign() == 0 {
+		options.MaxDailySpend = DefaultMaxDailySpend
+	}
+	var allowed map[common.Address]bool
+	if len(options.Allowlist) > 0 {
+		allowed = map[common.Address]bool{}
+		for _, address := range options.Allowlist {
+			allowed[address] = true
+		}
+	}
+	return &Relayer{
+		backend:  backend,
+		key:      key,
+		address:  crypto.PubkeyToAddress(key.PublicKey),
+		contract: contract,
+		chainID:  chainID,
+		abi:      parsed,
+		options:  options,
+		allowed:  allowed,
+		sent:     map[common.Address]sentRequest{},
+	}, nil
+}
+
+// Address is the account the relayer pays from
+func (r *Relayer) Address() common.Address {
+	return r.address
+}
+
+// Nonce is the nonce the sender's next request must be signed with
+func (r *Relayer) Nonce(ctx context.Context, from common.Address) (uint64, error) {
+	data, err := r.abi.Pack("nonces", from)
+	if err != nil {
+		return 0, err
+	}
+	out, err := r.backend.CallContract(ctx, ethereum.CallMsg{To: &r.contract, Data: data}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	values, err := r.abi.Unpack("nonces", out)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read nonce: %w", err)
+	}
+	nonce, ok := values[0].(*big.Int)
+	if !ok || !nonce.IsUint64() {
+		return 0, fmt.Errorf("failed to read nonce: unexpected value %v", values[0])
+	}
+	return nonce.Uint64(), nil
+}
+
+// TakeClient counts a request from a client address, failing with
+// ErrRateLimited once the address sent too many today
+func (r *Relayer) TakeClient(client string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limits.takeClient(client, r.options.MaxRequestsPerDay, time.Now())
+}
+
+// Relay checks the request and submits it, returning the hash of the
+// transaction. It does not wait for the transaction to be mined.
+func (r *Relayer) Relay(ctx context.Context, req *Request) (common.Hash, error) {
+	if len(req.Topic) > r.options.MaxTopicLength {
+		return common.Hash{}, fmt.Errorf("%w: %d bytes, at most %d", ErrTopicTooLong, len(req.Topic), r.options.MaxTopicLength)
+	}
+	if req.Target == (common.Address{}) {
+		return common.Hash{}, ErrInvalidTarget
+	}
+	if req.Deadline <= uint64(time.Now().Unix()) {
+		return common.Hash{}, ErrExpired
+	}
+	if err := req.Verify(r.chainID, r.contract); err != nil {
+		return common.Hash{}, err
+	}
+	if r.allowed != nil && !r.allowed[req.From] {
+		return common.Hash{}, ErrNotAllowed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.limits.checkSender(req.From, r.options.MaxRequestsPerDay, time.Now()); err != nil {
+		return common.Hash{}, err
+	}
+
+	nonce, err := r.Nonce(ctx, req.From)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if sent, ok := r.sent[req.From]; ok && sent.nextNonce > nonce && time.Since(sent.at) < pendingTimeout {
+		return common.Hash{}, ErrPending
+	}
+	if req.Nonce != nonce {
+		return common.Hash{}, fmt.Errorf("%w: signed with %d, current is %d", ErrNonce, req.Nonce, nonce)
+	}
+
+	data, err := r.abi.Pack("sendDataToTargetWithSig", req.From, req.Target, [32]byte(req.Owner), [32]byte(req.ActRef),
+		req.Topic, new(big.Int).SetUint64(req.Deadline), []byte(req.Signature))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// the estimate runs the call, a request that reverts is not paid for
+	gas, err := r.backend.EstimateGas(ctx, ethereum.CallMsg{From: r.address, To: &r.contract, Data: data})
+	if err != nil {
+		if reason, ok := revertReason(err); ok {
+			return common.Hash{}, fmt.Errorf("%w: %s", ErrReverted, reason)
+		}
+		return common.Hash{}, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	if gas > r.options.MaxGas {
+		return common.Hash{}, fmt.Errorf("%w: %d, at most %d", ErrGasLimit, gas, r.options.MaxGas)
+	}
+
+	tx, err := r.signTransaction(ctx, data, gas)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// the most the transaction can cost counts against the cap, not what it
+	// ends up paying
+	cost := new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+	if err := r.limits.checkSpend(cost, r.options.MaxDailySpend, time.Now()); err != nil {
+		return common.Hash{}, err
+	}
+	if err := r.backend.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	r.sent[req.From] = sentRequest{nextNonce: req.Nonce + 1, at: time.Now()}
+	r.limits.record(req.From, cost, time.Now())
+	return tx.Hash(), nil
+}
+
+// signTransaction signs the call with the relayer's next nonce, paying up to
+// twice the current base fee
+func (r *Relayer) signTransaction(ctx context.Context, data []byte, gas uint64) (*types.Transaction, error) {
+	head, err := r.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest header: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, ErrNoBaseFee
+	}
+	tip, err := r.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	nonce, err := r.backend.PendingNonceAt(ctx, r.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relayer nonce: %w", err)
+	}
+
+	// gas is the estimate, a little headroom covers state changing before
+	// the transaction is mined
+	return types.SignNewTx(r.key, types.LatestSignerForChainID(r.chainID), &types.DynamicFeeTx{
+		ChainID:   r.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas + gas/5,
+		To:        &r.contract,
+		Data:      data,
+	})
+}
+
+// revertReason decodes the Error(string) an execution reverted with
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return "", false
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return "", false
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return "", false
+	}
+	reason, unpackErr := abi.UnpackRevert(data)
+	if unpackErr != nil {
+		return "", false
+	}
+	return reason, true
+}
@@ -0,0 +1,163 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeBackend is a chain where every call succeeds and the contract's nonce
+// of a sender counts the transactions relayed for them
+type fakeBackend struct {
+	baseFee *big.Int
+	gas     uint64
+	nonces  map[common.Address]uint64
+	sent    []*types.Transaction
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{baseFee: big.NewInt(1e9), gas: 50_000, nonces: map[common.Address]uint64{}}
+}
+
+func (b *fakeBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return testChainID, nil
+}
+
+func (b *fakeBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	from := common.BytesToAddress(call.Data[4:36])
+	return common.LeftPadBytes(new(big.Int).SetUint64(b.nonces[from]).Bytes(), 32), nil
+}
+
+func (b *fakeBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return b.gas, nil
+}
+
+func (b *fakeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: b.baseFee}, nil
+}
+
+func (b *fakeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (b *fakeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return uint64(len(b.sent)), nil
+}
+
+func (b *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+// mine has the contract take the sender's request, so the next one can follow
+func (b *fakeBackend) mine(from common.Address) {
+	b.nonces[from]++
+}
+
+func newTestRelayer(t *testing.T, backend Backend, options Options) *Relayer {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(context.Background(), backend, key, testContract, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// signedRequest is a request of key with the nonce the backend expects
+func signedRequest(t *testing.T, backend *fakeBackend, key *ecdsa.PrivateKey) *Request {
+	t.Helper()
+	req := testRequest()
+	req.Nonce = backend.nonces[crypto.PubkeyToAddress(key.PublicKey)]
+	req.Deadline = uint64(time.Now().Add(time.Hour).Unix())
+	if err := req.Sign(key, testChainID, testContract); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestRelayLimitsSender(t *testing.T) {
+	backend := newFakeBackend()
+	r := newTestRelayer(t, backend, Options{MaxRequestsPerDay: 2})
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	for range 2 {
+		if _, err := r.Relay(context.Background(), signedRequest(t, backend, key)); err != nil {
+			t.Fatal(err)
+		}
+		backend.mine(from)
+	}
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, key)); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("relayed a third request: %v", err)
+	}
+	// other senders are not held back
+	other, _ := crypto.GenerateKey()
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, other)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelayAllowlist(t *testing.T) {
+	backend := newFakeBackend()
+	allowedKey, _ := crypto.GenerateKey()
+	r := newTestRelayer(t, backend, Options{Allowlist: []common.Address{crypto.PubkeyToAddress(allowedKey.PublicKey)}})
+	stranger, _ := crypto.GenerateKey()
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, stranger)); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("relayed for a sender not on the allowlist: %v", err)
+	}
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, allowedKey)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelaySpendCap(t *testing.T) {
+	backend := newFakeBackend()
+	// a request costs at most 60000 gas at 3 gwei
+	r := newTestRelayer(t, backend, Options{MaxDailySpend: big.NewInt(60_000 * 3e9 * 3 / 2)})
+	first, _ := crypto.GenerateKey()
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, first)); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := crypto.GenerateKey()
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, second)); !errors.Is(err, ErrSpendCap) {
+		t.Fatalf("relayed over the spending cap: %v", err)
+	}
+	if len(backend.sent) != 1 {
+		t.Fatalf("sent %d transactions", len(backend.sent))
+	}
+}
+
+func TestRelayWithoutBaseFee(t *testing.T) {
+	backend := newFakeBackend()
+	backend.baseFee = nil
+	r := newTestRelayer(t, backend, Options{})
+	key, _ := crypto.GenerateKey()
+	if _, err := r.Relay(context.Background(), signedRequest(t, backend, key)); !errors.Is(err, ErrNoBaseFee) {
+		t.Fatalf("relayed on a chain without base fee: %v", err)
+	}
+}
+
+func TestDailyLimitsWindow(t *testing.T) {
+	var limits dailyLimits
+	now := time.Now()
+	if err := limits.takeClient("192.0.2.1", 1, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := limits.takeClient("192.0.2.1", 1, now.Add(time.Hour)); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("took a second request: %v", err)
+	}
+	if err := limits.takeClient("192.0.2.1", 1, now.Add(limitWindow)); err != nil {
+		t.Fatalf("the limit did not reset the next day: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Package relayer lets senders without gas notify through the DataContract.
+// The sender signs an EIP-712 request with their node key, the relayer submits
+// it with sendDataToTargetWithSig and pays for it, and the contract emits the
+// event from the sender who signed it.
+package relayer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// the EIP-712 domain and request type of the DataContract
+const (
+	domainName    = "DataContract"
+	domainVersion = "1"
+	requestType   = "SendDataToTarget"
+)
+
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// Request is a sendDataToTargetWithSig call signed by its sender
+type Request struct {
+	From   common.Address `json:"from"`
+	Target common.Address `json:"target"`
+	Owner  common.Hash    `json:"owner"`
+	ActRef common.Hash    `json:"actref"`
+	Topic  string         `json:"topic"`
+	// Nonce is the sender's current nonce in the contract
+	Nonce uint64 `json:"nonce"`
+	// Deadline is the unix time after which the contract rejects the request
+	Deadline  uint64        `json:"deadline"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// typedData is the request as EIP-712 typed data for the contract on chainID
+func (r *Request) typedData(chainID *big.Int, contract common.Address) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			requestType: {
+				{Name: "from", Type: "address"},
+				{Name: "target", Type: "address"},
+				{Name: "owner", Type: "bytes32"},
+				{Name: "actref", Type: "bytes32"},
+				{Name: "topic", Type: "string"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: requestType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              domainName,
+			Version:           domainVersion,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: contract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":     r.From.Hex(),
+			"target":   r.Target.Hex(),
+			"owner":    r.Owner,
+			"actref":   r.ActRef,
+			"topic":    r.Topic,
+			"nonce":    new(big.Int).SetUint64(r.Nonce),
+			"deadline": new(big.Int).SetUint64(r.Deadline),
+		},
+	}
+}
+
+// Hash is the EIP-712 digest the sender signs
+func (r *Request) Hash(chainID *big.Int, contract common.Address) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(r.typedData(chainID, contract))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash request: %w", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// Sign sets From to the key's address and signs the request
+func (r *Request) Sign(key *ecdsa.PrivateKey, chainID *big.Int, contract common.Address) error {
+	r.From = crypto.PubkeyToAddress(key.PublicKey)
+	hash, err := r.Hash(chainID, contract)
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	// the contract takes the 27/28 recovery id of ecrecover
+	signature[crypto.RecoveryIDOffset] += 27
+	r.Signature = signature
+	return nil
+}
+
+// Signer recovers who signed the request
+func (r *Request) Signer(chainID *big.Int, contract common.Address) (common.Address, error) {
+	if len(r.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: %d bytes", ErrInvalidSignature, len(r.Signature))
+	}
+	v := r.Signature[crypto.RecoveryIDOffset]
+	if v != 27 && v != 28 {
+		return common.Address{}, fmt.Errorf("%w: recovery id %d", ErrInvalidSignature, v)
+	}
+	r1, s1 := new(big.Int).SetBytes(r.Signature[:32]), new(big.Int).SetBytes(r.Signature[32:64])
+	// the contract rejects malleable signatures with a high s
+	if !crypto.ValidateSignatureValues(v-27, r1, s1, true) {
+		return common.Address{}, fmt.Errorf("%w: malformed", ErrInvalidSignature)
+	}
+
+	hash, err := r.Hash(chainID, contract)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature, r.Signature)
+	signature[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(hash[:], signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Verify checks the request is signed by its sender
+func (r *Request) Verify(chainID *big.Int, contract common.Address) error {
+	signer, err := r.Signer(chainID, contract)
+	if err != nil {
+		return err
+	}
+	if signer != r.From {
+		return fmt.Errorf("%w: signed by %s, not %s", ErrInvalidSignature, signer.Hex(), r.From.Hex())
+	}
+	return nil
+}
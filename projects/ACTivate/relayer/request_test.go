@@ -0,0 +1,110 @@
+package relayer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	testChainID  = big.NewInt(100)
+	testContract = common.HexToAddress("0x242A2174fa8d8586a784aBdB4fF03C3181E96bee")
+)
+
+func testRequest() *Request {
+	return &Request{
+		Target:   common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Owner:    common.Hash{1},
+		ActRef:   common.Hash{2},
+		Topic:    "sealed topic",
+		Nonce:    3,
+		Deadline: 1_700_000_000,
+	}
+}
+
+// contractHash computes the digest the way the contract does
+func contractHash(t *testing.T, r *Request) common.Hash {
+	t.Helper()
+	bytes32, _ := abi.NewType("bytes32", "", nil)
+	address, _ := abi.NewType("address", "", nil)
+	uint256, _ := abi.NewType("uint256", "", nil)
+	encode := func(types []abi.Type, values ...any) []byte {
+		var args abi.Arguments
+		for _, typ := range types {
+			args = append(args, abi.Argument{Type: typ})
+		}
+		packed, err := args.Pack(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return packed
+	}
+
+	domain := crypto.Keccak256(encode([]abi.Type{bytes32, bytes32, bytes32, uint256, address},
+		crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256Hash([]byte("DataContract")), crypto.Keccak256Hash([]byte("1")), testChainID, testContract))
+	structHash := crypto.Keccak256(encode([]abi.Type{bytes32, address, address, bytes32, bytes32, bytes32, uint256, uint256},
+		crypto.Keccak256Hash([]byte("SendDataToTarget(address from,address target,bytes32 owner,bytes32 actref,string topic,uint256 nonce,uint256 deadline)")),
+		r.From, r.Target, [32]byte(r.Owner), [32]byte(r.ActRef), crypto.Keccak256Hash([]byte(r.Topic)),
+		new(big.Int).SetUint64(r.Nonce), new(big.Int).SetUint64(r.Deadline)))
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domain, structHash)
+}
+
+func TestRequestHashMatchesContract(t *testing.T) {
+	r := testRequest()
+	r.From = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	hash, err := r.Hash(testChainID, testContract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := contractHash(t, r); hash != want {
+		t.Fatalf("hash %s, contract computes %s", hash.Hex(), want.Hex())
+	}
+}
+
+func TestRequestSignVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := testRequest()
+	if err := r.Sign(key, testChainID, testContract); err != nil {
+		t.Fatal(err)
+	}
+	if r.From != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("signed from %s", r.From.Hex())
+	}
+	if v := r.Signature[64]; v != 27 && v != 28 {
+		t.Fatalf("recovery id %d", v)
+	}
+	if err := r.Verify(testChainID, testContract); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *r
+	tampered.Topic = "another topic"
+	if err := tampered.Verify(testChainID, testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("verified a tampered request: %v", err)
+	}
+	// a signature for one chain or contract is not valid on another
+	if err := r.Verify(big.NewInt(1), testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("verified on another chain: %v", err)
+	}
+	if err := r.Verify(testChainID, common.Address{1}); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("verified for another contract: %v", err)
+	}
+
+	// the malleable twin of the signature, s' = n - s and the other recovery id
+	malleable := *r
+	malleable.Signature = append([]byte(nil), r.Signature...)
+	s := new(big.Int).SetBytes(r.Signature[32:64])
+	new(big.Int).Sub(crypto.S256().Params().N, s).FillBytes(malleable.Signature[32:64])
+	malleable.Signature[64] ^= 1
+	if err := malleable.Verify(testChainID, testContract); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("verified a malleable signature: %v", err)
+	}
+}
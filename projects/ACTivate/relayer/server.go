@@ -0,0 +1,94 @@
+package relayer
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxRequestSize caps a request body, a request with the longest topic is
+// well below it
+const maxRequestSize = 64 * 1024
+
+type relayResponse struct {
+	TxHash common.Hash `json:"txHash"`
+}
+
+type nonceResponse struct {
+	Nonce uint64 `json:"nonce"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the relayer's HTTP API:
+//
+//	GET  /nonce/{address}  the nonce the address signs its next request with
+//	POST /relay            relays a signed Request, returns the transaction hash
+func (r *Relayer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /nonce/{address}", r.handleNonce)
+	mux.HandleFunc("POST /relay", r.handleRelay)
+	return mux
+}
+
+func (r *Relayer) handleNonce(w http.ResponseWriter, req *http.Request) {
+	address := req.PathValue("address")
+	if !common.IsHexAddress(address) {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid address"})
+		return
+	}
+	nonce, err := r.Nonce(req.Context(), common.HexToAddress(address))
+	if err != nil {
+		log.Printf("Failed to read nonce of %s: %v", address, err)
+		writeJSON(w, http.StatusBadGateway, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, nonceResponse{Nonce: nonce})
+}
+
+func (r *Relayer) handleRelay(w http.ResponseWriter, req *http.Request) {
+	// behind a proxy, all requests come from the proxy's address
+	client, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		client = req.RemoteAddr
+	}
+	if err := r.TakeClient(client); err != nil {
+		log.Printf("Rejected request from client %s: %v", client, err)
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: err.Error()})
+		return
+	}
+
+	var request Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestSize)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request: " + err.Error()})
+		return
+	}
+	txHash, err := r.Relay(req.Context(), &request)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case rejected(err):
+			status = http.StatusBadRequest
+		case limited(err):
+			status = http.StatusTooManyRequests
+		}
+		log.Printf("Rejected request from %s to %s: %v", request.From.Hex(), request.Target.Hex(), err)
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	log.Printf("Relayed request from %s to %s in %s", request.From.Hex(), request.Target.Hex(), txHash.Hex())
+	writeJSON(w, http.StatusOK, relayResponse{TxHash: txHash})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
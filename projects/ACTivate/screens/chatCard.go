@@ -0,0 +1,282 @@
+package screens
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+const ratchetSessionsPrefKey = "ratchetSessions"
+
+// chatMessage is a decrypted session message. Messages are only kept in
+// memory, so forward secrecy is not undone by a copy at rest.
+type chatMessage struct {
+	Peer      string
+	Outgoing  bool
+	Text      string
+	Timestamp time.Time
+}
+
+func (i *index) showChatCard() *widget.Card {
+	encryptionUtils := &EncryptionUtils{}
+
+	peerEntry := widget.NewSelectEntry(nil)
+	peerEntry.SetPlaceHolder("Peer public key (hex) or pick a grantee")
+	go func() {
+		eglRef := i.storedEglRef()
+		if eglRef.IsZero() {
+			return
+		}
+		grantees, err := i.bl.GetGranteeList(context.Background(), eglRef, false)
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Error fetching grantee list for chat: %v", err))
+			return
+		}
+		peerEntry.SetOptions(grantees)
+	}()
+
+	i.chatList = widget.NewList(
+		func() int {
+			return len(i.chatMessages)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("template message")
+			label.Wrapping = fyne.TextWrapWord
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id >= len(i.chatMessages) {
+				return
+			}
+			m := i.chatMessages[id]
+			direction := "from"
+			if m.Outgoing {
+				direction = "to"
+			}
+			item.(*widget.Label).SetText(fmt.Sprintf("%s %s %s: %s", m.Timestamp.Format("15:04"), direction, shortenHashOrAddress(m.Peer), m.Text))
+		},
+	)
+	chatScroll := container.NewScroll(i.chatList)
+	chatScroll.SetMinSize(fyne.NewSize(350, 150))
+
+	messageEntry := widget.NewEntry()
+	messageEntry.SetPlaceHolder("Message")
+
+	sendButton := widget.NewButton("Send Securely", func() {
+		peer, err := encryptionUtils.ParsePublicKeyFromHex(peerEntry.Text)
+		if err != nil {
+			i.showError(fmt.Errorf("invalid peer public key: %w", err))
+			return
+		}
+		text := messageEntry.Text
+		if text == "" {
+			i.showError(fmt.Errorf("message cannot be empty"))
+			return
+		}
+
+		i.showProgressWithMessage("Sending message...")
+		go func() {
+			defer i.hideProgress()
+			if err := i.sendSessionMessage(context.Background(), peer, text); err != nil {
+				i.showError(err)
+				return
+			}
+			messageEntry.SetText("")
+			i.addChatMessage(chatMessage{
+				Peer:      hex.EncodeToString(crypto.CompressPubkey(peer)),
+				Outgoing:  true,
+				Text:      text,
+				Timestamp: time.Now(),
+			})
+		}()
+	})
+
+	// the first ratchet step is against the peer's node key, see ratchet.go
+	limitation := widget.NewLabel("Messages sent before the peer first replies are not forward-secret: anyone who later gets the peer's node key can read them.")
+	limitation.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(
+		limitation,
+		chatScroll,
+		widget.NewLabel("Peer:"),
+		peerEntry,
+		messageEntry,
+		sendButton,
+	)
+	return widget.NewCard("Secure Messages", "forward-secret messages between two nodes", content)
+}
+
+func (i *index) addChatMessage(m chatMessage) {
+	i.chatMessages = append(i.chatMessages, m)
+	if i.chatList != nil {
+		i.chatList.Refresh()
+	}
+}
+
+// sendSessionMessage encrypts text with the next key of the session with peer,
+// uploads it and notifies the peer through the data contract. The sending
+// chain advances and is stored before anything is sent, so a message key is
+// never used twice even when a send that seemed to fail gets mined. The peer
+// skips the keys of messages that never arrive.
+func (i *index) sendSessionMessage(ctx context.Context, peer *ecdsa.PublicKey, text string) error {
+	if i.contractSvc == nil {
+		return fmt.Errorf("contract service not initialized")
+	}
+	nodeKey, err := i.nodePrivateKey()
+	if err != nil {
+		return err
+	}
+
+	stamp := i.getStamp()
+	if stamp == nil {
+		return fmt.Errorf("no usable postage stamp found")
+	}
+	batchHex := hex.EncodeToString(stamp.ID())
+
+	// uploaded under our own group's ACT when we have one, so only grantees
+	// can even fetch the ciphertext
+	history := swarm.ZeroAddress
+	if historyHex := i.getPreferenceString(historyRefPrefKey); historyHex != "" {
+		history, err = swarm.ParseHexAddress(historyHex)
+		if err != nil {
+			return fmt.Errorf("invalid history reference: %w", err)
+		}
+	}
+
+	message, err := i.withSession(peer, func(e *EncryptionUtils, s *ratchetState) (*ratchetState, []byte, error) {
+		if s == nil {
+			s, err = e.newInitiatorSession(nodeKey, peer)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		message, err := e.ratchetEncrypt(s, i.encryptionUtils().padding.pad([]byte(text)), ratchetAD(&nodeKey.PublicKey, peer))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt message: %w", err)
+		}
+		return s, message, nil
+	})
+	if err != nil {
+		return err
+	}
+	return i.deliverSessionMessage(ctx, nodeKey, peer, batchHex, history, message)
+}
+
+// deliverSessionMessage uploads an encrypted session message and notifies peer
+func (i *index) deliverSessionMessage(ctx context.Context, nodeKey *ecdsa.PrivateKey, peer *ecdsa.PublicKey, batchHex string, history swarm.Address, message []byte) error {
+	ref, newHistory, err := i.bl.AddBytes(ctx, batchHex, !history.IsZero(), history, false, 0, bytes.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to upload message: %w", err)
+	}
+	i.logger.Log(fmt.Sprintf("Uploaded session message: %s", ref.String()))
+
+	payload := sharePayload{
+		Kind:      payloadKindSession,
+		Reference: ref.Bytes(),
+	}
+	if !history.IsZero() {
+		payload.History = newHistory.Bytes()
+	}
+	receipt, err := i.sendSealed(ctx, nodeKey, payload, peer)
+	if err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	i.logger.Log(fmt.Sprintf("Session message notification sent: %s", receipt.TxHash.Hex()))
+	return nil
+}
+
+// receiveSessionMessage downloads and decrypts a session message announced
+// by a verified payload
+func (i *index) receiveSessionMessage(ctx context.Context, payload *sharePayload) error {
+	nodeKey, err := i.nodePrivateKey()
+	if err != nil {
+		return err
+	}
+
+	var history *swarm.Address
+	var publisher *ecdsa.PublicKey
+	if len(payload.History) > 0 {
+		h := swarm.NewAddress(payload.History)
+		history = &h
+		publisher = payload.Publisher
+	}
+	reader, err := i.bl.GetBytes(ctx, swarm.NewAddress(payload.Reference), publisher, history, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download session message: %w", err)
+	}
+	message, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	var plaintext []byte
+	_, err = i.withSession(payload.Publisher, func(e *EncryptionUtils, s *ratchetState) (*ratchetState, []byte, error) {
+		var next *ratchetState
+		plaintext, next, err = e.receiveRatchetMessage(s, nodeKey, payload.Publisher, message)
+		return next, nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt session message: %w", err)
+	}
+	text, err := unpad(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt session message: %w", err)
+	}
+
+	i.addChatMessage(chatMessage{
+		Peer:      hex.EncodeToString(crypto.CompressPubkey(payload.Publisher)),
+		Text:      string(text),
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// withSession runs fn with the stored session for peer, nil if there is none,
+// and stores the session fn returns unless fn fails. Sessions are locked while
+// fn runs, so no two messages use the same key. fn must not do network I/O.
+func (i *index) withSession(peer *ecdsa.PublicKey, fn func(*EncryptionUtils, *ratchetState) (*ratchetState, []byte, error)) ([]byte, error) {
+	i.sessionMu.Lock()
+	defer i.sessionMu.Unlock()
+
+	sessions, err := i.loadSessions()
+	if err != nil {
+		return nil, err
+	}
+	peerHex := hex.EncodeToString(crypto.CompressPubkey(peer))
+
+	s, out, err := fn(&EncryptionUtils{}, sessions[peerHex])
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		sessions[peerHex] = s
+	}
+	if err := i.storeSessions(sessions); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// loadSessions returns the ratchet sessions by compressed peer key. They are
+// kept in preferences encrypted to the node's own key.
+func (i *index) loadSessions() (map[string]*ratchetState, error) {
+	sessions := map[string]*ratchetState{}
+	if err := i.loadSealedPreference(ratchetSessionsPrefKey, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (i *index) storeSessions(sessions map[string]*ratchetState) error {
+	return i.storeSealedPreference(ratchetSessionsPrefKey, sessions)
+}
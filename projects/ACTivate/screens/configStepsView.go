@@ -7,8 +7,10 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethersphere/bee/v2/pkg/api"
 )
@@ -20,6 +22,7 @@ type nodeConfig struct {
 	swapEnable     bool
 	natAddress     string
 	rpcEndpoint    string
+	network        string
 	isKeyStoreMem  bool
 }
 
@@ -39,13 +42,62 @@ func (i *index) showPasswordView() fyne.CanvasObject {
 		content.Refresh()
 	})
 	nextButton.Importance = widget.HighImportance
-	content.Objects = []fyne.CanvasObject{container.NewBorder(passwordEntry, nextButton, nil, nil)}
+	restoreButton := widget.NewButton("Restore from recovery phrase", func() {
+		if passwordEntry.Text == "" {
+			i.showError(fmt.Errorf("choose a password for the restored identity first"))
+			return
+		}
+		i.nodeConfig.password = passwordEntry.Text
+		content.Objects = []fyne.CanvasObject{i.showRestoreView()}
+		content.Refresh()
+	})
+	if i.nodeConfig.isKeyStoreMem {
+		restoreButton.Disable()
+	}
+	content.Objects = []fyne.CanvasObject{container.NewBorder(passwordEntry, container.NewVBox(nextButton, restoreButton), nil, nil)}
 	i.content = content
 	i.view = container.NewBorder(container.NewVBox(i.intro), nil, nil, nil, content)
 	i.view.Refresh()
 	return content
 }
 
+func (i *index) showRestoreView() fyne.CanvasObject {
+	i.intro.SetText("Enter the 24 words of your recovery phrase")
+	content := container.NewStack()
+	phraseEntry := widget.NewMultiLineEntry()
+	phraseEntry.SetPlaceHolder("word1 word2 word3 ...")
+	phraseEntry.Wrapping = fyne.TextWrapWord
+
+	nextButton := widget.NewButton("Restore", func() {
+		key, err := i.restoreIdentity(phraseEntry.Text, i.nodeConfig.password)
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Restore failed: %v", err))
+			i.showError(err)
+			return
+		}
+		phraseEntry.SetText("")
+
+		addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+		d := dialog.NewCustom("Identity restored", "Ok", i.copyDialog(fmt.Sprintf("Restored node %s", shortenHashOrAddress(addr)), addr), i.Window)
+		d.Show()
+		content.Objects = []fyne.CanvasObject{i.showWelcomeMessageView()}
+		content.Refresh()
+	})
+
+	backButton := widget.NewButton("Back", func() {
+		content.Objects = []fyne.CanvasObject{i.showPasswordView()}
+		content.Refresh()
+	})
+	backButton.Importance = widget.WarningImportance
+	nextButton.Importance = widget.HighImportance
+	content.Objects = []fyne.CanvasObject{container.NewBorder(phraseEntry, container.NewVBox(nextButton, backButton), nil, nil)}
+	i.content = content
+	i.view = container.NewBorder(container.NewVBox(i.intro), nil, nil, nil, content)
+	i.view.Refresh()
+
+	return content
+}
+
 func setPlaceHolderText(config, defaultText string) string {
 	placeHolder := config
 	if placeHolder == "" {
@@ -186,26 +238,22 @@ func (i *index) verifyRPCConnection(rpcEndpoint string) error {
 	if err != nil {
 		return fmt.Errorf("rpc endpoint is invalid or not reachable: %w", err)
 	}
-	// check connections
-	_, err = eth.ChainID(context.Background())
-	if err != nil {
-		return err
-	}
-
-	return nil
+	defer eth.Close()
+	// check the endpoint is on the selected network's chain
+	return i.network().checkChain(context.Background(), eth)
 }
 
 func (i *index) showRPCView() fyne.CanvasObject {
 	i.intro.SetText("Swarm mobile needs a RPC endpoint to start")
 	content := container.NewStack()
 	rpcEntry := widget.NewEntry()
-	rpcEntry.SetPlaceHolder(setPlaceHolderText(i.nodeConfig.rpcEndpoint, defaultRPC))
+	rpcEntry.SetPlaceHolder(setPlaceHolderText(i.nodeConfig.rpcEndpoint, i.network().RPCURL))
 
 	nextButton := widget.NewButton("Next", func() {
 		if i.nodeConfig.swapEnable {
 			if rpcEntry.Text == "" {
-				rpcEntry.SetText(defaultRPC)
-				i.logger.Log(fmt.Sprintf("RPC endpoint is blank, using default RPC: %s", defaultRPC))
+				rpcEntry.SetText(i.network().RPCURL)
+				i.logger.Log(fmt.Sprintf("RPC endpoint is blank, using default RPC: %s", i.network().RPCURL))
 			}
 			i.nodeConfig.rpcEndpoint = rpcEntry.Text
 		}
@@ -280,7 +328,7 @@ func (i *index) showStartView(firstStart bool) fyne.CanvasObject {
 	bottomBox := container.NewVBox()
 	if overlayAddr != "" {
 		infoLabel := widget.NewLabel(fmt.Sprintf("Warning: cannot continue in light-mode until there is\nat least min %s (for Gas) and at least min %s available on\n address: %s",
-			NativeTokenSymbol, SwarmTokenSymbol, shortenHashOrAddress(overlayAddr)))
+			i.network().NativeToken, i.network().SwarmToken, shortenHashOrAddress(overlayAddr)))
 		bottomBox.Add(infoLabel)
 		bottomBox.Add(i.copyButton(overlayAddr))
 	}
@@ -348,14 +396,85 @@ func (i *index) showAdvancedSettings() fyne.CanvasObject {
 			i.logger.Log(fmt.Sprintf("failed to bind rpc endpoint: %s", err.Error()))
 		}
 	}
-	rpcEntry.SetPlaceHolder(setPlaceHolderText(i.nodeConfig.rpcEndpoint, defaultRPC))
+	rpcEntry.SetPlaceHolder(setPlaceHolderText(i.nodeConfig.rpcEndpoint, i.network().RPCURL))
 	rpcEndpointItem := &widget.AccordionItem{
 		Title:  "RPC Endpoint",
 		Detail: rpcEntry,
 		Open:   false,
 	}
 
+	relayerEntry := widget.NewEntry()
+	relayerEntry.SetPlaceHolder(setPlaceHolderText(i.network().RelayerURL, "https://relayer.example.org"))
+	relayerEntry.SetText(i.getPreferenceString(relayerURLPrefKey))
+	relayerEntry.OnChanged = func(s string) {
+		i.setPreference(relayerURLPrefKey, s)
+	}
+	relayerItem := &widget.AccordionItem{
+		Title:  "Gasless relayer",
+		Detail: relayerEntry,
+		Open:   false,
+	}
+
+	networkSelect := widget.NewSelect(networkNames(), nil)
+	networkSelect.SetSelected(i.network().Name)
+	networkSelect.OnChanged = func(name string) {
+		previous := i.network()
+		i.nodeConfig.network = name
+		i.setPreference(networkPrefKey, name)
+		// an endpoint the user entered is kept, the default follows the network
+		if rpcEntry.Text == "" || rpcEntry.Text == previous.RPCURL {
+			rpcEntry.SetText(i.network().RPCURL)
+		}
+		rpcEntry.SetPlaceHolder(i.network().RPCURL)
+		relayerEntry.SetPlaceHolder(setPlaceHolderText(i.network().RelayerURL, "https://relayer.example.org"))
+	}
+	networkItem := &widget.AccordionItem{
+		Title:  "Network",
+		Detail: networkSelect,
+		Open:   false,
+	}
+
+	pollIntervalEntry := widget.NewEntry()
+	pollIntervalEntry.SetPlaceHolder(fmt.Sprintf("%d", int(defaultEventPollInterval.Seconds())))
+	pollIntervalEntry.SetText(i.getPreferenceString(eventPollIntervalPrefKey))
+	pollIntervalEntry.OnChanged = func(s string) {
+		i.setPreference(eventPollIntervalPrefKey, s)
+	}
+	pollIntervalItem := &widget.AccordionItem{
+		Title:  "Event poll interval (seconds, HTTP RPC only)",
+		Detail: pollIntervalEntry,
+		Open:   false,
+	}
+
+	confirmationsEntry := widget.NewEntry()
+	confirmationsEntry.SetPlaceHolder(fmt.Sprintf("%d", defaultEventConfirmations))
+	confirmationsEntry.SetText(i.getPreferenceString(eventConfirmationsPrefKey))
+	confirmationsEntry.OnChanged = func(s string) {
+		i.setPreference(eventConfirmationsPrefKey, s)
+	}
+	confirmationsItem := &widget.AccordionItem{
+		Title:  "Event confirmations (blocks)",
+		Detail: confirmationsEntry,
+		Open:   false,
+	}
+
+	watchListEntry := widget.NewMultiLineEntry()
+	watchListEntry.SetPlaceHolder("0x... (one address per line)")
+	watchListEntry.SetText(i.getPreferenceString(eventWatchListPrefKey))
+	watchListEntry.Validator = func(s string) error {
+		_, err := parseWatchList(s)
+		return err
+	}
+	watchListEntry.OnChanged = func(s string) {
+		i.setPreference(eventWatchListPrefKey, s)
+	}
+	watchListItem := &widget.AccordionItem{
+		Title:  "Also receive events sent to",
+		Detail: watchListEntry,
+		Open:   false,
+	}
+
 	return container.NewBorder(container.NewVBox(
-		widget.NewAccordion(modeSwitchItem, welcomeMsgItem, rpcEndpointItem, natAddrItem)),
+		widget.NewAccordion(modeSwitchItem, welcomeMsgItem, networkItem, rpcEndpointItem, relayerItem, natAddrItem, pollIntervalItem, confirmationsItem, watchListItem)),
 		nil, nil, nil)
 }
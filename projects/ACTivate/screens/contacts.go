@@ -0,0 +1,175 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// contact is a named public key. A key that changes after it was first saved
+// is flagged until the user verifies the new one.
+type contact struct {
+	Name         string    `json:"name"`
+	PublicKey    string    `json:"publicKey"`
+	Verified     bool      `json:"verified"`
+	KeyChanged   bool      `json:"keyChanged,omitempty"`
+	PreviousKeys []string  `json:"previousKeys,omitempty"`
+	Updated      time.Time `json:"updated"`
+}
+
+// key returns the contact's public key
+func (c *contact) key() (*ecdsa.PublicKey, error) {
+	encryptionUtils := &EncryptionUtils{}
+	return encryptionUtils.ParsePublicKeyFromHex(c.PublicKey)
+}
+
+// status describes how far the contact's key can be trusted
+func (c *contact) status() string {
+	switch {
+	case c.KeyChanged:
+		return "key changed"
+	case c.Verified:
+		return "verified"
+	default:
+		return "unverified"
+	}
+}
+
+// contactBook is the list of contacts, stored as JSON in preferences
+type contactBook []*contact
+
+// byName returns the contact called name, or nil
+func (b contactBook) byName(name string) *contact {
+	for _, c := range b {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// byKey returns the contact holding pub, or nil
+func (b contactBook) byKey(pub *ecdsa.PublicKey) *contact {
+	compressed := crypto.CompressPubkey(pub)
+	for _, c := range b {
+		key, err := c.key()
+		if err == nil && bytes.Equal(crypto.CompressPubkey(key), compressed) {
+			return c
+		}
+	}
+	return nil
+}
+
+// save adds or updates the contact called name. It reports whether an
+// existing contact's key was replaced, which clears its verification.
+func (b *contactBook) save(name string, pub *ecdsa.PublicKey) (*contact, bool) {
+	keyHex := hex.EncodeToString(crypto.CompressPubkey(pub))
+
+	c := b.byName(name)
+	if c == nil {
+		c = &contact{Name: name, PublicKey: keyHex, Updated: time.Now()}
+		*b = append(*b, c)
+		return c, false
+	}
+	if c.PublicKey == keyHex {
+		return c, false
+	}
+	c.replaceKey(keyHex)
+	return c, true
+}
+
+// forwardedBy returns the contact whose address sent a payload signed by
+// another key, nil if sender is no contact or signed it itself. An address
+// derives from its key, so this is never the contact's key changing: the
+// contact relayed or forwarded someone else's payload. Its key stays, only
+// save replaces keys.
+func (b contactBook) forwardedBy(sender common.Address, publisher *ecdsa.PublicKey) *contact {
+	if sender == crypto.PubkeyToAddress(*publisher) {
+		return nil
+	}
+	for _, c := range b {
+		key, err := c.key()
+		if err == nil && crypto.PubkeyToAddress(*key) == sender {
+			return c
+		}
+	}
+	return nil
+}
+
+// replaceKey flags the contact's new key until the user verifies it
+func (c *contact) replaceKey(keyHex string) {
+	c.PreviousKeys = append(c.PreviousKeys, c.PublicKey)
+	c.PublicKey = keyHex
+	c.Verified = false
+	c.KeyChanged = true
+	c.Updated = time.Now()
+}
+
+// markVerified records that the user compared the contact's safety number
+func (c *contact) markVerified() {
+	c.Verified = true
+	c.KeyChanged = false
+	c.Updated = time.Now()
+}
+
+func (i *index) loadContacts() (contactBook, error) {
+	contacts := contactBook{}
+	stored := i.getPreferenceString(contactsPrefKey)
+	if stored == "" {
+		return contacts, nil
+	}
+	if err := json.Unmarshal([]byte(stored), &contacts); err != nil {
+		return nil, fmt.Errorf("failed to read contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+func (i *index) storeContacts(contacts contactBook) error {
+	data, err := json.Marshal(contacts)
+	if err != nil {
+		return err
+	}
+	i.setPreference(contactsPrefKey, string(data))
+	return nil
+}
+
+// describeKey labels a hex public key with its contact name, verification
+// status and fingerprint
+func (i *index) describeKey(keyHex string) string {
+	encryptionUtils := &EncryptionUtils{}
+	pub, err := encryptionUtils.ParsePublicKeyFromHex(keyHex)
+	if err != nil {
+		return keyHex
+	}
+
+	contacts, err := i.loadContacts()
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Error loading contacts: %v", err))
+	}
+	if c := contacts.byKey(pub); c != nil {
+		return fmt.Sprintf("%s (%s)\n%s", c.Name, c.status(), displayFingerprint(pub))
+	}
+	return fmt.Sprintf("%s\n%s", shortenHashOrAddress(keyHex), displayFingerprint(pub))
+}
+
+// checkContactKey warns when a contact's address sends a payload signed by
+// another key. The contact is left as it is.
+func (i *index) checkContactKey(sender common.Address, publisher *ecdsa.PublicKey) {
+	contacts, err := i.loadContacts()
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Error loading contacts: %v", err))
+		return
+	}
+	c := contacts.forwardedBy(sender, publisher)
+	if c == nil {
+		return
+	}
+	i.logger.Log(fmt.Sprintf("Contact %s sent a payload signed by another key %s", c.Name, displayFingerprint(publisher)))
+	i.showForwardedPayloadWarning(c, publisher)
+}
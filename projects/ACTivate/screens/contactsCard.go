@@ -0,0 +1,203 @@
+package screens
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func (i *index) showContactsCard() *widget.Card {
+	encryptionUtils := &EncryptionUtils{}
+
+	ownLabel := widget.NewLabel(fmt.Sprintf("Your fingerprint: %s", displayFingerprint(i.bl.PublicKey())))
+
+	var contacts contactBook
+	var contactList *widget.List
+	reload := func() {
+		loaded, err := i.loadContacts()
+		if err != nil {
+			i.showError(err)
+			return
+		}
+		contacts = loaded
+		contactList.Refresh()
+	}
+
+	contactList = widget.NewList(
+		func() int {
+			return len(contacts)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template contact\nfingerprint")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id >= len(contacts) {
+				return
+			}
+			c := contacts[id]
+			fingerprint := "invalid key"
+			if pub, err := c.key(); err == nil {
+				fingerprint = displayFingerprint(pub)
+			}
+			text := fmt.Sprintf("%s (%s)\n%s", c.Name, c.status(), fingerprint)
+			if c.KeyChanged {
+				text = "⚠ " + text
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	contactList.OnSelected = func(id widget.ListItemID) {
+		if id < len(contacts) {
+			i.showContactDetails(contacts[id], reload)
+		}
+		contactList.UnselectAll()
+	}
+	reload()
+
+	contactScroll := container.NewScroll(contactList)
+	contactScroll.SetMinSize(fyne.NewSize(350, 150))
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Name")
+	keyEntry := widget.NewEntry()
+	keyEntry.SetPlaceHolder("Public key (hex)")
+	fingerprintLabel := widget.NewLabel("")
+	keyEntry.OnChanged = func(s string) {
+		fingerprintLabel.SetText(i.fingerprintHint(s))
+	}
+
+	saveButton := widget.NewButton("Save Contact", func() {
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			i.showError(fmt.Errorf("contact name cannot be empty"))
+			return
+		}
+		pub, err := encryptionUtils.ParsePublicKeyFromHex(keyEntry.Text)
+		if err != nil {
+			i.showError(fmt.Errorf("invalid public key: %w", err))
+			return
+		}
+
+		book, err := i.loadContacts()
+		if err != nil {
+			i.showError(err)
+			return
+		}
+		c, changed := book.save(name, pub)
+		if err := i.storeContacts(book); err != nil {
+			i.showError(err)
+			return
+		}
+		i.logger.Log(fmt.Sprintf("Saved contact %s with fingerprint %s", name, displayFingerprint(pub)))
+
+		nameEntry.SetText("")
+		keyEntry.SetText("")
+		reload()
+		if changed {
+			i.showKeyChangeWarning(c)
+		}
+	})
+
+	content := container.NewVBox(
+		ownLabel,
+		contactScroll,
+		widget.NewLabel("Name:"),
+		nameEntry,
+		widget.NewLabel("Public key:"),
+		keyEntry,
+		fingerprintLabel,
+		saveButton,
+	)
+	return widget.NewCard("Contacts", "compare fingerprints before trusting a key", content)
+}
+
+// showContactDetails shows the contact's fingerprint and safety number and
+// lets the user mark the key as verified
+func (i *index) showContactDetails(c *contact, onVerified func()) {
+	pub, err := c.key()
+	if err != nil {
+		i.showError(fmt.Errorf("invalid key for %s: %w", c.Name, err))
+		return
+	}
+
+	safety := widget.NewLabel(safetyNumber(i.bl.PublicKey(), pub))
+	safety.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Status: %s", c.status())),
+		widget.NewLabel(fmt.Sprintf("Fingerprint: %s", displayFingerprint(pub))),
+		widget.NewLabel("Safety number (compare with the contact in person or by call):"),
+		safety,
+		i.copyDialog(shortenHashOrAddress(c.PublicKey), c.PublicKey),
+	)
+	if c.KeyChanged {
+		content.Add(widget.NewLabel("⚠ This contact's key changed. Verify the new safety number before sharing."))
+	}
+
+	d := dialog.NewCustomConfirm(c.Name, "Mark as Verified", "Close", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		book, err := i.loadContacts()
+		if err != nil {
+			i.showError(err)
+			return
+		}
+		stored := book.byName(c.Name)
+		if stored == nil || stored.PublicKey != c.PublicKey {
+			i.showError(fmt.Errorf("the key of %s changed while verifying, please compare again", c.Name))
+			return
+		}
+		stored.markVerified()
+		if err := i.storeContacts(book); err != nil {
+			i.showError(err)
+			return
+		}
+		i.logger.Log(fmt.Sprintf("Marked contact %s as verified", c.Name))
+		onVerified()
+	}, i.Window)
+	d.Show()
+}
+
+func (i *index) showKeyChangeWarning(c *contact) {
+	previous := c.PreviousKeys[len(c.PreviousKeys)-1]
+	oldFingerprint := "unknown"
+	encryptionUtils := &EncryptionUtils{}
+	if pub, err := encryptionUtils.ParsePublicKeyFromHex(previous); err == nil {
+		oldFingerprint = displayFingerprint(pub)
+	}
+	newFingerprint := "unknown"
+	if pub, err := c.key(); err == nil {
+		newFingerprint = displayFingerprint(pub)
+	}
+	dialog.ShowInformation("Key changed",
+		fmt.Sprintf("The key of %s changed.\nOld: %s\nNew: %s\nCompare the new safety number before sharing with this contact.", c.Name, oldFingerprint, newFingerprint),
+		i.Window)
+}
+
+// showForwardedPayloadWarning tells the user a contact sent a payload signed
+// by a key that is not the contact's
+func (i *index) showForwardedPayloadWarning(c *contact, publisher *ecdsa.PublicKey) {
+	dialog.ShowInformation("Payload signed by another key",
+		fmt.Sprintf("%s sent a payload signed by another key.\nSigner: %s\nIt comes from whoever holds that key, not from %s. The contact's key is unchanged.",
+			c.Name, i.describeKey(hex.EncodeToString(crypto.FromECDSAPub(publisher))), c.Name),
+		i.Window)
+}
+
+// fingerprintHint describes a key being typed into an entry
+func (i *index) fingerprintHint(keyHex string) string {
+	if keyHex == "" {
+		return ""
+	}
+	encryptionUtils := &EncryptionUtils{}
+	if _, err := encryptionUtils.ParsePublicKeyFromHex(keyHex); err != nil {
+		return "Not a valid public key"
+	}
+	return "Key: " + strings.ReplaceAll(i.describeKey(keyHex), "\n", " - ")
+}
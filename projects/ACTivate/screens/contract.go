@@ -1,33 +1,71 @@
 package screens
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethersphere/bee/v2/pkg/sctx"
 	"github.com/ethersphere/bee/v2/pkg/transaction"
 )
 
+// maxNotifyTargets is the most targets the contract accepts in one
+// sendDataToTargets call
+const maxNotifyTargets = 100
+
 type DataContractInterface interface {
 	SendDataToTarget(ctx context.Context, target common.Address, owner, actRef []byte, topic string) (receipt *types.Receipt, err error)
-	SubscribeDataSentToTarget(ctx context.Context, client *ethclient.Client, sink chan<- types.Log) (ethereum.Subscription, error)
+	SendDataToTargets(ctx context.Context, targets []common.Address, owner, actRef []byte, topics []string) (receipt *types.Receipt, err error)
+	SendNotification(ctx context.Context, target common.Address, n *notification) (receipt *types.Receipt, err error)
+	SendNotifications(ctx context.Context, targets []common.Address, notifications []*notification) (receipt *types.Receipt, err error)
+	EstimateSendNotification(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, n *notification) (*feeEstimate, error)
+	EstimateSendDataToTarget(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, topic string) (*feeEstimate, error)
+	SubscribeDataSentToTarget(ctx context.Context, client logClient, recipients []common.Address, from logCheckpoint, sink chan<- streamedLog) (ethereum.Subscription, error)
+}
+
+// contractTransactor sends transactions to a contract through the bee
+// transaction service and journals them
+type contractTransactor struct {
+	owner              common.Address
+	address            common.Address
+	abi                abi.ABI
+	transactionService transaction.Service
+	gasLimit           uint64
+	journal            *txJournal
+}
+
+func newContractTransactor(owner, address common.Address, contractABI abi.ABI, transactionService transaction.Service, setGasLimit bool, journal *txJournal) contractTransactor {
+	var gasLimit uint64
+	if setGasLimit {
+		gasLimit = transaction.DefaultGasLimit
+	}
+	return contractTransactor{
+		owner:              owner,
+		address:            address,
+		abi:                contractABI,
+		transactionService: transactionService,
+		gasLimit:           gasLimit,
+		journal:            journal,
+	}
 }
 
 type datacontract struct {
-	owner               common.Address
-	dataContractAddress common.Address
-	dataContractABI     abi.ABI
-	transactionService  transaction.Service
-	gasLimit            uint64
-	dataSentToTarget    common.Hash
+	contractTransactor
+	dataSentToTarget   common.Hash
+	dataSentToTargetV2 common.Hash
+	pollInterval       time.Duration
+	confirmations      uint64
 }
 
 func NewDataContract(
@@ -36,20 +74,16 @@ func NewDataContract(
 	dataContractABI abi.ABI,
 	transactionService transaction.Service,
 	setGasLimit bool,
+	pollInterval time.Duration,
+	confirmations uint64,
+	journal *txJournal,
 ) DataContractInterface {
-
-	var gasLimit uint64
-	if setGasLimit {
-		gasLimit = transaction.DefaultGasLimit
-	}
-
 	return &datacontract{
-		owner:               owner,
-		dataContractAddress: dataContractAddress,
-		dataContractABI:     dataContractABI,
-		transactionService:  transactionService,
-		gasLimit:            gasLimit,
-		dataSentToTarget:    dataContractABI.Events["DataSentToTarget"].ID,
+		contractTransactor: newContractTransactor(owner, dataContractAddress, dataContractABI, transactionService, setGasLimit, journal),
+		dataSentToTarget:   dataContractABI.Events["DataSentToTarget"].ID,
+		dataSentToTargetV2: dataContractABI.Events["DataSentToTargetV2"].ID,
+		pollInterval:       pollInterval,
+		confirmations:      confirmations,
 	}
 }
 
@@ -61,7 +95,7 @@ func (c *datacontract) SendDataToTarget(ctx context.Context, target common.Addre
 	copy(ownerArray[:], owner)
 	copy(actRefArray[:], actRef)
 
-	callData, err := c.dataContractABI.Pack("sendDataToTarget", target, ownerArray, actRefArray, topic)
+	callData, err := c.abi.Pack("sendDataToTarget", target, ownerArray, actRefArray, topic)
 	if err != nil {
 		return nil, err
 	}
@@ -74,72 +108,345 @@ func (c *datacontract) SendDataToTarget(ctx context.Context, target common.Addre
 	return receipt, nil
 }
 
-func (c *datacontract) SubscribeDataSentToTarget(ctx context.Context, client *ethclient.Client, sink chan<- types.Log) (ethereum.Subscription, error) {
-	if client == nil {
-		return nil, errors.New("ethclient.Client is nil")
+// SendDataToTargets notifies several targets in one transaction, topics[n] is
+// sent to targets[n]. The contract caps a batch at maxNotifyTargets.
+func (c *datacontract) SendDataToTargets(ctx context.Context, targets []common.Address, owner, actRef []byte, topics []string) (receipt *types.Receipt, err error) {
+	if len(targets) == 0 || len(targets) != len(topics) {
+		return nil, fmt.Errorf("send data to targets: %d targets for %d topics", len(targets), len(topics))
+	}
+	if len(targets) > maxNotifyTargets {
+		return nil, fmt.Errorf("send data to targets: %d targets, at most %d per transaction", len(targets), maxNotifyTargets)
 	}
 
-	currentBlock, err := client.BlockNumber(context.Background())
+	var ownerArray [32]byte
+	var actRefArray [32]byte
+	copy(ownerArray[:], owner)
+	copy(actRefArray[:], actRef)
+
+	callData, err := c.abi.Pack("sendDataToTargets", targets, ownerArray, actRefArray, topics)
 	if err != nil {
-		log.Println("Error getting current block number for admin subscription:", err)
-	} else {
-		currentBlock = 40581246
+		return nil, err
 	}
-	log.Printf("Obtained currentBlock: %d for admin subscription", currentBlock)
 
-	fromBlockBigInt := new(big.Int).SetUint64(currentBlock)
+	receipt, err = c.sendTransaction(ctx, callData, "sendDataToTargets")
+	if err != nil {
+		return nil, fmt.Errorf("send data to targets: %w", err)
+	}
+
+	return receipt, nil
+}
 
-	log.Printf("Subscribing to DataContract DataSentToTarget events from block %s", fromBlockBigInt.String())
+// SendNotification sends a compact DataSentToTargetV2 notification to target
+func (c *datacontract) SendNotification(ctx context.Context, target common.Address, n *notification) (receipt *types.Receipt, err error) {
+	callData, err := c.abi.Pack("sendDataToTargetV2", target, uint8(n.Version), n.PublisherKey, [32]byte(n.HistoryRef), n.Payload)
+	if err != nil {
+		return nil, err
+	}
 
-	const blockPageSize = 500
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{c.dataContractAddress},
-		Topics:    [][]common.Hash{{c.dataSentToTarget}},
-		FromBlock: fromBlockBigInt,
-		ToBlock:   big.NewInt(int64(currentBlock + blockPageSize - 1)),
+	receipt, err = c.sendTransaction(ctx, callData, "sendDataToTargetV2")
+	if err != nil {
+		return nil, fmt.Errorf("send notification: %w", err)
 	}
 
-	logs := make(chan types.Log)
+	return receipt, nil
+}
 
-	sub, err := client.SubscribeFilterLogs(ctx, query, logs)
+// EstimateSendNotification estimates what SendNotification costs at the given
+// speed. Sending with withFee(ctx, estimate) uses the estimated fees.
+func (c *datacontract) EstimateSendNotification(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, n *notification) (*feeEstimate, error) {
+	callData, err := c.abi.Pack("sendDataToTargetV2", target, uint8(n.Version), n.PublisherKey, [32]byte(n.HistoryRef), n.Payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe to DataSentToTarget events: %w", err)
+		return nil, err
+	}
+	return estimateFee(ctx, client, ethereum.CallMsg{
+		From: c.owner,
+		To:   &c.address,
+		Data: callData,
+	}, speed)
+}
+
+// EstimateSendDataToTarget estimates what SendDataToTarget costs at the given
+// speed for a topic without owner and ACT reference
+func (c *datacontract) EstimateSendDataToTarget(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, topic string) (*feeEstimate, error) {
+	callData, err := c.abi.Pack("sendDataToTarget", target, [32]byte{}, [32]byte{}, topic)
+	if err != nil {
+		return nil, err
 	}
+	return estimateFee(ctx, client, ethereum.CallMsg{
+		From: c.owner,
+		To:   &c.address,
+		Data: callData,
+	}, speed)
+}
 
-	go func() {
-		defer close(sink)
-		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("Subscription context cancelled, unsubscribing.")
-				sub.Unsubscribe()
-				return
-			case err := <-sub.Err():
-				fmt.Printf("Event subscription error for DataSentToTarget: %v\\n", err)
-				return
-			case vLog := <-logs:
-				select {
-				case sink <- vLog:
-					fmt.Printf("Received DataSentToTarget TxHash: %s\\n", vLog.TxHash.Hex())
-					fmt.Printf("Received DataSentToTarget data: %s\\n", vLog.Data)
-				case <-ctx.Done():
-					fmt.Println("Subscription context cancelled while trying to send log, unsubscribing.")
-					sub.Unsubscribe()
-					return
-				}
-			}
+// SendNotifications notifies several targets in one transaction,
+// notifications[n] is sent to targets[n]. The notifications only differ in
+// their payloads, which the contract caps at maxNotifyTargets.
+func (c *datacontract) SendNotifications(ctx context.Context, targets []common.Address, notifications []*notification) (receipt *types.Receipt, err error) {
+	if len(targets) == 0 || len(targets) != len(notifications) {
+		return nil, fmt.Errorf("send notifications: %d targets for %d notifications", len(targets), len(notifications))
+	}
+	if len(targets) > maxNotifyTargets {
+		return nil, fmt.Errorf("send notifications: %d targets, at most %d per transaction", len(targets), maxNotifyTargets)
+	}
+
+	first := notifications[0]
+	payloads := make([][]byte, len(notifications))
+	for n, notification := range notifications {
+		if notification.Version != first.Version || notification.HistoryRef != first.HistoryRef || !bytes.Equal(notification.PublisherKey, first.PublisherKey) {
+			return nil, fmt.Errorf("send notifications: notification %d differs from the first in more than its payload", n)
 		}
-	}()
+		payloads[n] = notification.Payload
+	}
 
+	callData, err := c.abi.Pack("sendDataToTargetsV2", targets, uint8(first.Version), first.PublisherKey, [32]byte(first.HistoryRef), payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err = c.sendTransaction(ctx, callData, "sendDataToTargetsV2")
+	if err != nil {
+		return nil, fmt.Errorf("send notifications: %w", err)
+	}
+
+	return receipt, nil
+}
+
+// codeClient is the part of the chain client reading contract code
+type codeClient interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// deployedFunctions reports which functions of contractABI the code at address
+// has. A deployment can lag behind the contract source the ABI comes from, and
+// calling a function it lacks reverts. solc dispatches on PUSH4 <selector>, so
+// the selectors are looked up in the code.
+func deployedFunctions(ctx context.Context, client codeClient, address common.Address, contractABI abi.ABI) (map[string]bool, error) {
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get contract code: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract at %s", address.Hex())
+	}
+	functions := make(map[string]bool, len(contractABI.Methods))
+	for name, method := range contractABI.Methods {
+		functions[name] = bytes.Contains(code, append([]byte{0x63}, method.ID...))
+	}
+	return functions, nil
+}
+
+// SubscribeDataSentToTarget streams DataSentToTarget and DataSentToTargetV2
+// events sent to one of the recipients from the checkpoint on, backfilling the history before handing off to
+// the live subscription, or to polling on endpoints without subscriptions.
+// Events are delivered once they have the configured confirmations, reorged
+// ones again as removed.
+func (c *datacontract) SubscribeDataSentToTarget(ctx context.Context, client logClient, recipients []common.Address, from logCheckpoint, sink chan<- streamedLog) (ethereum.Subscription, error) {
+	if client == nil {
+		return nil, errors.New("ethclient.Client is nil")
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients to subscribe for")
+	}
+
+	log.Printf("Subscribing to DataContract DataSentToTarget events for %d recipients from %s", len(recipients), from.Cursor)
+
+	query := c.dataSentToTargetQuery(recipients)
+	config := logStreamConfig{
+		PageSize:      eventPageSize,
+		PollInterval:  c.pollInterval,
+		Confirmations: c.confirmations,
+	}
+	sub, err := subscribeLogs(ctx, client, query, from, config, sink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to DataSentToTarget events: %w", err)
+	}
 	return sub, nil
 }
 
-func (c *datacontract) sendTransaction(ctx context.Context, callData []byte, desc string) (receipt *types.Receipt, err error) {
+// dataSentToTargetQuery filters DataSentToTarget and DataSentToTargetV2
+// events on the indexed "to" topic, so the node only sees the events sent to
+// the recipients
+func (c *datacontract) dataSentToTargetQuery(recipients []common.Address) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		// topics are the signature, then the indexed from and to
+		Topics: [][]common.Hash{{c.dataSentToTarget, c.dataSentToTargetV2}, nil, recipientTopics(recipients)},
+	}
+}
+
+// DataSentToTargetEvent is a decoded DataSentToTarget log
+type DataSentToTargetEvent struct {
+	From   common.Address
+	To     common.Address
+	Owner  [32]byte
+	ActRef [32]byte
+	Topic  string
+	Raw    types.Log
+}
+
+var (
+	// ErrNotDataSentToTarget is returned for logs of other events
+	ErrNotDataSentToTarget = errors.New("log is not a DataSentToTarget event")
+	// ErrMalformedDataSentToTarget is returned for logs with the event's
+	// signature that do not decode to valid fields
+	ErrMalformedDataSentToTarget = errors.New("malformed DataSentToTarget event")
+)
+
+var dataSentToTargetEvent = sync.OnceValue(func() abi.Event {
+	parsedABI, _ := ParseContractABI()
+	return parsedABI.Events["DataSentToTarget"]
+})
+
+// ParseDataSentToTarget decodes and validates a DataSentToTarget log
+func ParseDataSentToTarget(l types.Log) (*DataSentToTargetEvent, error) {
+	event := dataSentToTargetEvent()
+	if len(l.Topics) == 0 || l.Topics[0] != event.ID {
+		return nil, ErrNotDataSentToTarget
+	}
+	// the signature, then the indexed from and to
+	if len(l.Topics) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 topics, got %d", ErrMalformedDataSentToTarget, len(l.Topics))
+	}
+	from, err := topicAddress(l.Topics[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: from: %v", ErrMalformedDataSentToTarget, err)
+	}
+	to, err := topicAddress(l.Topics[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: to: %v", ErrMalformedDataSentToTarget, err)
+	}
+	if to == (common.Address{}) {
+		return nil, fmt.Errorf("%w: zero target address", ErrMalformedDataSentToTarget)
+	}
+
+	values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedDataSentToTarget, err)
+	}
+	owner, ownerOK := values[0].([32]byte)
+	actRef, actRefOK := values[1].([32]byte)
+	topic, topicOK := values[2].(string)
+	if !ownerOK || !actRefOK || !topicOK {
+		return nil, fmt.Errorf("%w: unexpected field types %T, %T, %T", ErrMalformedDataSentToTarget, values[0], values[1], values[2])
+	}
+	if !utf8.ValidString(topic) {
+		return nil, fmt.Errorf("%w: topic is not valid UTF-8", ErrMalformedDataSentToTarget)
+	}
+
+	return &DataSentToTargetEvent{
+		From:   from,
+		To:     to,
+		Owner:  owner,
+		ActRef: actRef,
+		Topic:  topic,
+		Raw:    l,
+	}, nil
+}
+
+// DataSentToTargetV2Event is a decoded DataSentToTargetV2 log
+type DataSentToTargetV2Event struct {
+	From         common.Address
+	To           common.Address
+	Version      uint8
+	PublisherKey []byte
+	HistoryRef   [32]byte
+	Payload      []byte
+	Raw          types.Log
+}
+
+// notification is the notification the event carries
+func (e *DataSentToTargetV2Event) notification() *notification {
+	return &notification{
+		Version:      notificationVersion(e.Version),
+		PublisherKey: e.PublisherKey,
+		HistoryRef:   e.HistoryRef,
+		Payload:      e.Payload,
+	}
+}
+
+var (
+	// ErrNotDataSentToTargetV2 is returned for logs of other events
+	ErrNotDataSentToTargetV2 = errors.New("log is not a DataSentToTargetV2 event")
+	// ErrMalformedDataSentToTargetV2 is returned for logs with the event's
+	// signature that do not decode to valid fields
+	ErrMalformedDataSentToTargetV2 = errors.New("malformed DataSentToTargetV2 event")
+)
+
+var dataSentToTargetV2Event = sync.OnceValue(func() abi.Event {
+	parsedABI, _ := ParseContractABI()
+	return parsedABI.Events["DataSentToTargetV2"]
+})
+
+// isDataSentToTargetV2 reports whether a log has the DataSentToTargetV2 signature
+func isDataSentToTargetV2(l types.Log) bool {
+	return len(l.Topics) > 0 && l.Topics[0] == dataSentToTargetV2Event().ID
+}
+
+// ParseDataSentToTargetV2 decodes and validates a DataSentToTargetV2 log
+func ParseDataSentToTargetV2(l types.Log) (*DataSentToTargetV2Event, error) {
+	if !isDataSentToTargetV2(l) {
+		return nil, ErrNotDataSentToTargetV2
+	}
+	// the signature, then the indexed from and to
+	if len(l.Topics) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 topics, got %d", ErrMalformedDataSentToTargetV2, len(l.Topics))
+	}
+	from, err := topicAddress(l.Topics[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: from: %v", ErrMalformedDataSentToTargetV2, err)
+	}
+	to, err := topicAddress(l.Topics[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: to: %v", ErrMalformedDataSentToTargetV2, err)
+	}
+	if to == (common.Address{}) {
+		return nil, fmt.Errorf("%w: zero target address", ErrMalformedDataSentToTargetV2)
+	}
+
+	values, err := dataSentToTargetV2Event().Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedDataSentToTargetV2, err)
+	}
+	version, versionOK := values[0].(uint8)
+	publisherKey, publisherKeyOK := values[1].([]byte)
+	historyRef, historyRefOK := values[2].([32]byte)
+	payload, payloadOK := values[3].([]byte)
+	if !versionOK || !publisherKeyOK || !historyRefOK || !payloadOK {
+		return nil, fmt.Errorf("%w: unexpected field types %T, %T, %T, %T", ErrMalformedDataSentToTargetV2, values[0], values[1], values[2], values[3])
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: zero version", ErrMalformedDataSentToTargetV2)
+	}
+	if len(publisherKey) != 0 && len(publisherKey) != compressedKeySize {
+		return nil, fmt.Errorf("%w: %d-byte publisher key", ErrMalformedDataSentToTargetV2, len(publisherKey))
+	}
+
+	return &DataSentToTargetV2Event{
+		From:         from,
+		To:           to,
+		Version:      version,
+		PublisherKey: publisherKey,
+		HistoryRef:   historyRef,
+		Payload:      payload,
+		Raw:          l,
+	}, nil
+}
+
+// topicAddress decodes an indexed address, which is left padded with zeros
+func topicAddress(topic common.Hash) (common.Address, error) {
+	if slices.ContainsFunc(topic[:common.HashLength-common.AddressLength], func(b byte) bool { return b != 0 }) {
+		return common.Address{}, fmt.Errorf("%s is not an address", topic.Hex())
+	}
+	return common.BytesToAddress(topic[common.HashLength-common.AddressLength:]), nil
+}
+
+func (c *contractTransactor) sendTransaction(ctx context.Context, callData []byte, desc string) (receipt *types.Receipt, err error) {
 	request := &transaction.TxRequest{
-		To:          &c.dataContractAddress,
+		To:          &c.address,
 		Data:        callData,
 		GasPrice:    sctx.GetGasPrice(ctx),
-		GasLimit:    max(sctx.GetGasLimit(ctx), c.gasLimit),
+		GasLimit:    sctx.GetGasLimitWithDefault(ctx, c.gasLimit),
 		Value:       big.NewInt(0),
 		Description: desc,
 	}
@@ -149,19 +456,25 @@ func (c *datacontract) sendTransaction(ctx context.Context, callData []byte, des
 			ctx,
 			request,
 			err,
-			c.dataContractABI.Errors,
+			c.abi.Errors,
 		)
 	}()
 
-	txHash, err := c.transactionService.Send(ctx, request, transaction.DefaultTipBoostPercent)
+	txHash, err := c.transactionService.Send(ctx, request, tipBoost(ctx))
 	if err != nil {
 		return nil, err
 	}
+	c.journalSent(txHash, request)
 
 	receipt, err = c.transactionService.WaitForReceipt(ctx, txHash)
 	if err != nil {
 		return nil, err
 	}
+	if c.journal != nil {
+		if err := c.journal.settle(receipt); err != nil {
+			log.Printf("Failed to journal receipt of %s: %v", txHash.Hex(), err)
+		}
+	}
 
 	if receipt.Status == 0 {
 		return nil, transaction.ErrTransactionReverted
@@ -169,3 +482,33 @@ func (c *datacontract) sendTransaction(ctx context.Context, callData []byte, des
 
 	return receipt, nil
 }
+
+// journalSent records a sent transaction with the nonce and fees the
+// transaction service gave it, so it can be settled after a restart
+func (c *contractTransactor) journalSent(txHash common.Hash, request *transaction.TxRequest) {
+	if c.journal == nil {
+		return
+	}
+	rec := &txRecord{
+		Hash:        txHash,
+		From:        c.owner,
+		To:          *request.To,
+		Data:        request.Data,
+		Description: request.Description,
+		Params:      describeCall(c.abi, request.Data),
+		GasLimit:    request.GasLimit,
+		Sent:        time.Now(),
+		Status:      txPending,
+	}
+	if stored, err := c.transactionService.StoredTransaction(txHash); err == nil {
+		rec.Nonce = stored.Nonce
+		rec.GasLimit = stored.GasLimit
+		rec.GasTipCap = stored.GasTipCap
+		rec.GasFeeCap = stored.GasFeeCap
+	} else {
+		log.Printf("Failed to read sent transaction %s: %v", txHash.Hex(), err)
+	}
+	if err := c.journal.add(rec); err != nil {
+		log.Printf("Failed to journal transaction %s: %v", txHash.Hex(), err)
+	}
+}
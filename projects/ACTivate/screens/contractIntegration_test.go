@@ -0,0 +1,437 @@
+package screens
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	beecrypto "github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/log"
+	statestore "github.com/ethersphere/bee/v2/pkg/statestore/mock"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+// testdata/DataContract.bin is the DataContract creation code from
+// contract/contracts/AdminContract.sol, built the way hardhat.config.ts does:
+//
+//	solc --optimize --optimize-runs 200 --evm-version shanghai --bin contracts/AdminContract.sol
+//
+// Rebuild it when the contract changes.
+const dataContractBinPath = "testdata/DataContract.bin"
+
+// testdata/DataContractV1.bin is the first deployed DataContract, which only
+// has sendDataToTarget
+const dataContractV1BinPath = "testdata/DataContractV1.bin"
+
+// contractHarness is a DataContract deployed on an evmChain, sent to through
+// the bee transaction service the app uses
+type contractHarness struct {
+	chain     *evmChain
+	key       *ecdsa.PrivateKey
+	owner     common.Address
+	address   common.Address
+	txService transaction.Service
+	journal   *txJournal
+	contract  *datacontract
+}
+
+func newContractHarness(t *testing.T, confirmations uint64) *contractHarness {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	chain := newEVMChain(types.GenesisAlloc{owner: {Balance: big.NewInt(params.Ether)}})
+
+	address := deployContract(t, chain, key, dataContractBinPath)
+	txService := newTestTxService(t, chain, key)
+
+	contractABI, err := ParseContractABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored string
+	journal := memoryTxJournal(&stored)
+	// no fixed gas limit, so every send is estimated by the EVM
+	contract := NewDataContract(owner, address, contractABI, txService, false, 10*time.Millisecond, confirmations, journal).(*datacontract)
+	return &contractHarness{
+		chain:     chain,
+		key:       key,
+		owner:     owner,
+		address:   address,
+		txService: txService,
+		journal:   journal,
+		contract:  contract,
+	}
+}
+
+// newTestTxService is the bee transaction service sending from key
+func newTestTxService(t *testing.T, chain *evmChain, key *ecdsa.PrivateKey) transaction.Service {
+	t.Helper()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	monitor := transaction.NewMonitor(log.Noop, chain, from, 10*time.Millisecond, 0)
+	t.Cleanup(func() { monitor.Close() })
+	txService, err := transaction.NewService(log.Noop, from, chain, beecrypto.NewDefaultSigner(key), statestore.NewStateStore(), chain.config.ChainID, monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { txService.Close() })
+	return txService
+}
+
+// deployContract deploys the creation code in binPath from key
+func deployContract(t *testing.T, chain *evmChain, key *ecdsa.PrivateKey, binPath string) common.Address {
+	t.Helper()
+	bin, err := os.ReadFile(binPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := hexutil.Decode("0x" + strings.TrimSpace(string(bin)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	nonce, err := chain.PendingNonceAt(ctx, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas, err := chain.EstimateGas(ctx, ethereum.CallMsg{From: from, Data: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	price, _ := chain.SuggestGasPrice(ctx)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chain.config.ChainID), &types.DynamicFeeTx{
+		ChainID:   chain.config.ChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: price,
+		Gas:       gas,
+		Data:      code,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := chain.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deployment failed: %v", err)
+	}
+	return receipt.ContractAddress
+}
+
+// subscribe streams the DataSentToTarget events for recipients from the
+// start of the chain
+func (h *contractHarness) subscribe(t *testing.T, recipients ...common.Address) <-chan streamedLog {
+	t.Helper()
+	sink := make(chan streamedLog, 16)
+	sub, err := h.contract.SubscribeDataSentToTarget(context.Background(), h.chain, recipients, logCheckpoint{}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sub.Unsubscribe)
+	return sink
+}
+
+// nextEvent decodes the next log on the stream
+func nextEvent(t *testing.T, sink <-chan streamedLog) *DataSentToTargetEvent {
+	t.Helper()
+	item := nextLog(t, sink)
+	event, err := ParseDataSentToTarget(*item.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestSendDataToTargetOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	sink := h.subscribe(t, testTo)
+
+	owner := [32]byte{1}
+	actRef := [32]byte{2}
+	receipt, err := h.contract.SendDataToTarget(context.Background(), testTo, owner[:], actRef[:], "sealed topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("transaction reverted")
+	}
+
+	event := nextEvent(t, sink)
+	if event.From != h.owner || event.To != testTo || event.Owner != owner || event.ActRef != actRef || event.Topic != "sealed topic" {
+		t.Fatalf("got event %+v", event)
+	}
+	if event.Raw.TxHash != receipt.TxHash || event.Raw.Address != h.address {
+		t.Fatalf("event from %s in %s", event.Raw.Address.Hex(), event.Raw.TxHash.Hex())
+	}
+
+	records, err := h.journal.records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := records.byHash(receipt.TxHash)
+	if rec == nil || rec.Status != txConfirmed || rec.Block != receipt.BlockNumber.Uint64() || !strings.HasPrefix(rec.Params, "sendDataToTarget(") {
+		t.Fatalf("journaled %+v", rec)
+	}
+}
+
+func TestSendDataToTargetsOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	sink := h.subscribe(t, testTo, testFrom)
+
+	targets := []common.Address{testTo, other, testFrom}
+	topics := []string{"to", "other", "from"}
+	receipt, err := h.contract.SendDataToTargets(context.Background(), targets, nil, nil, topics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipt.Logs) != len(targets) {
+		t.Fatalf("%d logs for %d targets", len(receipt.Logs), len(targets))
+	}
+
+	// the node filters by recipient, the other target is not delivered
+	for _, want := range []struct {
+		to    common.Address
+		topic string
+	}{{testTo, "to"}, {testFrom, "from"}} {
+		event := nextEvent(t, sink)
+		if event.To != want.to || event.Topic != want.topic {
+			t.Fatalf("got event to %s with %q", event.To.Hex(), event.Topic)
+		}
+	}
+	expectNoLog(t, sink, 100*time.Millisecond)
+}
+
+func TestSendDataToTargetRevertOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	_, err := h.contract.SendDataToTarget(context.Background(), common.Address{}, nil, nil, "topic")
+	if err == nil || !strings.Contains(err.Error(), "target cannot be zero address") {
+		t.Fatalf("got %v, want the revert reason", err)
+	}
+	if pending, _ := h.txService.PendingTransactions(); len(pending) != 0 {
+		t.Fatalf("%d transactions sent", len(pending))
+	}
+}
+
+func TestEventConfirmationsOnChain(t *testing.T) {
+	h := newContractHarness(t, 2)
+	sink := h.subscribe(t, testTo)
+
+	receipt, err := h.contract.SendDataToTarget(context.Background(), testTo, nil, nil, "topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectNoLog(t, sink, 100*time.Millisecond)
+	if err := h.chain.advance(1); err != nil {
+		t.Fatal(err)
+	}
+	expectNoLog(t, sink, 100*time.Millisecond)
+	if err := h.chain.advance(1); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(t, sink); event.Raw.TxHash != receipt.TxHash {
+		t.Fatalf("got event from %s", event.Raw.TxHash.Hex())
+	}
+}
+
+func TestEstimateSendNotificationOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	ctx := context.Background()
+	n := &notification{Version: notificationSealed, Payload: []byte("sealed payload")}
+
+	estimate, err := h.contract.EstimateSendNotification(ctx, h.chain, feeFast, testTo, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !estimate.sufficient() {
+		t.Fatalf("balance %s does not cover %s", estimate.Balance, estimate.Max)
+	}
+
+	receipt, err := h.contract.SendNotification(withFee(ctx, estimate), testTo, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.GasUsed != estimate.GasUsed {
+		t.Fatalf("used %d gas, estimated %d", receipt.GasUsed, estimate.GasUsed)
+	}
+	stored, err := h.txService.StoredTransaction(receipt.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.GasLimit != estimate.GasLimit || stored.GasTipCap.Cmp(estimate.TipCap) != 0 || stored.GasFeeCap.Cmp(estimate.FeeCap) != 0 {
+		t.Fatalf("sent with gas %d, tip %s, fee cap %s; estimated %d, %s, %s",
+			stored.GasLimit, stored.GasTipCap, stored.GasFeeCap, estimate.GasLimit, estimate.TipCap, estimate.FeeCap)
+	}
+}
+
+func TestEstimateSendDataToTargetOnV1Chain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	ctx := context.Background()
+	v1 := deployContract(t, h.chain, h.key, dataContractV1BinPath)
+	contract := NewDataContract(h.owner, v1, h.contract.abi, h.txService, false, 10*time.Millisecond, 0, h.journal)
+
+	estimate, err := contract.EstimateSendDataToTarget(ctx, h.chain, feeNormal, testTo, "sealed topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := contract.SendDataToTarget(withFee(ctx, estimate), testTo, nil, nil, "sealed topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.GasUsed != estimate.GasUsed {
+		t.Fatalf("used %d gas, estimated %d", receipt.GasUsed, estimate.GasUsed)
+	}
+	// the compact notification reverts on the first deployment
+	if _, err := contract.EstimateSendNotification(ctx, h.chain, feeNormal, testTo, &notification{Version: notificationSealed, Payload: []byte("x")}); err == nil {
+		t.Fatal("estimated sendDataToTargetV2 on a contract without it")
+	}
+}
+
+func TestSendNotificationOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	ctx := context.Background()
+	recipient := mustGenerateKey(t)
+	target := crypto.PubkeyToAddress(recipient.PublicKey)
+	sink := h.subscribe(t, target)
+
+	payload := sharePayload{Kind: payloadKindACT, Reference: make([]byte, 32), History: bytes.Repeat([]byte{1}, 32)}
+	_, n, err := sealNotification(h.key, payload, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, topic, err := sealTopic(h.key, payload, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a legacy notification, then the same one compact, on the same stream
+	legacy, err := h.contract.SendDataToTarget(ctx, target, nil, nil, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := h.contract.SendNotification(ctx, target, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := nextEvent(t, sink)
+	if v1.Raw.TxHash != legacy.TxHash {
+		t.Fatalf("got event from %s", v1.Raw.TxHash.Hex())
+	}
+	item := nextLog(t, sink)
+	v2, err := ParseDataSentToTargetV2(*item.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.From != h.owner || v2.To != target || v2.Version != uint8(notificationSealed) || len(v2.PublisherKey) != 0 || v2.HistoryRef != ([32]byte{}) || v2.Raw.TxHash != compact.TxHash {
+		t.Fatalf("got event %+v", v2)
+	}
+	encryptionUtils := &EncryptionUtils{}
+	received, err := encryptionUtils.openNotification(v2.notification(), recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := received.verify(target); status != payloadVerified || !bytes.Equal(received.History, payload.History) {
+		t.Fatalf("received a %s payload with history %x", status, received.History)
+	}
+
+	// the compact notification takes well under the calldata of the topic
+	legacyTx, _, _ := h.chain.TransactionByHash(ctx, legacy.TxHash)
+	compactTx, _, _ := h.chain.TransactionByHash(ctx, compact.TxHash)
+	if 2*len(compactTx.Data()) > len(legacyTx.Data()) {
+		t.Fatalf("compact calldata %d bytes, legacy %d bytes", len(compactTx.Data()), len(legacyTx.Data()))
+	}
+}
+
+func TestSendNotificationsOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	sink := h.subscribe(t, testTo, testFrom)
+
+	targets := []common.Address{testTo, other, testFrom}
+	notifications := []*notification{
+		{Version: notificationSealed, Payload: []byte("to")},
+		{Version: notificationSealed, Payload: []byte("other")},
+		{Version: notificationSealed, Payload: []byte("from")},
+	}
+	receipt, err := h.contract.SendNotifications(context.Background(), targets, notifications)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipt.Logs) != len(targets) {
+		t.Fatalf("%d logs for %d targets", len(receipt.Logs), len(targets))
+	}
+	for _, want := range []struct {
+		to      common.Address
+		payload string
+	}{{testTo, "to"}, {testFrom, "from"}} {
+		item := nextLog(t, sink)
+		event, err := ParseDataSentToTargetV2(*item.Log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.To != want.to || string(event.Payload) != want.payload {
+			t.Fatalf("got event to %s with %q", event.To.Hex(), event.Payload)
+		}
+	}
+	expectNoLog(t, sink, 100*time.Millisecond)
+
+	// a batch shares everything but the payloads
+	notifications[1] = &notification{Version: notificationSigned, Payload: []byte("other")}
+	if _, err := h.contract.SendNotifications(context.Background(), targets, notifications); err == nil {
+		t.Fatal("sent notifications of different versions in one batch")
+	}
+	if _, err := h.contract.SendNotification(context.Background(), testTo, &notification{Payload: []byte("x")}); err == nil || !strings.Contains(err.Error(), "version cannot be zero") {
+		t.Fatalf("got %v, want the revert reason", err)
+	}
+}
+
+func TestDeployedFunctionsOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	ctx := context.Background()
+	functions, err := deployedFunctions(ctx, h.chain, h.address, h.contract.abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sendDataToTarget", "sendDataToTargets", "sendDataToTargetWithSig", "sendDataToTargetV2", "sendDataToTargetsV2"} {
+		if !functions[name] {
+			t.Errorf("%s not found in the current contract", name)
+		}
+	}
+	// the ABI still lists functions of an earlier admin contract
+	if functions["getAdmin"] {
+		t.Error("found getAdmin in the current contract")
+	}
+
+	v1 := deployContract(t, h.chain, h.key, dataContractV1BinPath)
+	functions, err = deployedFunctions(ctx, h.chain, v1, h.contract.abi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, found := range functions {
+		if found != (name == "sendDataToTarget") {
+			t.Errorf("first deployment has %s: %v", name, found)
+		}
+	}
+
+	if _, err := deployedFunctions(ctx, h.chain, common.Address{1}, h.contract.abi); err == nil {
+		t.Fatal("found functions where there is no contract")
+	}
+}
@@ -0,0 +1,179 @@
+package screens
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testFrom = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testTo   = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// dataSentToTargetLog encodes a DataSentToTarget log the way the contract emits it
+func dataSentToTargetLog(t testing.TB, from, to common.Address, owner, actRef [32]byte, topic string) types.Log {
+	t.Helper()
+	event := dataSentToTargetEvent()
+	topics, err := abi.MakeTopics([]interface{}{from}, []interface{}{to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := event.Inputs.NonIndexed().Pack(owner, actRef, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{
+		Topics: []common.Hash{event.ID, topics[0][0], topics[1][0]},
+		Data:   data,
+	}
+}
+
+func TestParseDataSentToTarget(t *testing.T) {
+	owner := [32]byte{1}
+	actRef := [32]byte{2}
+	valid := dataSentToTargetLog(t, testFrom, testTo, owner, actRef, "hello")
+
+	event, err := ParseDataSentToTarget(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.From != testFrom || event.To != testTo || event.Owner != owner || event.ActRef != actRef || event.Topic != "hello" {
+		t.Fatalf("decoded %+v", event)
+	}
+
+	modified := func(fn func(l *types.Log)) types.Log {
+		l := valid
+		l.Topics = append([]common.Hash(nil), valid.Topics...)
+		l.Data = append([]byte(nil), valid.Data...)
+		fn(&l)
+		return l
+	}
+	tests := []struct {
+		name string
+		log  types.Log
+		want error
+	}{
+		{"no topics", types.Log{}, ErrNotDataSentToTarget},
+		{"other event", modified(func(l *types.Log) { l.Topics[0] = common.Hash{1} }), ErrNotDataSentToTarget},
+		{"missing topic", modified(func(l *types.Log) { l.Topics = l.Topics[:2] }), ErrMalformedDataSentToTarget},
+		{"extra topic", modified(func(l *types.Log) { l.Topics = append(l.Topics, common.Hash{}) }), ErrMalformedDataSentToTarget},
+		{"dirty from padding", modified(func(l *types.Log) { l.Topics[1][0] = 1 }), ErrMalformedDataSentToTarget},
+		{"dirty to padding", modified(func(l *types.Log) { l.Topics[2][11] = 1 }), ErrMalformedDataSentToTarget},
+		{"zero target", dataSentToTargetLog(t, testFrom, common.Address{}, owner, actRef, "hello"), ErrMalformedDataSentToTarget},
+		{"no data", modified(func(l *types.Log) { l.Data = nil }), ErrMalformedDataSentToTarget},
+		{"truncated data", modified(func(l *types.Log) { l.Data = l.Data[:len(l.Data)-32] }), ErrMalformedDataSentToTarget},
+		{"string offset out of range", modified(func(l *types.Log) { l.Data[95] = 0xff }), ErrMalformedDataSentToTarget},
+		{"string length out of range", modified(func(l *types.Log) { l.Data[127] = 0xff }), ErrMalformedDataSentToTarget},
+		{"invalid utf-8", dataSentToTargetLog(t, testFrom, testTo, owner, actRef, "\xff\xfe"), ErrMalformedDataSentToTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseDataSentToTarget(tt.log)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %+v, %v, want %v", event, err, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseDataSentToTarget(f *testing.F) {
+	valid := dataSentToTargetLog(f, testFrom, testTo, [32]byte{1}, [32]byte{2}, "hello")
+	f.Add(valid.Topics[1].Bytes(), valid.Topics[2].Bytes(), valid.Data)
+	f.Add(valid.Topics[1].Bytes(), valid.Topics[2].Bytes(), valid.Data[:96])
+	f.Add([]byte{}, []byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, from, to, data []byte) {
+		l := types.Log{
+			Topics: []common.Hash{dataSentToTargetEvent().ID, common.BytesToHash(from), common.BytesToHash(to)},
+			Data:   data,
+		}
+		event, err := ParseDataSentToTarget(l)
+		if err != nil {
+			if !errors.Is(err, ErrMalformedDataSentToTarget) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			return
+		}
+
+		// whatever decodes must survive a round trip
+		again, err := ParseDataSentToTarget(dataSentToTargetLog(t, event.From, event.To, event.Owner, event.ActRef, event.Topic))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.From != event.From || again.To != event.To || again.Owner != event.Owner || again.ActRef != event.ActRef || again.Topic != event.Topic {
+			t.Fatalf("round trip changed %+v to %+v", event, again)
+		}
+	})
+}
+
+// dataSentToTargetV2Log encodes a DataSentToTargetV2 log the way the contract emits it
+func dataSentToTargetV2Log(t testing.TB, from, to common.Address, version uint8, publisherKey []byte, historyRef [32]byte, payload []byte) types.Log {
+	t.Helper()
+	event := dataSentToTargetV2Event()
+	topics, err := abi.MakeTopics([]interface{}{from}, []interface{}{to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := event.Inputs.NonIndexed().Pack(version, publisherKey, historyRef, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{
+		Topics: []common.Hash{event.ID, topics[0][0], topics[1][0]},
+		Data:   data,
+	}
+}
+
+func TestParseDataSentToTargetV2(t *testing.T) {
+	publisherKey := make([]byte, compressedKeySize)
+	historyRef := [32]byte{2}
+	valid := dataSentToTargetV2Log(t, testFrom, testTo, 1, publisherKey, historyRef, []byte("payload"))
+
+	event, err := ParseDataSentToTargetV2(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.From != testFrom || event.To != testTo || event.Version != 1 || len(event.PublisherKey) != compressedKeySize ||
+		event.HistoryRef != historyRef || string(event.Payload) != "payload" {
+		t.Fatalf("decoded %+v", event)
+	}
+	// a v1 log is not mistaken for a v2 one, nor the other way round
+	if _, err := ParseDataSentToTarget(valid); !errors.Is(err, ErrNotDataSentToTarget) {
+		t.Fatalf("v2 log parsed as v1: %v", err)
+	}
+
+	modified := func(fn func(l *types.Log)) types.Log {
+		l := valid
+		l.Topics = append([]common.Hash(nil), valid.Topics...)
+		l.Data = append([]byte(nil), valid.Data...)
+		fn(&l)
+		return l
+	}
+	tests := []struct {
+		name string
+		log  types.Log
+		want error
+	}{
+		{"no topics", types.Log{}, ErrNotDataSentToTargetV2},
+		{"v1 event", dataSentToTargetLog(t, testFrom, testTo, [32]byte{}, [32]byte{}, "hello"), ErrNotDataSentToTargetV2},
+		{"missing topic", modified(func(l *types.Log) { l.Topics = l.Topics[:2] }), ErrMalformedDataSentToTargetV2},
+		{"dirty to padding", modified(func(l *types.Log) { l.Topics[2][11] = 1 }), ErrMalformedDataSentToTargetV2},
+		{"zero target", dataSentToTargetV2Log(t, testFrom, common.Address{}, 1, nil, historyRef, nil), ErrMalformedDataSentToTargetV2},
+		{"truncated data", modified(func(l *types.Log) { l.Data = l.Data[:len(l.Data)-32] }), ErrMalformedDataSentToTargetV2},
+		{"version out of range", modified(func(l *types.Log) { l.Data[30] = 1 }), ErrMalformedDataSentToTargetV2},
+		{"zero version", dataSentToTargetV2Log(t, testFrom, testTo, 0, nil, historyRef, nil), ErrMalformedDataSentToTargetV2},
+		{"uncompressed key", dataSentToTargetV2Log(t, testFrom, testTo, 1, make([]byte, 65), historyRef, nil), ErrMalformedDataSentToTargetV2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseDataSentToTargetV2(tt.log)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %+v, %v, want %v", event, err, tt.want)
+			}
+		})
+	}
+}
@@ -102,6 +102,217 @@ const DataContractABI = `[
 		"outputs": [],
 		"stateMutability": "nonpayable",
 		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address[]",
+				"name": "targets",
+				"type": "address[]"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "ownerParam",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "actref",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "string[]",
+				"name": "topics",
+				"type": "string[]"
+			}
+		],
+		"name": "sendDataToTargets",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "DOMAIN_SEPARATOR",
+		"outputs": [
+			{
+				"internalType": "bytes32",
+				"name": "",
+				"type": "bytes32"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "",
+				"type": "address"
+			}
+		],
+		"name": "nonces",
+		"outputs": [
+			{
+				"internalType": "uint256",
+				"name": "",
+				"type": "uint256"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "from",
+				"type": "address"
+			},
+			{
+				"internalType": "address",
+				"name": "target",
+				"type": "address"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "ownerParam",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "actref",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "string",
+				"name": "topic",
+				"type": "string"
+			},
+			{
+				"internalType": "uint256",
+				"name": "deadline",
+				"type": "uint256"
+			},
+			{
+				"internalType": "bytes",
+				"name": "signature",
+				"type": "bytes"
+			}
+		],
+		"name": "sendDataToTargetWithSig",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "from",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "to",
+				"type": "address"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint8",
+				"name": "version",
+				"type": "uint8"
+			},
+			{
+				"indexed": false,
+				"internalType": "bytes",
+				"name": "publisherKey",
+				"type": "bytes"
+			},
+			{
+				"indexed": false,
+				"internalType": "bytes32",
+				"name": "historyRef",
+				"type": "bytes32"
+			},
+			{
+				"indexed": false,
+				"internalType": "bytes",
+				"name": "payload",
+				"type": "bytes"
+			}
+		],
+		"name": "DataSentToTargetV2",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "target",
+				"type": "address"
+			},
+			{
+				"internalType": "uint8",
+				"name": "version",
+				"type": "uint8"
+			},
+			{
+				"internalType": "bytes",
+				"name": "publisherKey",
+				"type": "bytes"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "historyRef",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "bytes",
+				"name": "payload",
+				"type": "bytes"
+			}
+		],
+		"name": "sendDataToTargetV2",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address[]",
+				"name": "targets",
+				"type": "address[]"
+			},
+			{
+				"internalType": "uint8",
+				"name": "version",
+				"type": "uint8"
+			},
+			{
+				"internalType": "bytes",
+				"name": "publisherKey",
+				"type": "bytes"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "historyRef",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "bytes[]",
+				"name": "payloads",
+				"type": "bytes[]"
+			}
+		],
+		"name": "sendDataToTargetsV2",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
 	}
 ]`
 
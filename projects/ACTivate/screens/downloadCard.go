@@ -8,6 +8,7 @@ import (
 	"io"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
@@ -16,37 +17,139 @@ import (
 )
 
 func (i *index) showDownloadCard() *widget.Card {
-	dlForm := i.downloadForm()
-	return widget.NewCard("Download", "download content from swarm", dlForm)
+	dlForm, selectShare := i.downloadForm()
+
+	entries, err := i.loadInbox()
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Error loading inbox: %v", err))
+	}
+	i.inboxMu.Lock()
+	i.inbox = entries
+	i.inboxMu.Unlock()
+
+	i.inboxList = widget.NewList(
+		func() int {
+			return len(i.inbox)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template sender\nfingerprint\nblock and time")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			if id >= len(i.inbox) {
+				return
+			}
+			e := i.inbox[id]
+			text := fmt.Sprintf("%s\nBlock %d, %s, %s", i.describeKey(e.Publisher), e.Log.Block, e.Timestamp.Format("2006-01-02 15:04"), verificationText(e.Verification))
+			if !e.Read {
+				text = "● " + text
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	i.inboxList.OnSelected = func(id widget.ListItemID) {
+		if id < len(i.inbox) {
+			selectShare(i.inbox[id])
+		}
+		i.inboxList.UnselectAll()
+	}
+	inboxScroll := container.NewScroll(i.inboxList)
+	inboxScroll.SetMinSize(fyne.NewSize(350, 150))
+
+	i.inboxCard = widget.NewCard("Download", inboxSubtitle(entries), container.NewVBox(inboxScroll, dlForm))
+	return i.inboxCard
 }
 
-func (i *index) downloadForm() *widget.Form {
+// downloadForm returns the download form and a function that fills it with a
+// share from the inbox
+func (i *index) downloadForm() (*widget.Form, func(*inboxEntry)) {
 	hash := widget.NewEntry()
 	hash.SetPlaceHolder("Swarm Hash")
-	dlForm := &widget.Form{
+	verificationLabel := widget.NewLabel("Pick a share from the inbox")
+	// selected is the inbox share the hash belongs to, nil for other hashes
+	var selected *inboxEntry
+	hash.OnChanged = func(s string) {
+		if selected != nil && selected.Reference == s {
+			return
+		}
+		i.inboxMu.Lock()
+		selected = i.inbox.byReference(s)
+		i.inboxMu.Unlock()
+		switch {
+		case selected != nil:
+			verificationLabel.SetText(verificationText(selected.Verification))
+		case s == "":
+			verificationLabel.SetText("Pick a share from the inbox")
+		default:
+			verificationLabel.SetText("Not in the inbox, downloading without access control")
+		}
+	}
+	selectShare := func(e *inboxEntry) {
+		selected = e
+		hash.SetText(e.Reference)
+		verificationLabel.SetText(verificationText(e.Verification))
+		if e.Read {
+			return
+		}
+		err := i.updateInbox(func(entries *inbox) bool {
+			stored := entries.byLog(e.Log)
+			if stored == nil || stored.Read {
+				return false
+			}
+			stored.Read = true
+			return true
+		})
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Failed to mark share as read: %v", err))
+		}
+	}
+
+	// forgedConfirmed is set while downloading a forged payload the user accepted
+	forgedConfirmed := false
+	var dlForm *widget.Form
+	dlForm = &widget.Form{
 		Items: []*widget.FormItem{
 			{Text: "Swarm Hash", Widget: hash, HintText: "Swarm Hash"},
+			{Text: "Sender", Widget: verificationLabel, HintText: "Signature check of the received payload"},
 		},
 		OnSubmit: func() {
-			// dlAddr, err := swarm.ParseHexAddress(hash.Text)
-			// if err != nil {
-			// 	i.showError(err)
-			// 	return
-			// }
+			share := selected
+			if share != nil && share.Verification == payloadForged.String() && !forgedConfirmed {
+				dialog.ShowConfirm("Forged sender",
+					"The signature of this payload does not match its publisher. Someone may be impersonating the sender.\nDownload anyway?",
+					func(ok bool) {
+						if ok {
+							forgedConfirmed = true
+							dlForm.OnSubmit()
+							forgedConfirmed = false
+						}
+					}, i.Window)
+				return
+			}
 			if hash.Text == "" {
 				i.showError(fmt.Errorf("please enter a hash"))
 				return
 			}
+			bytehash, err := swarm.ParseHexAddress(hash.Text)
+			if err != nil {
+				i.showError(err)
+				return
+			}
 			go func() {
 				i.showProgressWithMessage(fmt.Sprintf("Downloading %s", shortenHashOrAddress(hash.Text)))
 				//ref, fileName, err := i.bl.GetBzz(context.Background(), dlAddr, nil, nil, nil)
-				bytehash, _ := swarm.ParseHexAddress(i.getPreferenceString("event32ByteHex"))
-				acthash, _ := swarm.ParseHexAddress(i.getPreferenceString("eventActRef"))
-				publisherHex := i.getPreferenceString("eventPublicKey")
 
-				// Parse the public key as ECDSA public key from hex encoded string
+				// shares from the inbox are behind the publisher's access control
 				var publisher *ecdsa.PublicKey
-				if publisherHex != "" {
+				var acthash *swarm.Address
+				if share != nil {
+					history, err := swarm.ParseHexAddress(share.History)
+					if err != nil {
+						i.hideProgress()
+						i.showError(fmt.Errorf("invalid history reference: %w", err))
+						return
+					}
+					acthash = &history
+					publisherHex := share.Publisher
 					// Remove 0x prefix if present
 					if len(publisherHex) > 2 && publisherHex[:2] == "0x" {
 						publisherHex = publisherHex[2:]
@@ -71,21 +174,42 @@ func (i *index) downloadForm() *widget.Form {
 					fmt.Printf("Successfully parsed ECDSA public key: %x\n", crypto.FromECDSAPub(publisher))
 				}
 
-				fmt.Println("bytehash", i.getPreferenceString("event32ByteHex"))
-				fmt.Println("acthash", i.getPreferenceString("eventActRef"))
-				fmt.Println("publisher", publisherHex)
-				ref, err := i.bl.GetBytes(context.Background(), bytehash, publisher, &acthash, nil)
+				fmt.Println("bytehash", bytehash)
+				fmt.Println("acthash", acthash)
+				ref, err := i.bl.GetBytes(context.Background(), bytehash, publisher, acthash, nil)
 				if err != nil {
 					i.hideProgress()
 					i.showError(err)
 					return
 				}
 				hash.SetText("")
-				data, err := io.ReadAll(ref)
-				if err != nil {
-					i.hideProgress()
-					i.showError(err)
-					return
+				// encrypted files are decrypted segment by segment while saving
+				data, encrypted := peekEncryptedStream(ref)
+				if encrypted {
+					key, err := i.nodePrivateKey()
+					if err != nil {
+						i.hideProgress()
+						i.showError(fmt.Errorf("cannot decrypt file: %w", err))
+						return
+					}
+					groupKeys, err := i.openingGroupKeys()
+					if err != nil {
+						i.logger.Log(fmt.Sprintf("Failed to load group keys: %v", err))
+					}
+					keys := &keyring{
+						privateKey: key,
+						groupKeys:  groupKeys,
+						fetchGroupKeys: func(bundleRef []byte) ([]*groupKey, error) {
+							return i.fetchGroupKeys(context.Background(), bundleRef)
+						},
+					}
+					encryptionUtils := &EncryptionUtils{}
+					data, err = encryptionUtils.decryptStream(data, keys)
+					if err != nil {
+						i.hideProgress()
+						i.showError(fmt.Errorf("cannot decrypt file: %w", err))
+						return
+					}
 				}
 				i.hideProgress()
 				saveFile := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
@@ -96,12 +220,12 @@ func (i *index) downloadForm() *widget.Form {
 					if writer == nil {
 						return
 					}
-					_, err = writer.Write(data)
+					defer writer.Close()
+					_, err = io.Copy(writer, data)
 					if err != nil {
-						i.showError(err)
+						i.showError(fmt.Errorf("download incomplete: %w", err))
 						return
 					}
-					writer.Close()
 				}, i.Window)
 				//saveFile.SetFileName(fileName)
 				saveFile.Show()
@@ -109,5 +233,19 @@ func (i *index) downloadForm() *widget.Form {
 		},
 	}
 
-	return dlForm
+	return dlForm, selectShare
+}
+
+// verificationText describes a stored verification status for the user
+func verificationText(status string) string {
+	switch status {
+	case payloadVerified.String():
+		return "Verified: signed by the publisher"
+	case payloadForged.String():
+		return "Forged: signature does not match the publisher"
+	case "":
+		return "No payload received yet"
+	default:
+		return "Unverified: the payload is not signed"
+	}
 }
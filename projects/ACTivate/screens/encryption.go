@@ -1,44 +1,63 @@
 package screens
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/hkdf"
 )
 
 /*
-Deterministic ECDH + AES-CTR Encryption Implementation
+ECIES (secp256k1 + HKDF-SHA256 + AES-256-GCM) Encryption Implementation
 
-This implementation uses deterministic encryption to produce same-size output
-as the input data, suitable for fixed-length transaction fields.
+Every message is encrypted to the recipient's public key with a fresh random
+ephemeral key, so the recipient can always decrypt it with the private key alone.
 
 Process:
-1. Derive deterministic ephemeral key from recipient's public key and data
-2. Perform ECDH key exchange with recipient's public key
-3. Derive shared secret using Keccak256 hash
-4. Encrypt data with AES-256-CTR using deterministic IV
-5. Return encrypted data (same size as input)
+1. Generate a random ephemeral secp256k1 key pair
+2. Perform ECDH between the ephemeral private key and the recipient's public key
+3. Derive a 32-byte AES key from the shared point with HKDF-SHA256, bound to both public keys
+4. Encrypt data with AES-256-GCM using a random nonce, authenticating the header
+5. Return the envelope (see envelope.go) carrying the ephemeral public key and ciphertext
 
-Warning: This is deterministic encryption - same input produces same output.
-This trades security for fixed output size. Use only when necessary.
+Decryption repeats the ECDH with the recipient's private key and the ephemeral
+public key carried in the envelope. Only envelopes are decrypted: the
+deterministic ECDH + AES-CTR output of the first release cannot be, its
+ephemeral key derives from the plaintext and was never sent.
 
 Benefits:
-- Same output size as input (no overhead)
-- Compatible with Ethereum's secp256k1 curve
-- Suitable for fixed-length blockchain transaction fields
-
-Security Trade-offs:
-- Deterministic (same input = same output)
-- No authentication (no integrity protection)
-- Still provides confidentiality protection
+- Compatible with Ethereum's secp256k1 curve and the Swarm node key
+- Randomised (same input encrypts differently every time)
+- Authenticated (tampering is detected on decryption)
+
+Overhead:
+- 23 bytes envelope header + 33 bytes ephemeral public key + 16 bytes tag per message
 */
 
-// EncryptionUtils provides ECDH + AES encryption functionality
-type EncryptionUtils struct{}
+const (
+	eciesTagSize = 16
+	eciesKDFInfo = "ACTivate ECIES v1"
+)
+
+var (
+	errCiphertextTooShort = errors.New("ciphertext too short")
+	errDecryptionFailed   = errors.New("message authentication failed")
+)
+
+// EncryptionUtils provides ECIES encryption functionality. The zero value
+// does not pad, see padding.go.
+type EncryptionUtils struct {
+	padding paddingPolicy
+}
 
 // GenerateKeyPair generates a new ECDSA key pair and returns the public key as hex string
 func (e *EncryptionUtils) GenerateKeyPair() (publicKeyHex string, privateKey *ecdsa.PrivateKey, err error) {
@@ -67,8 +86,13 @@ func (e *EncryptionUtils) ParsePublicKeyFromHex(publicKeyHex string) (*ecdsa.Pub
 		return nil, fmt.Errorf("failed to decode hex public key: %w", err)
 	}
 
-	// Parse ECDSA public key
-	publicKey, err := crypto.UnmarshalPubkey(publicKeyBytes)
+	// Parse ECDSA public key, either uncompressed or compressed as grantee lists store them
+	var publicKey *ecdsa.PublicKey
+	if len(publicKeyBytes) == compressedKeySize {
+		publicKey, err = crypto.DecompressPubkey(publicKeyBytes)
+	} else {
+		publicKey, err = crypto.UnmarshalPubkey(publicKeyBytes)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ECDSA public key: %w", err)
 	}
@@ -76,88 +100,52 @@ func (e *EncryptionUtils) ParsePublicKeyFromHex(publicKeyHex string) (*ecdsa.Pub
 	return publicKey, nil
 }
 
-// DeriveSharedSecret performs ECDH key exchange to derive a shared secret
+// DeriveSharedSecret performs ECDH key exchange and derives a 32-byte AES key from it.
+// The result is the same for both sides of the exchange.
 func (e *EncryptionUtils) DeriveSharedSecret(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	if publicKey == nil || !crypto.S256().IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, fmt.Errorf("public key is not on the secp256k1 curve")
+	}
+
 	// Perform ECDH key exchange
 	sharedX, _ := publicKey.Curve.ScalarMult(publicKey.X, publicKey.Y, privateKey.D.Bytes())
-	if sharedX == nil {
+	if sharedX == nil || sharedX.Sign() == 0 {
 		return nil, fmt.Errorf("failed to derive shared secret")
 	}
+	shared := make([]byte, 32)
+	sharedX.FillBytes(shared)
 
-	// Hash the shared secret to get a 32-byte key for AES-256
-	sharedSecret := crypto.Keccak256(sharedX.Bytes())
+	// Bind the key to both public keys, ordered so both sides agree
+	first := crypto.CompressPubkey(&privateKey.PublicKey)
+	second := crypto.CompressPubkey(publicKey)
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+	salt := append(append([]byte{}, first...), second...)
+
+	sharedSecret := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte(eciesKDFInfo)), sharedSecret); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
 
 	return sharedSecret, nil
 }
 
-// EncryptWithSharedSecret encrypts data using AES-CTR with a deterministic IV
+// EncryptWithSharedSecret encrypts data using AES-256-GCM with a random nonce.
+// The nonce is prepended to the returned ciphertext.
 func (e *EncryptionUtils) EncryptWithSharedSecret(data []byte, sharedSecret []byte) ([]byte, error) {
-	// Create AES cipher
-	block, err := aes.NewCipher(sharedSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
-	}
-
-	// Create a deterministic IV from the first 16 bytes of the shared secret
-	// This ensures same input produces same output
-	iv := make([]byte, aes.BlockSize)
-	copy(iv, sharedSecret[:aes.BlockSize])
-
-	// Create CTR mode stream
-	stream := cipher.NewCTR(block, iv)
-
-	// Encrypt data (same size as input)
-	ciphertext := make([]byte, len(data))
-	stream.XORKeyStream(ciphertext, data)
-
-	return ciphertext, nil
+	return sealAESGCM(sharedSecret, data, nil)
 }
 
-// DecryptWithSharedSecret decrypts data using AES-CTR with the same deterministic IV
+// DecryptWithSharedSecret decrypts data produced by EncryptWithSharedSecret
 func (e *EncryptionUtils) DecryptWithSharedSecret(encryptedData []byte, sharedSecret []byte) ([]byte, error) {
-	// Create AES cipher
-	block, err := aes.NewCipher(sharedSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
-	}
-
-	// Use the same deterministic IV as encryption
-	iv := make([]byte, aes.BlockSize)
-	copy(iv, sharedSecret[:aes.BlockSize])
-
-	// Create CTR mode stream
-	stream := cipher.NewCTR(block, iv)
-
-	// Decrypt data (CTR mode decryption is same as encryption)
-	plaintext := make([]byte, len(encryptedData))
-	stream.XORKeyStream(plaintext, encryptedData)
-
-	return plaintext, nil
+	return openAESGCM(sharedSecret, encryptedData, nil)
 }
 
-// EncryptData encrypts data using deterministic ECDH + AES-CTR (same size output)
+// EncryptData encrypts data to the recipient's public key using ECIES and
+// wraps the result in an envelope (see envelope.go)
 func (e *EncryptionUtils) EncryptData(data []byte, recipientPublicKey *ecdsa.PublicKey) ([]byte, error) {
-	// Use a deterministic approach: derive ephemeral key from recipient's public key
-	// This is less secure but produces same-size output
-	deterministicSeed := crypto.Keccak256(crypto.FromECDSAPub(recipientPublicKey), data)
-	ephemeralPrivateKey, err := crypto.ToECDSA(deterministicSeed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create deterministic ephemeral key: %w", err)
-	}
-
-	// Derive shared secret using ECDH
-	sharedSecret, err := e.DeriveSharedSecret(ephemeralPrivateKey, recipientPublicKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
-	}
-
-	// Encrypt data with shared secret (same size as input)
-	encryptedData, err := e.EncryptWithSharedSecret(data, sharedSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt with shared secret: %w", err)
-	}
-
-	return encryptedData, nil
+	return e.sealEnvelope(rand.Reader, data, recipientPublicKey)
 }
 
 // EncryptString encrypts a string and returns hex-encoded result
@@ -169,36 +157,9 @@ func (e *EncryptionUtils) EncryptString(data string, publicKey *ecdsa.PublicKey)
 	return hex.EncodeToString(encrypted), nil
 }
 
-// DecryptData decrypts data using deterministic ECDH + AES-CTR
+// DecryptData decrypts an envelope with the recipient's private key
 func (e *EncryptionUtils) DecryptData(encryptedData []byte, recipientPrivateKey *ecdsa.PrivateKey) ([]byte, error) {
-	// We need the original data length to recreate the deterministic seed
-	// For now, we'll use a simpler approach: derive the ephemeral key from the recipient's public key
-	recipientPublicKey := &recipientPrivateKey.PublicKey
-
-	// Use the same deterministic approach as encryption
-	// Note: This requires knowing the original data, so we'll use the encrypted data as approximation
-	deterministicSeed := crypto.Keccak256(crypto.FromECDSAPub(recipientPublicKey), encryptedData)
-	ephemeralPrivateKey, err := crypto.ToECDSA(deterministicSeed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to recreate deterministic ephemeral key: %w", err)
-	}
-
-	// Get the ephemeral public key
-	ephemeralPublicKey := &ephemeralPrivateKey.PublicKey
-
-	// Derive shared secret using ECDH
-	sharedSecret, err := e.DeriveSharedSecret(recipientPrivateKey, ephemeralPublicKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
-	}
-
-	// Decrypt data with shared secret
-	plaintext, err := e.DecryptWithSharedSecret(encryptedData, sharedSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt with shared secret: %w", err)
-	}
-
-	return plaintext, nil
+	return e.openEnvelope(encryptedData, recipientPrivateKey)
 }
 
 // DecryptString decrypts hex-encoded encrypted data
@@ -226,3 +187,52 @@ func (e *EncryptionUtils) GetDefaultPublicKey() string {
 	}
 	return publicKeyHex
 }
+
+// sealAESGCM encrypts data with AES-256-GCM and returns nonce || ciphertext || tag
+func sealAESGCM(key, data, additionalData []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, data, additionalData), nil
+}
+
+// openAESGCM decrypts data produced by sealAESGCM
+func openAESGCM(key, data, additionalData []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, errCiphertextTooShort
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+
+	return plaintext, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return aead, nil
+}
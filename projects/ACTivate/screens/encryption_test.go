@@ -0,0 +1,166 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, recipient, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	large := make([]byte, 64*1024)
+	if _, err := rand.Read(large); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"ref", bytes.Repeat([]byte{0xab}, 32)},
+		{"text", []byte("hello from ACTivate")},
+		{"large", large},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			encrypted, err := e.EncryptData(tc.data, &recipient.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(encrypted) != len(tc.data)+envelopeECIESOverhead {
+				t.Fatalf("got ciphertext length %d, want %d", len(encrypted), len(tc.data)+envelopeECIESOverhead)
+			}
+
+			decrypted, err := e.DecryptData(encrypted, recipient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, tc.data) {
+				t.Fatalf("got %x, want %x", decrypted, tc.data)
+			}
+		})
+	}
+}
+
+func TestEncryptDataIsRandomised(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, recipient, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("same input")
+	first, err := e.EncryptData(data, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := e.EncryptData(data, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Fatal("encrypting the same input twice produced the same ciphertext")
+	}
+}
+
+func TestDecryptDataWrongKey(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, recipient, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := e.EncryptData([]byte("secret"), &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.DecryptData(encrypted, other); err == nil {
+		t.Fatal("expected decryption with the wrong key to fail")
+	}
+}
+
+func TestDecryptDataTampered(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, recipient, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := e.EncryptData([]byte("secret"), &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flip a bit in the version, fingerprint, nonce, ephemeral key and tag
+	for _, idx := range []int{1, 5, 15, envelopeHeaderSize + 5, len(encrypted) - 1} {
+		tampered := bytes.Clone(encrypted)
+		tampered[idx] ^= 0x01
+		if _, err := e.DecryptData(tampered, recipient); err == nil {
+			t.Fatalf("expected decryption to fail with byte %d modified", idx)
+		}
+	}
+
+	if _, err := e.DecryptData(encrypted[:envelopeHeaderSize+compressedKeySize], recipient); err != errCiphertextTooShort {
+		t.Fatalf("got error %v, want %v", err, errCiphertextTooShort)
+	}
+}
+
+func TestEncryptDecryptString(t *testing.T) {
+	e := &EncryptionUtils{}
+	publicKeyHex, recipient, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := e.ParsePublicKeyFromHex("0x" + publicKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := e.EncryptString("example-topic", publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := e.DecryptString(encrypted, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != "example-topic" {
+		t.Fatalf("got %q, want %q", decrypted, "example-topic")
+	}
+}
+
+func TestDeriveSharedSecretSymmetric(t *testing.T) {
+	e := &EncryptionUtils{}
+	a, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ab, err := e.DeriveSharedSecret(a, &b.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba, err := e.DeriveSharedSecret(b, &a.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ab, ba) || len(ab) != 32 {
+		t.Fatalf("shared secrets differ: %x != %x", ab, ba)
+	}
+}
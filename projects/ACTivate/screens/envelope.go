@@ -0,0 +1,219 @@
+package screens
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+Ciphertext Envelope Format
+
+Everything ACTivate encrypts is wrapped in a small self-describing envelope so
+that readers can tell which algorithm, key and format version produced a blob.
+
+Layout (version 1):
+
+	offset  size  field
+	0       1     magic (0xAC)
+	1       1     format version
+	2       1     algorithm ID
+	3       8     recipient key fingerprint
+	11      12    nonce
+	23      ...   algorithm specific body
+
+For AlgECIESSecp256k1AESGCM the body is the compressed ephemeral public key
+(33 bytes) followed by the AES-256-GCM ciphertext and tag. All bytes before the
+ciphertext are authenticated as additional data, so the header cannot be
+altered without detection.
+
+Version 2 has the same layout; its plaintext is padded (see padding.go) so the
+ciphertext length only reveals the padding bucket.
+
+Blobs that do not start with the magic byte are rejected. The bare ECIES
+format that preceded envelopes never shipped in a release.
+*/
+
+const (
+	envelopeMagic      byte = 0xAC
+	envelopeVersion1   byte = 0x01
+	envelopeVersion2   byte = 0x02
+	envelopeHeaderSize      = 1 + 1 + 1 + fingerprintSize + envelopeNonceSize
+	envelopeNonceSize       = 12
+	fingerprintSize         = 8
+	compressedKeySize       = 33
+
+	// envelopeECIESOverhead is the size added to the plaintext by EncryptData
+	envelopeECIESOverhead = envelopeHeaderSize + compressedKeySize + eciesTagSize
+
+	// AlgECIESSecp256k1AESGCM is ECIES over secp256k1 with HKDF-SHA256 and AES-256-GCM
+	AlgECIESSecp256k1AESGCM byte = 0x01
+)
+
+var (
+	errEnvelopeVersion   = errors.New("unsupported envelope version")
+	errEnvelopeAlgorithm = errors.New("unsupported envelope algorithm")
+	errWrongRecipient    = errors.New("envelope is addressed to a different key")
+)
+
+// envelopeHeader is the fixed-size prefix of every envelope
+type envelopeHeader struct {
+	version     byte
+	algorithm   byte
+	fingerprint [fingerprintSize]byte
+	nonce       [envelopeNonceSize]byte
+}
+
+func (h *envelopeHeader) marshal() []byte {
+	b := make([]byte, 0, envelopeHeaderSize)
+	b = append(b, envelopeMagic, h.version, h.algorithm)
+	b = append(b, h.fingerprint[:]...)
+	b = append(b, h.nonce[:]...)
+	return b
+}
+
+// isEnvelope reports whether data starts with an envelope header
+func isEnvelope(data []byte) bool {
+	return len(data) >= envelopeHeaderSize && data[0] == envelopeMagic
+}
+
+// parseEnvelopeHeader splits an envelope into its header and body
+func parseEnvelopeHeader(data []byte) (*envelopeHeader, []byte, error) {
+	if len(data) < envelopeHeaderSize {
+		return nil, nil, errCiphertextTooShort
+	}
+	if data[0] != envelopeMagic {
+		return nil, nil, fmt.Errorf("invalid envelope magic byte 0x%02x", data[0])
+	}
+
+	h := &envelopeHeader{
+		version:   data[1],
+		algorithm: data[2],
+	}
+	if h.version != envelopeVersion1 && h.version != envelopeVersion2 {
+		return nil, nil, fmt.Errorf("%w: %d", errEnvelopeVersion, h.version)
+	}
+	copy(h.fingerprint[:], data[3:3+fingerprintSize])
+	copy(h.nonce[:], data[3+fingerprintSize:envelopeHeaderSize])
+
+	return h, data[envelopeHeaderSize:], nil
+}
+
+// envelopeVersion returns the version to write, padded plaintexts use version 2
+func (e *EncryptionUtils) envelopeVersion() byte {
+	if e.padding != paddingNone {
+		return envelopeVersion2
+	}
+	return envelopeVersion1
+}
+
+// sealPlaintext pads data if the envelope version calls for it
+func (e *EncryptionUtils) sealPlaintext(version byte, data []byte) []byte {
+	if version == envelopeVersion2 {
+		return e.padding.pad(data)
+	}
+	return data
+}
+
+// openPlaintext strips the padding of a version 2 plaintext
+func openPlaintext(version byte, plaintext []byte) ([]byte, error) {
+	if version == envelopeVersion2 {
+		return unpad(plaintext)
+	}
+	return plaintext, nil
+}
+
+// keyFingerprint identifies a public key inside envelopes without revealing it
+func keyFingerprint(publicKey *ecdsa.PublicKey) [fingerprintSize]byte {
+	var fp [fingerprintSize]byte
+	copy(fp[:], crypto.Keccak256(crypto.CompressPubkey(publicKey)))
+	return fp
+}
+
+// sealEnvelope encrypts data to the recipient's public key, drawing the
+// ephemeral key and the nonce from rnd
+func (e *EncryptionUtils) sealEnvelope(rnd io.Reader, data []byte, recipientPublicKey *ecdsa.PublicKey) ([]byte, error) {
+	ephemeralPrivateKey, err := readPrivateKey(rnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	sharedSecret, err := e.DeriveSharedSecret(ephemeralPrivateKey, recipientPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+
+	h := &envelopeHeader{
+		version:     e.envelopeVersion(),
+		algorithm:   AlgECIESSecp256k1AESGCM,
+		fingerprint: keyFingerprint(recipientPublicKey),
+	}
+	if _, err := io.ReadFull(rnd, h.nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := newAESGCM(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := append(h.marshal(), crypto.CompressPubkey(&ephemeralPrivateKey.PublicKey)...)
+	return aead.Seal(prefix, h.nonce[:], e.sealPlaintext(h.version, data), prefix), nil
+}
+
+// openEnvelope decrypts an envelope with the recipient's private key
+func (e *EncryptionUtils) openEnvelope(data []byte, recipientPrivateKey *ecdsa.PrivateKey) ([]byte, error) {
+	h, body, err := parseEnvelopeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.fingerprint != keyFingerprint(&recipientPrivateKey.PublicKey) {
+		return nil, errWrongRecipient
+	}
+
+	switch h.algorithm {
+	case AlgECIESSecp256k1AESGCM:
+		if len(body) < compressedKeySize+eciesTagSize {
+			return nil, errCiphertextTooShort
+		}
+		ephemeralPublicKey, err := crypto.DecompressPubkey(body[:compressedKeySize])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ephemeral public key: %w", err)
+		}
+
+		sharedSecret, err := e.DeriveSharedSecret(recipientPrivateKey, ephemeralPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+		}
+
+		aead, err := newAESGCM(sharedSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixLen := envelopeHeaderSize + compressedKeySize
+		plaintext, err := aead.Open(nil, h.nonce[:], data[prefixLen:], data[:prefixLen])
+		if err != nil {
+			return nil, errDecryptionFailed
+		}
+		return openPlaintext(h.version, plaintext)
+	default:
+		return nil, fmt.Errorf("%w: 0x%02x", errEnvelopeAlgorithm, h.algorithm)
+	}
+}
+
+// readPrivateKey derives a valid secp256k1 private key from rnd
+func readPrivateKey(rnd io.Reader) (*ecdsa.PrivateKey, error) {
+	seed := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(rnd, seed); err != nil {
+			return nil, err
+		}
+		if key, err := crypto.ToECDSA(seed); err == nil {
+			return key, nil
+		}
+	}
+}
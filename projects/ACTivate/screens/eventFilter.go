@@ -0,0 +1,64 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// eventWatchListPrefKey holds extra recipient addresses to follow events for,
+// separated by commas or whitespace. Addresses added later are followed from
+// the current checkpoint on, their earlier events are not backfilled.
+const eventWatchListPrefKey = "eventWatchList"
+
+// parseWatchList parses a watch list, skipping duplicates. Entries that are not
+// addresses are returned as an error next to the valid ones.
+func parseWatchList(s string) ([]common.Address, error) {
+	var addrs []common.Address
+	var invalid []string
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if !common.IsHexAddress(field) {
+			invalid = append(invalid, field)
+			continue
+		}
+		addr := common.HexToAddress(field)
+		if !slices.Contains(addrs, addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(invalid) > 0 {
+		return addrs, fmt.Errorf("invalid watch list addresses: %s", strings.Join(invalid, ", "))
+	}
+	return addrs, nil
+}
+
+// recipientTopics returns the topic values matching the indexed recipients
+func recipientTopics(recipients []common.Address) []common.Hash {
+	topics := make([]common.Hash, 0, len(recipients))
+	for _, addr := range recipients {
+		topic := common.BytesToHash(addr.Bytes())
+		if !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// eventRecipients returns the addresses to receive events for: the node's own
+// address followed by the watch list
+func (i *index) eventRecipients() []common.Address {
+	recipients := []common.Address{i.bl.OverlayEthAddress()}
+	watched, err := parseWatchList(i.getPreferenceString(eventWatchListPrefKey))
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Ignoring part of the event watch list: %v", err))
+	}
+	for _, addr := range watched {
+		if !slices.Contains(recipients, addr) {
+			recipients = append(recipients, addr)
+		}
+	}
+	return recipients
+}
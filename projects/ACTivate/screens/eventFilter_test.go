@@ -0,0 +1,53 @@
+package screens
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseWatchList(t *testing.T) {
+	a := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	got, err := parseWatchList(" 0x1111111111111111111111111111111111111111,\n0x2222222222222222222222222222222222222222 0x1111111111111111111111111111111111111111 ")
+	if err != nil || !slices.Equal(got, []common.Address{a, b}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if got, err := parseWatchList(""); err != nil || len(got) != 0 {
+		t.Fatalf("empty watch list gave %v, %v", got, err)
+	}
+
+	got, err = parseWatchList("0x2222222222222222222222222222222222222222, bob")
+	if err == nil || !slices.Equal(got, []common.Address{b}) {
+		t.Fatalf("invalid entry: got %v, %v", got, err)
+	}
+}
+
+func TestDataSentToTargetQuery(t *testing.T) {
+	contractABI, err := ParseContractABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewDataContract(common.Address{}, common.HexToAddress("0xdead"), contractABI, nil, false, 0, 0, nil).(*datacontract)
+	self := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	query := c.dataSentToTargetQuery([]common.Address{self, watched, self})
+	// both event versions are delivered
+	signatures := []common.Hash{contractABI.Events["DataSentToTarget"].ID, contractABI.Events["DataSentToTargetV2"].ID}
+	if len(query.Topics) != 3 || !slices.Equal(query.Topics[0], signatures) || query.Topics[1] != nil {
+		t.Fatalf("unexpected topics %v", query.Topics)
+	}
+
+	// the recipients must be encoded the way the indexed "to" is
+	want, err := abi.MakeTopics([]interface{}{self, watched})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(query.Topics[2], want[0]) {
+		t.Fatalf("recipient topics %v, want %v", query.Topics[2], want[0])
+	}
+}
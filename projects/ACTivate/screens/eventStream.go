@@ -0,0 +1,412 @@
+package screens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	eventPageSize          = 500
+	eventCheckpointPrefKey = "eventCheckpoint"
+
+	// defaultEventPollInterval is how often endpoints without subscriptions
+	// are asked for new logs
+	defaultEventPollInterval = 15 * time.Second
+	eventPollIntervalPrefKey = "eventPollInterval"
+
+	// defaultEventConfirmations is how deep an event must be before it is
+	// acted on, deep enough to ride out the usual short reorgs
+	defaultEventConfirmations = 5
+	eventConfirmationsPrefKey = "eventConfirmations"
+)
+
+// logClient is the part of the chain client the event stream needs
+type logClient interface {
+	ethereum.LogFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// logCursor is the position in the chain to resume reading logs from. Logs
+// before it have been handled.
+type logCursor struct {
+	Block uint64
+	Index uint
+}
+
+// includes reports whether l is at or past the cursor
+func (c logCursor) includes(l types.Log) bool {
+	return l.BlockNumber > c.Block || (l.BlockNumber == c.Block && l.Index >= c.Index)
+}
+
+// next returns the cursor just past l
+func (c logCursor) next(l types.Log) logCursor {
+	return logCursor{Block: l.BlockNumber, Index: l.Index + 1}
+}
+
+func (c logCursor) String() string {
+	return fmt.Sprintf("%d:%d", c.Block, c.Index)
+}
+
+func parseLogCursor(s string) (logCursor, error) {
+	var c logCursor
+	if _, err := fmt.Sscanf(s, "%d:%d", &c.Block, &c.Index); err != nil {
+		return logCursor{}, fmt.Errorf("invalid log cursor %q: %w", s, err)
+	}
+	return c, nil
+}
+
+// logCheckpoint is where a log stream resumes after a restart: the cursor and
+// the delivered logs that could still be reorged out, so a reorg of logs
+// handled before the restart is undone too
+type logCheckpoint struct {
+	Cursor logCursor   `json:"cursor"`
+	Recent []types.Log `json:"recent,omitempty"`
+}
+
+// marshal encodes the checkpoint for preferences
+func (c logCheckpoint) marshal() (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// parseLogCheckpoint decodes a stored checkpoint, or the bare cursor earlier
+// builds stored
+func parseLogCheckpoint(s string) (logCheckpoint, error) {
+	if !strings.HasPrefix(s, "{") {
+		cursor, err := parseLogCursor(s)
+		return logCheckpoint{Cursor: cursor}, err
+	}
+	var c logCheckpoint
+	if err := json.Unmarshal([]byte(s), &c); err != nil {
+		return logCheckpoint{}, fmt.Errorf("invalid log checkpoint: %w", err)
+	}
+	return c, nil
+}
+
+// streamedLog is a log from the event stream, or only a new cursor when a
+// range of blocks held no logs. Cursor is where to resume once it is handled,
+// Recent the delivered logs the stream still checks for reorgs by then.
+// A log with Removed set was delivered before and has been dropped by a chain
+// reorganisation, whatever it caused has to be undone.
+type streamedLog struct {
+	Log    *types.Log
+	Cursor logCursor
+	Recent []types.Log
+}
+
+// checkpoint is what to persist once the item is handled
+func (l streamedLog) checkpoint() logCheckpoint {
+	return logCheckpoint{Cursor: l.Cursor, Recent: l.Recent}
+}
+
+// logStreamConfig tunes how a log stream reads the chain
+type logStreamConfig struct {
+	// PageSize is the number of blocks asked for in one FilterLogs call
+	PageSize uint64
+	// PollInterval is how often endpoints without subscriptions are polled
+	PollInterval time.Duration
+	// Confirmations is how many blocks must be built on top of a log's block
+	// before the log is delivered
+	Confirmations uint64
+}
+
+// subscribeLogs delivers the logs matching query from the cursor on: first the
+// history in pages of FilterLogs, then the live subscription. The live
+// subscription is opened before the history is read, so no log falls between
+// the two, and logs the history already delivered are skipped.
+//
+// Endpoints without subscriptions, like plain HTTP, are polled for new blocks
+// every PollInterval instead. The stream also polls when logs have to wait for
+// confirmations, using live logs only to poll early. The sink sees the same
+// items either way.
+//
+// Delivered logs are remembered for recentLogWindow blocks, and carried by
+// every item so they survive a restart through the checkpoint. If one of them
+// leaves the canonical chain it is delivered again with Removed set, and the
+// stream rewinds to its position to pick up what replaced it.
+func subscribeLogs(ctx context.Context, client logClient, query ethereum.FilterQuery, from logCheckpoint, config logStreamConfig, sink chan<- streamedLog) (ethereum.Subscription, error) {
+	liveQuery := query
+	liveQuery.FromBlock, liveQuery.ToBlock = nil, nil
+	live := make(chan types.Log, 128)
+	liveSub, err := client.SubscribeFilterLogs(ctx, liveQuery, live)
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		log.Printf("RPC endpoint does not support subscriptions, polling for logs every %s", config.PollInterval)
+		liveSub = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		s := &logStream{
+			client:        client,
+			query:         query,
+			pageSize:      config.PageSize,
+			confirmations: config.Confirmations,
+			cursor:        from.Cursor,
+			recent:        slices.Clone(from.Recent),
+			sink:          sink,
+			quit:          quit,
+		}
+		if liveSub == nil {
+			return s.poll(ctx, config.PollInterval, nil, nil)
+		}
+		defer liveSub.Unsubscribe()
+		if s.confirmations > 0 {
+			return s.poll(ctx, config.PollInterval, live, liveSub.Err())
+		}
+
+		if err := s.catchUp(ctx); err != nil || s.stopped {
+			return err
+		}
+		for {
+			select {
+			case l := <-live:
+				if !s.deliverLog(ctx, l) {
+					return nil
+				}
+			case err := <-liveSub.Err():
+				return err
+			case <-quit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}), nil
+}
+
+// recentLogWindow is how many blocks back delivered logs are checked for reorgs
+const recentLogWindow = 128
+
+// logStream feeds one subscription's sink and tracks its cursor
+type logStream struct {
+	client        logClient
+	query         ethereum.FilterQuery
+	pageSize      uint64
+	confirmations uint64
+	cursor        logCursor
+	sink          chan<- streamedLog
+	quit          <-chan struct{}
+	stopped       bool
+
+	// recent are the delivered logs that could still be reorged out
+	recent []types.Log
+}
+
+// deliver sends item, whose Recent is what the recent logs become once it is
+// taken
+func (s *logStream) deliver(ctx context.Context, item streamedLog) bool {
+	select {
+	case s.sink <- item:
+		s.cursor = item.Cursor
+		s.recent = item.Recent
+		return true
+	case <-s.quit:
+	case <-ctx.Done():
+	}
+	s.stopped = true
+	return false
+}
+
+func (s *logStream) deliverLog(ctx context.Context, l types.Log) bool {
+	if l.Removed {
+		return s.deliverRemoved(ctx, l)
+	}
+	// after a rewind, logs that stayed in the chain are not delivered twice
+	if !s.cursor.includes(l) || slices.ContainsFunc(s.recent, func(r types.Log) bool { return sameLog(r, l) }) {
+		return true
+	}
+	cursor := s.cursor.next(l)
+	return s.deliver(ctx, streamedLog{Log: &l, Cursor: cursor, Recent: recentAt(append(slices.Clone(s.recent), l), cursor)})
+}
+
+// deliverRemoved reports a delivered log that left the canonical chain and
+// rewinds to it, the logs before it stay handled. Removed logs that were never
+// delivered are ignored.
+func (s *logStream) deliverRemoved(ctx context.Context, l types.Log) bool {
+	n := slices.IndexFunc(s.recent, func(r types.Log) bool { return sameLog(r, l) })
+	if n < 0 {
+		return true
+	}
+	recent := slices.Delete(slices.Clone(s.recent), n, n+1)
+	l.Removed = true
+	cursor := s.cursor
+	if !cursor.includes(l) {
+		cursor = logCursor{Block: l.BlockNumber, Index: l.Index}
+	}
+	return s.deliver(ctx, streamedLog{Log: &l, Cursor: cursor, Recent: recent})
+}
+
+// sameLog reports whether a and b are the same log in the same block
+func sameLog(a, b types.Log) bool {
+	return logIDOf(a) == logIDOf(b)
+}
+
+// checkRecent delivers the recent logs that are no longer in the chain, latest
+// first, so they can be undone in reverse
+func (s *logStream) checkRecent(ctx context.Context) error {
+	if len(s.recent) == 0 {
+		return nil
+	}
+	query := s.query
+	query.FromBlock = new(big.Int).SetUint64(s.recent[0].BlockNumber)
+	query.ToBlock = new(big.Int).SetUint64(s.recent[len(s.recent)-1].BlockNumber)
+	canonical, err := s.client.FilterLogs(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to check recent logs: %w", err)
+	}
+
+	for n := len(s.recent) - 1; n >= 0; n-- {
+		l := s.recent[n]
+		if slices.ContainsFunc(canonical, func(c types.Log) bool { return sameLog(c, l) }) {
+			continue
+		}
+		if !s.deliverRemoved(ctx, l) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// recentAt drops the recent logs too deep to be reorged at cursor
+func recentAt(recent []types.Log, cursor logCursor) []types.Log {
+	return slices.DeleteFunc(recent, func(l types.Log) bool {
+		return l.BlockNumber+recentLogWindow < cursor.Block
+	})
+}
+
+// catchUp delivers the logs from the cursor to the last confirmed block
+func (s *logStream) catchUp(ctx context.Context) error {
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get head block: %w", err)
+	}
+	if head < s.confirmations {
+		return nil
+	}
+	head -= s.confirmations
+
+	for s.cursor.Block <= head {
+		end := min(s.cursor.Block+s.pageSize-1, head)
+		page := s.query
+		page.FromBlock = new(big.Int).SetUint64(s.cursor.Block)
+		page.ToBlock = new(big.Int).SetUint64(end)
+
+		logs, err := s.client.FilterLogs(ctx, page)
+		if err != nil {
+			// providers cap the size of a response, retry with a smaller range
+			if s.pageSize > 1 {
+				s.pageSize /= 2
+				continue
+			}
+			return fmt.Errorf("failed to filter logs from block %d: %w", s.cursor.Block, err)
+		}
+		for _, l := range logs {
+			if !s.deliverLog(ctx, l) {
+				return nil
+			}
+		}
+		cursor := logCursor{Block: end + 1}
+		if !s.deliver(ctx, streamedLog{Cursor: cursor, Recent: recentAt(slices.Clone(s.recent), cursor)}) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// poll checks the recent logs and catches up with the chain every interval,
+// or as soon as a live log arrives
+func (s *logStream) poll(ctx context.Context, interval time.Duration, live <-chan types.Log, liveErr <-chan error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.checkRecent(ctx); err != nil || s.stopped {
+			return err
+		}
+		if err := s.catchUp(ctx); err != nil || s.stopped {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-live:
+		case err := <-liveErr:
+			return err
+		case <-s.quit:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// eventCheckpointKey keys the checkpoint by node identity and chain, so a
+// restored identity or a network switch does not resume from the wrong place
+func (i *index) eventCheckpointKey(ctx context.Context) (string, error) {
+	chainID, err := i.contractClient().ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	return fmt.Sprintf("%s_%s_%s", eventCheckpointPrefKey, chainID, i.bl.OverlayEthAddress().Hex()), nil
+}
+
+// loadEventCheckpoint returns where to resume reading events, the event
+// history starts at the contract deployment when there is no checkpoint yet
+func (i *index) loadEventCheckpoint(ctx context.Context, key string) (logCheckpoint, error) {
+	if stored := i.getPreferenceString(key); stored != "" {
+		checkpoint, err := parseLogCheckpoint(stored)
+		if err == nil {
+			return checkpoint, nil
+		}
+		i.logger.Log(fmt.Sprintf("Ignoring event checkpoint: %v", err))
+	}
+	block, err := i.network().deployBlock(ctx, i.contractClient())
+	if err != nil {
+		return logCheckpoint{}, err
+	}
+	return logCheckpoint{Cursor: logCursor{Block: block}}, nil
+}
+
+// eventPollInterval returns the configured polling interval for endpoints
+// without subscriptions
+func (i *index) eventPollInterval() time.Duration {
+	stored := i.getPreferenceString(eventPollIntervalPrefKey)
+	if stored == "" {
+		return defaultEventPollInterval
+	}
+	seconds, err := strconv.Atoi(stored)
+	if err != nil || seconds < 1 {
+		i.logger.Log(fmt.Sprintf("Invalid event poll interval %q, using %s", stored, defaultEventPollInterval))
+		return defaultEventPollInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// eventConfirmations returns the configured confirmation depth for events
+func (i *index) eventConfirmations() uint64 {
+	stored := i.getPreferenceString(eventConfirmationsPrefKey)
+	if stored == "" {
+		return defaultEventConfirmations
+	}
+	confirmations, err := strconv.ParseUint(stored, 10, 64)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Invalid event confirmation depth %q, using %d", stored, defaultEventConfirmations))
+		return defaultEventConfirmations
+	}
+	return confirmations
+}
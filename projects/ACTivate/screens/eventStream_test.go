@@ -0,0 +1,227 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeLogClient serves a history and forwards live logs sent on live. Without
+// live it behaves like an HTTP endpoint.
+type fakeLogClient struct {
+	mu       sync.Mutex
+	head     uint64
+	history  []types.Log
+	live     chan types.Log
+	maxRange uint64
+	queries  int
+}
+
+// mine appends a block holding logs
+func (f *fakeLogClient) mine(logs ...types.Log) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.head++
+	for _, l := range logs {
+		l.BlockNumber = f.head
+		f.history = append(f.history, l)
+	}
+}
+
+func (f *fakeLogClient) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.head, nil
+}
+
+func (f *fakeLogClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries++
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	if f.maxRange > 0 && to-from+1 > f.maxRange {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	var logs []types.Log
+	for _, l := range f.history {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (f *fakeLogClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if f.live == nil {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case l := <-f.live:
+				select {
+				case ch <- l:
+				case <-quit:
+					return nil
+				}
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func testLog(block uint64, index uint) types.Log {
+	return types.Log{BlockNumber: block, Index: index}
+}
+
+// collectLogs reads n logs from the stream and returns them with the last cursor
+func collectLogs(t *testing.T, sink <-chan streamedLog, n int) ([]types.Log, logCursor) {
+	t.Helper()
+	var logs []types.Log
+	var cursor logCursor
+	timeout := time.After(5 * time.Second)
+	for len(logs) < n {
+		select {
+		case item := <-sink:
+			if item.Log != nil {
+				logs = append(logs, *item.Log)
+			}
+			cursor = item.Cursor
+		case <-timeout:
+			t.Fatalf("got %d of %d logs", len(logs), n)
+		}
+	}
+	return logs, cursor
+}
+
+func TestSubscribeLogsBackfillThenLive(t *testing.T) {
+	client := &fakeLogClient{
+		head:    1250,
+		history: []types.Log{testLog(90, 0), testLog(100, 0), testLog(100, 1), testLog(600, 3), testLog(1250, 0)},
+		live:    make(chan types.Log),
+	}
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), client, ethereum.FilterQuery{}, logCheckpoint{Cursor: logCursor{Block: 100}}, logStreamConfig{PageSize: 500, PollInterval: time.Second}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	logs, cursor := collectLogs(t, sink, 4)
+	want := []logCursor{{100, 0}, {100, 1}, {600, 3}, {1250, 0}}
+	for n, l := range logs {
+		if (logCursor{l.BlockNumber, l.Index}) != want[n] {
+			t.Fatalf("log %d: got %d:%d, want %s", n, l.BlockNumber, l.Index, want[n])
+		}
+	}
+	if cursor != (logCursor{1250, 1}) {
+		t.Fatalf("cursor after backfill: %s", cursor)
+	}
+	if client.queries != 3 {
+		t.Fatalf("expected 3 pages for blocks 100..1250, got %d", client.queries)
+	}
+
+	// the live subscription repeats what the backfill already delivered
+	go func() {
+		client.live <- testLog(1250, 0)
+		client.live <- types.Log{BlockNumber: 1251, Removed: true}
+		client.live <- testLog(1251, 2)
+	}()
+	logs, cursor = collectLogs(t, sink, 1)
+	if logs[0].BlockNumber != 1251 || logs[0].Index != 2 || cursor != (logCursor{1251, 3}) {
+		t.Fatalf("unexpected live log %d:%d, cursor %s", logs[0].BlockNumber, logs[0].Index, cursor)
+	}
+}
+
+func TestSubscribeLogsResumeMidBlock(t *testing.T) {
+	client := &fakeLogClient{
+		head:    100,
+		history: []types.Log{testLog(100, 0), testLog(100, 1), testLog(100, 2)},
+		live:    make(chan types.Log),
+	}
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), client, ethereum.FilterQuery{}, logCheckpoint{Cursor: logCursor{Block: 100, Index: 2}}, logStreamConfig{PageSize: 500, PollInterval: time.Second}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	logs, _ := collectLogs(t, sink, 1)
+	if logs[0].Index != 2 {
+		t.Fatalf("resumed at index %d, want 2", logs[0].Index)
+	}
+}
+
+func TestSubscribeLogsShrinksPages(t *testing.T) {
+	client := &fakeLogClient{
+		head:     1000,
+		history:  []types.Log{testLog(10, 0), testLog(999, 0)},
+		live:     make(chan types.Log),
+		maxRange: 100,
+	}
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), client, ethereum.FilterQuery{}, logCheckpoint{}, logStreamConfig{PageSize: 500, PollInterval: time.Second}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	logs, _ := collectLogs(t, sink, 2)
+	if logs[0].BlockNumber != 10 || logs[1].BlockNumber != 999 {
+		t.Fatalf("unexpected logs %+v", logs)
+	}
+}
+
+func TestSubscribeLogsPolling(t *testing.T) {
+	client := &fakeLogClient{
+		head:    100,
+		history: []types.Log{testLog(100, 0)},
+	}
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), client, ethereum.FilterQuery{}, logCheckpoint{Cursor: logCursor{Block: 50}}, logStreamConfig{PageSize: 500, PollInterval: 10 * time.Millisecond}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	logs, _ := collectLogs(t, sink, 1)
+	if logs[0].BlockNumber != 100 {
+		t.Fatalf("backfill delivered block %d", logs[0].BlockNumber)
+	}
+
+	client.mine()
+	client.mine(types.Log{Index: 0}, types.Log{Index: 1})
+	logs, cursor := collectLogs(t, sink, 2)
+	if logs[0].BlockNumber != 102 || logs[1].Index != 1 || cursor != (logCursor{102, 2}) {
+		t.Fatalf("unexpected polled logs %+v, cursor %s", logs, cursor)
+	}
+
+	// later polls must not repeat the block
+	client.mine(types.Log{Index: 5})
+	logs, _ = collectLogs(t, sink, 1)
+	if logs[0].BlockNumber != 103 {
+		t.Fatalf("expected block 103, got %d", logs[0].BlockNumber)
+	}
+}
+
+func TestLogCursorString(t *testing.T) {
+	c := logCursor{Block: 40581246, Index: 7}
+	got, err := parseLogCursor(c.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != c {
+		t.Fatalf("got %s, want %s", got, c)
+	}
+	if _, err := parseLogCursor("latest"); err == nil {
+		t.Fatal("parsed an invalid cursor")
+	}
+}
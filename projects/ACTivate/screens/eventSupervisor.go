@@ -0,0 +1,202 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	reconnectBaseDelay = time.Second
+	reconnectMaxDelay  = time.Minute
+
+	// offlineAfterAttempts failed reconnects in a row show the listener as offline
+	offlineAfterAttempts = 5
+)
+
+var errSubscriptionClosed = errors.New("subscription closed")
+
+// connectionState is the state of the contract event listener shown to the user
+type connectionState int
+
+const (
+	stateConnecting connectionState = iota
+	stateConnected
+	stateReconnecting
+	stateOffline
+)
+
+func (s connectionState) String() string {
+	switch s {
+	case stateConnecting:
+		return "connecting"
+	case stateConnected:
+		return "connected"
+	case stateReconnecting:
+		return "reconnecting"
+	case stateOffline:
+		return "offline"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(s))
+	}
+}
+
+// reconnectDelay returns how long to wait before reconnect attempt n, counted
+// from 0. The delay doubles up to reconnectMaxDelay and is jittered by up to
+// 20% either way, so clients that lost the same endpoint do not return at once.
+func reconnectDelay(attempt int) time.Duration {
+	delay := reconnectMaxDelay
+	if attempt < 16 {
+		delay = min(reconnectBaseDelay<<attempt, reconnectMaxDelay)
+	}
+	jitter := (rand.Float64()*0.4 - 0.2) * float64(delay)
+	return delay + time.Duration(jitter)
+}
+
+// superviseEventSubscription keeps the event subscription running until ctx
+// is cancelled. Whenever it fails the client is redialled and the
+// subscription resumes from the checkpoint after a growing delay.
+func (i *index) superviseEventSubscription(ctx context.Context) {
+	defer i.logger.Log("Event listener goroutine stopped.")
+
+	attempt := 0
+	i.setConnectionState(stateConnecting)
+	for {
+		err := i.runEventSubscription(ctx, func() {
+			attempt = 0
+			i.setConnectionState(stateConnected)
+		})
+		if ctx.Err() != nil {
+			i.logger.Log("Event listener context cancelled. Unsubscribing.")
+			return
+		}
+		i.logger.Log(fmt.Sprintf("Event subscription error: %v", err))
+
+		if attempt >= offlineAfterAttempts {
+			i.setConnectionState(stateOffline)
+		} else {
+			i.setConnectionState(stateReconnecting)
+		}
+		delay := reconnectDelay(attempt)
+		attempt++
+		i.logger.Log(fmt.Sprintf("Reconnecting to the event stream in %s (attempt %d)", delay.Round(time.Millisecond), attempt))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		if err := i.redialEthClient(ctx); err != nil {
+			i.logger.Log(fmt.Sprintf("Failed to redial %s: %v", i.contractRPCEndpoint(), err))
+		}
+	}
+}
+
+// runEventSubscription subscribes from the checkpoint and handles events until
+// the subscription fails or ctx is cancelled. onConnected is called once the
+// subscription is up.
+func (i *index) runEventSubscription(ctx context.Context, onConnected func()) error {
+	if i.contractClient() == nil {
+		if err := i.redialEthClient(ctx); err != nil {
+			return err
+		}
+	}
+
+	checkpointKey, err := i.eventCheckpointKey(ctx)
+	if err != nil {
+		return err
+	}
+	// fails until the deploy block can be looked up, the supervisor retries
+	checkpoint, err := i.loadEventCheckpoint(ctx, checkpointKey)
+	if err != nil {
+		return err
+	}
+	i.logger.Log(fmt.Sprintf("Resuming DataSentToTarget events from %s", checkpoint.Cursor))
+
+	logs := make(chan streamedLog)
+	sub, err := i.contractSvc.SubscribeDataSentToTarget(ctx, i.contractClient(), i.eventRecipients(), checkpoint, logs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to DataSentToTarget: %w", err)
+	}
+	i.eventLogSubscription = sub
+	defer sub.Unsubscribe()
+
+	i.logger.Log("Successfully subscribed to DataSentToTarget events.")
+	onConnected()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.Err():
+			if err == nil {
+				err = errSubscriptionClosed
+			}
+			return err
+		case item := <-logs:
+			// checkpoint as soon as an item is taken off the stream, so
+			// a restart does not deliver it twice
+			if stored, err := item.checkpoint().marshal(); err != nil {
+				i.logger.Log(fmt.Sprintf("Failed to store event checkpoint: %v", err))
+			} else {
+				i.setPreference(checkpointKey, stored)
+			}
+			switch {
+			case item.Log == nil:
+			case item.Log.Removed:
+				i.revertDataSentToTarget(*item.Log)
+			default:
+				i.handleDataSentToTarget(*item.Log)
+			}
+		}
+	}
+}
+
+// contractRPCEndpoint returns the endpoint the contract client dials
+func (i *index) contractRPCEndpoint() string {
+	if i.nodeConfig.rpcEndpoint != "" {
+		return i.nodeConfig.rpcEndpoint
+	}
+	return i.network().RPCURL
+}
+
+// contractClient returns the contract client, nil until a dial succeeded.
+// Calls still running on a client that is replaced fail like any other RPC
+// error.
+func (i *index) contractClient() *ethclient.Client {
+	i.ethClientMu.RLock()
+	defer i.ethClientMu.RUnlock()
+	return i.ethClient
+}
+
+// redialEthClient replaces the contract client with a fresh connection and
+// closes the previous one
+func (i *index) redialEthClient(ctx context.Context) error {
+	client, err := ethclient.DialContext(ctx, i.contractRPCEndpoint())
+	if err != nil {
+		return err
+	}
+	i.ethClientMu.Lock()
+	previous := i.ethClient
+	i.ethClient = client
+	i.ethClientMu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// setConnectionState shows the event listener state in the UI
+func (i *index) setConnectionState(state connectionState) {
+	i.logger.Log(fmt.Sprintf("Event listener %s", state))
+	if i.connectionLabel != nil {
+		i.connectionLabel.SetText(fmt.Sprintf("Contract events: %s", state))
+	}
+	if i.eventMessageLabel != nil && state == stateConnected {
+		i.eventMessageLabel.SetText("Subscribed. Waiting for 'DataSentToTarget' events...")
+	}
+}
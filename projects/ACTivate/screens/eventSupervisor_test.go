@@ -0,0 +1,53 @@
+package screens
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	within := func(d, want time.Duration) bool {
+		return d >= want*8/10 && d <= want*12/10
+	}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, time.Minute} {
+		for range 50 {
+			if d := reconnectDelay(attempt); !within(d, want) {
+				t.Fatalf("attempt %d: delay %s outside 20%% of %s", attempt, d, want)
+			}
+		}
+	}
+	// large attempt counts must not overflow the shift
+	for _, attempt := range []int{7, 40, 1000} {
+		if d := reconnectDelay(attempt); !within(d, reconnectMaxDelay) {
+			t.Fatalf("attempt %d: delay %s not capped at %s", attempt, d, reconnectMaxDelay)
+		}
+	}
+}
+
+func TestRedialWhileReading(t *testing.T) {
+	// an HTTP endpoint is dialled lazily, nothing has to listen on it
+	i := &index{nodeConfig: &nodeConfig{rpcEndpoint: "http://127.0.0.1:1"}, logger: &logger{}}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if client := i.contractClient(); client != nil {
+					client.Client()
+				}
+			}
+		}()
+	}
+	for range 20 {
+		if err := i.redialEthClient(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if i.contractClient() == nil {
+		t.Fatal("no client after redialing")
+	}
+}
@@ -0,0 +1,408 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// evmChain is an in-memory chain that executes transactions in the EVM and
+// mines each one into a block of its own, like an automining dev node. It
+// serves the client interfaces of the app and the bee transaction service.
+//
+// go-ethereum's simulated backend does the same but pulls in the node
+// package, which no longer links with current Go releases.
+type evmChain struct {
+	config *params.ChainConfig
+	engine consensus.Engine
+	db     ethdb.Database
+
+	mu       sync.Mutex
+	blocks   []*types.Block
+	receipts map[common.Hash]*types.Receipt
+	txs      map[common.Hash]*types.Transaction
+	blockLog map[uint64][]*types.Log
+	logFeed  event.Feed
+}
+
+var errEVMChainClosed = errors.New("evm chain closed")
+
+func newEVMChain(alloc types.GenesisAlloc) *evmChain {
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config:   params.MergedTestChainConfig,
+		Alloc:    alloc,
+		GasLimit: 30_000_000,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+		// proof of stake from genesis, for the Shanghai opcodes solc emits
+		Difficulty: common.Big0,
+	}
+	block := genesis.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	return &evmChain{
+		config:   genesis.Config,
+		engine:   beacon.New(ethash.NewFaker()),
+		db:       db,
+		blocks:   []*types.Block{block},
+		receipts: map[common.Hash]*types.Receipt{},
+		txs:      map[common.Hash]*types.Transaction{},
+		blockLog: map[uint64][]*types.Log{},
+	}
+}
+
+func (c *evmChain) head() *types.Block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// mine adds a block with the transactions, which must all be executable
+func (c *evmChain) mine(txs ...*types.Transaction) (err error) {
+	c.mu.Lock()
+	var logs []*types.Log
+	defer func() {
+		c.mu.Unlock()
+		for _, l := range logs {
+			c.logFeed.Send(*l)
+		}
+	}()
+	// the block generator panics on transactions it cannot execute
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	blocks, receipts := core.GenerateChain(c.config, c.head(), c.engine, c.db, 1, func(_ int, b *core.BlockGen) {
+		// the generator cannot look up the total difficulty of blocks it did
+		// not make, so it has to be told the chain is past the merge
+		b.SetDifficulty(common.Big0)
+		for _, tx := range txs {
+			b.AddTx(tx)
+		}
+	})
+	block := blocks[0]
+	c.blocks = append(c.blocks, block)
+	for n, receipt := range receipts[0] {
+		c.receipts[receipt.TxHash] = receipt
+		c.txs[receipt.TxHash] = txs[n]
+		logs = append(logs, receipt.Logs...)
+	}
+	c.blockLog[block.NumberU64()] = logs
+	return nil
+}
+
+// advance mines n empty blocks
+func (c *evmChain) advance(n int) error {
+	for range n {
+		if err := c.mine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *evmChain) stateAt(number *big.Int) (*state.StateDB, *types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	header := c.head().Header()
+	if number != nil {
+		if !number.IsUint64() || number.Uint64() >= uint64(len(c.blocks)) {
+			return nil, nil, ethereum.NotFound
+		}
+		header = c.blocks[number.Uint64()].Header()
+	}
+	statedb, err := state.New(header.Root, state.NewDatabase(c.db), nil)
+	return statedb, header, err
+}
+
+// revertError carries the revert data the way an RPC node returns it
+type revertError struct {
+	reason string
+	data   []byte
+}
+
+func (e *revertError) Error() string          { return "execution reverted: " + e.reason }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// call executes msg on the state at number, the head for nil, without changing it
+func (c *evmChain) call(msg ethereum.CallMsg, number *big.Int) (*core.ExecutionResult, error) {
+	statedb, header, err := c.stateAt(number)
+	if err != nil {
+		return nil, err
+	}
+	gas := msg.Gas
+	if gas == 0 {
+		gas = header.GasLimit
+	}
+	message := &core.Message{
+		From:              msg.From,
+		To:                msg.To,
+		Value:             new(big.Int),
+		GasLimit:          gas,
+		GasPrice:          new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasTipCap:         new(big.Int),
+		Data:              msg.Data,
+		SkipAccountChecks: true,
+	}
+	if msg.Value != nil {
+		message.Value = msg.Value
+	}
+	blockContext := core.NewEVMBlockContext(header, c, nil)
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(message), statedb, c.config, vm.Config{NoBaseFee: true})
+	result, err := core.ApplyMessage(evm, message, new(core.GasPool).AddGas(gas))
+	if err != nil {
+		return nil, err
+	}
+	if errors.Is(result.Err, vm.ErrExecutionReverted) {
+		reason := "no reason"
+		if unpacked, err := abi.UnpackRevert(result.Revert()); err == nil {
+			reason = unpacked
+		}
+		return nil, &revertError{reason: reason, data: result.Revert()}
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result, nil
+}
+
+// Engine and GetHeader make the chain a core.ChainContext for the EVM
+func (c *evmChain) Engine() consensus.Engine { return c.engine }
+
+func (c *evmChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number < uint64(len(c.blocks)) && c.blocks[number].Hash() == hash {
+		return c.blocks[number].Header()
+	}
+	return nil
+}
+
+func (c *evmChain) CodeAt(_ context.Context, contract common.Address, number *big.Int) ([]byte, error) {
+	statedb, _, err := c.stateAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(contract), nil
+}
+
+func (c *evmChain) CallContract(_ context.Context, msg ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	result, err := c.call(msg, number)
+	if err != nil {
+		return nil, err
+	}
+	return result.ReturnData, nil
+}
+
+func (c *evmChain) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	msg.Gas = 0
+	result, err := c.call(msg, nil)
+	if err != nil {
+		return 0, err
+	}
+	// A transaction can need more gas than it ends up using, for refunds and
+	// the gas calls hold back, so search for the least limit it succeeds with
+	// like a node does. It succeeded with the block gas limit.
+	header, err := c.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	lo, hi := result.UsedGas-1, header.GasLimit
+	for lo+1 < hi {
+		msg.Gas = (lo + hi) / 2
+		if result, err := c.call(msg, nil); err == nil && result.Err == nil {
+			hi = msg.Gas
+		} else {
+			lo = msg.Gas
+		}
+	}
+	return hi, nil
+}
+
+func (c *evmChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number == nil {
+		return c.head().Header(), nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(c.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return c.blocks[number.Uint64()].Header(), nil
+}
+
+func (c *evmChain) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range c.blocks {
+		if b.Hash() == hash {
+			return b.Header(), nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *evmChain) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head().NumberU64(), nil
+}
+
+func (c *evmChain) ChainID(context.Context) (*big.Int, error) {
+	return c.config.ChainID, nil
+}
+
+func (c *evmChain) NonceAt(_ context.Context, account common.Address, number *big.Int) (uint64, error) {
+	statedb, _, err := c.stateAt(number)
+	if err != nil {
+		return 0, err
+	}
+	return statedb.GetNonce(account), nil
+}
+
+// PendingNonceAt is the nonce at the head, transactions are mined as they are sent
+func (c *evmChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.NonceAt(ctx, account, nil)
+}
+
+func (c *evmChain) BalanceAt(_ context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	statedb, _, err := c.stateAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetBalance(account).ToBig(), nil
+}
+
+func (c *evmChain) SuggestGasPrice(context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Mul(c.head().BaseFee(), big.NewInt(2)), nil
+}
+
+func (c *evmChain) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei), nil
+}
+
+func (c *evmChain) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	return c.mine(tx)
+}
+
+func (c *evmChain) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *evmChain) TransactionByHash(_ context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tx, ok := c.txs[txHash]; ok {
+		return tx, false, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+// matchLog reports whether l matches the address and topics of query
+func matchLog(query ethereum.FilterQuery, l types.Log) bool {
+	if len(query.Addresses) > 0 && !containsAddress(query.Addresses, l.Address) {
+		return false
+	}
+	if len(query.Topics) > len(l.Topics) {
+		return false
+	}
+	for n, alternatives := range query.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			found = found || topic == l.Topics[n]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *evmChain) FilterLogs(_ context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from, to := uint64(0), c.head().NumberU64()
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil && query.ToBlock.Uint64() < to {
+		to = query.ToBlock.Uint64()
+	}
+	var logs []types.Log
+	for number := from; number <= to; number++ {
+		for _, l := range c.blockLog[number] {
+			if matchLog(query, *l) {
+				logs = append(logs, *l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+func (c *evmChain) SubscribeFilterLogs(_ context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	mined := make(chan types.Log)
+	feedSub := c.logFeed.Subscribe(mined)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer feedSub.Unsubscribe()
+		for {
+			select {
+			case l := <-mined:
+				if !matchLog(query, l) {
+					continue
+				}
+				select {
+				case ch <- l:
+				case <-quit:
+					return nil
+				}
+			case err := <-feedSub.Err():
+				if err == nil {
+					err = errEVMChainClosed
+				}
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (c *evmChain) Close() {}
@@ -0,0 +1,261 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/sctx"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+// feeClient is the part of the chain client fee estimates need
+type feeClient interface {
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// feeSpeed is how much above the suggested fees a transaction pays to be
+// included sooner
+type feeSpeed int
+
+const (
+	feeSlow feeSpeed = iota
+	feeNormal
+	feeFast
+)
+
+var feeSpeeds = []feeSpeed{feeSlow, feeNormal, feeFast}
+
+func (s feeSpeed) String() string {
+	switch s {
+	case feeSlow:
+		return "slow"
+	case feeNormal:
+		return "normal"
+	case feeFast:
+		return "fast"
+	default:
+		return fmt.Sprintf("unknown (%d)", int(s))
+	}
+}
+
+// boostPercent is added to the suggested gas price and tip
+func (s feeSpeed) boostPercent() int {
+	switch s {
+	case feeSlow:
+		return 0
+	case feeFast:
+		return 100
+	default:
+		return transaction.DefaultTipBoostPercent
+	}
+}
+
+var errInsufficientFunds = errors.New("insufficient funds for the transaction fee")
+
+// feeEstimate is the expected cost of a transaction, computed the way the
+// transaction service will fill in its fees
+type feeEstimate struct {
+	Speed    feeSpeed
+	GasUsed  uint64
+	GasLimit uint64
+	BaseFee  *big.Int
+	GasPrice *big.Int
+	TipCap   *big.Int
+	FeeCap   *big.Int
+	// Expected is the fee if the transaction uses the estimated gas at the
+	// current base fee, Max is the most it can cost
+	Expected *big.Int
+	Max      *big.Int
+	Balance  *big.Int
+}
+
+// sufficient reports whether the balance covers the most the transaction can cost
+func (f *feeEstimate) sufficient() bool {
+	return f.Balance.Cmp(f.Max) >= 0
+}
+
+// boost adds percent to v
+func boost(v *big.Int, percent int) *big.Int {
+	boosted := new(big.Int).Mul(v, big.NewInt(int64(100+percent)))
+	return boosted.Div(boosted, big.NewInt(100))
+}
+
+// estimateFee estimates gas for msg and prices it at the given speed
+func estimateFee(ctx context.Context, client feeClient, msg ethereum.CallMsg, speed feeSpeed) (*feeEstimate, error) {
+	gasUsed, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	suggestedPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	suggestedTip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get priority fee: %w", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base fee: %w", err)
+	}
+	balance, err := client.BalanceAt(ctx, msg.From, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	f := &feeEstimate{
+		Speed:    speed,
+		GasUsed:  gasUsed,
+		GasLimit: gasUsed + gasUsed/4, // the same 25% margin the transaction service adds
+		GasPrice: boost(suggestedPrice, speed.boostPercent()),
+		TipCap:   boost(suggestedTip, speed.boostPercent()),
+		Balance:  balance,
+	}
+	f.FeeCap = new(big.Int).Add(f.GasPrice, f.TipCap)
+
+	// chains without EIP-1559 charge the full gas price
+	f.BaseFee = head.BaseFee
+	perGas := f.FeeCap
+	if f.BaseFee != nil {
+		perGas = new(big.Int).Add(f.BaseFee, f.TipCap)
+		if perGas.Cmp(f.FeeCap) > 0 {
+			perGas = f.FeeCap
+		}
+	}
+	f.Expected = new(big.Int).Mul(perGas, new(big.Int).SetUint64(f.GasUsed))
+	f.Max = new(big.Int).Mul(f.FeeCap, new(big.Int).SetUint64(f.GasLimit))
+	return f, nil
+}
+
+type tipBoostKey struct{}
+
+// withFee makes the transaction sent with ctx use the estimated fees
+func withFee(ctx context.Context, f *feeEstimate) context.Context {
+	ctx = sctx.SetGasPrice(ctx, f.GasPrice)
+	ctx = sctx.SetGasLimit(ctx, f.GasLimit)
+	return context.WithValue(ctx, tipBoostKey{}, f.Speed.boostPercent())
+}
+
+// tipBoost returns the tip boost set by withFee, or the default one
+func tipBoost(ctx context.Context) int {
+	if percent, ok := ctx.Value(tipBoostKey{}).(int); ok {
+		return percent
+	}
+	return transaction.DefaultTipBoostPercent
+}
+
+// formatUnits formats an amount with the given number of decimals
+func formatUnits(amount *big.Int, decimals int, precision int) string {
+	if amount == nil {
+		return "n/a"
+	}
+	f := new(big.Float).SetInt(amount)
+	f.Quo(f, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	return f.Text('f', precision)
+}
+
+func formatNative(wei *big.Int, symbol string) string {
+	return fmt.Sprintf("%s %s", formatUnits(wei, 18, 8), symbol)
+}
+
+func formatGwei(wei *big.Int) string {
+	return fmt.Sprintf("%s gwei", formatUnits(wei, 9, 3))
+}
+
+// confirmFee shows what a transaction will cost at the speed the user picks.
+// estimate is called for every speed picked, send once the user confirms.
+// With a relay, the user can also have a relayer send it for free.
+func (i *index) confirmFee(title string, estimate func(feeSpeed) (*feeEstimate, error), send func(*feeEstimate), relay func()) {
+	feeLabel := widget.NewLabel("Estimating fee...")
+	warningLabel := widget.NewLabel("")
+	warningLabel.Importance = widget.DangerImportance
+	warningLabel.Wrapping = fyne.TextWrapWord
+
+	symbol := i.network().NativeToken
+	var current *feeEstimate
+	// latest numbers the estimates, so a slow one cannot replace a newer one
+	var latest atomic.Int64
+	var d *dialog.CustomDialog
+	sendButton := widget.NewButton("Send", func() {
+		d.Hide()
+		send(current)
+	})
+	sendButton.Importance = widget.HighImportance
+	sendButton.Disable()
+	cancelButton := widget.NewButton("Cancel", func() {
+		d.Hide()
+	})
+	buttons := []fyne.CanvasObject{cancelButton}
+	if relay != nil {
+		buttons = append(buttons, widget.NewButton("Send Gasless", func() {
+			d.Hide()
+			relay()
+		}))
+	}
+	buttons = append(buttons, sendButton)
+
+	speedSelect := widget.NewRadioGroup(nil, nil)
+	for _, speed := range feeSpeeds {
+		speedSelect.Options = append(speedSelect.Options, speed.String())
+	}
+	speedSelect.Horizontal = true
+	speedSelect.Required = true
+	speedSelect.OnChanged = func(selected string) {
+		speed := feeNormal
+		for _, s := range feeSpeeds {
+			if s.String() == selected {
+				speed = s
+			}
+		}
+		sendButton.Disable()
+		feeLabel.SetText("Estimating fee...")
+		warningLabel.SetText("")
+		seq := latest.Add(1)
+		go func() {
+			f, err := estimate(speed)
+			if seq != latest.Load() {
+				return
+			}
+			if err != nil {
+				feeLabel.SetText("Fee estimate failed")
+				warningLabel.SetText(err.Error())
+				return
+			}
+			current = f
+			feeLabel.SetText(fmt.Sprintf(
+				"Gas: %d (limit %d)\nBase fee: %s\nPriority fee: %s\nMax fee: %s\nEstimated cost: %s\nMaximum cost: %s\nBalance: %s",
+				f.GasUsed, f.GasLimit, formatGwei(f.BaseFee), formatGwei(f.TipCap), formatGwei(f.FeeCap),
+				formatNative(f.Expected, symbol), formatNative(f.Max, symbol), formatNative(f.Balance, symbol)))
+			if !f.sufficient() {
+				if relay != nil {
+					warningLabel.SetText(fmt.Sprintf("%v: the balance does not cover the maximum cost, send it gasless through the relayer.", errInsufficientFunds))
+					return
+				}
+				warningLabel.SetText(fmt.Sprintf("%v: the balance does not cover the maximum cost.", errInsufficientFunds))
+				return
+			}
+			sendButton.Enable()
+		}()
+	}
+
+	content := container.NewVBox(speedSelect, feeLabel, warningLabel)
+	d = dialog.NewCustomWithoutButtons(title, content, i.Window)
+	d.SetButtons(buttons)
+	d.Resize(fyne.NewSize(450, 350))
+	d.Show()
+	speedSelect.SetSelected(feeNormal.String())
+}
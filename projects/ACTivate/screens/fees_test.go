@@ -0,0 +1,97 @@
+package screens
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethersphere/bee/v2/pkg/sctx"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+type fakeFeeClient struct {
+	gas     uint64
+	price   int64
+	tip     int64
+	baseFee *big.Int
+	balance int64
+}
+
+func (f *fakeFeeClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return f.gas, nil
+}
+
+func (f *fakeFeeClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(f.price), nil
+}
+
+func (f *fakeFeeClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(f.tip), nil
+}
+
+func (f *fakeFeeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeFeeClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(f.balance), nil
+}
+
+func TestEstimateFee(t *testing.T) {
+	client := &fakeFeeClient{gas: 40000, price: 100, tip: 10, baseFee: big.NewInt(50), balance: 10_000_000}
+
+	tests := []struct {
+		speed    feeSpeed
+		baseFee  *big.Int
+		price    int64 // boosted gas price
+		tip      int64 // boosted tip
+		expected int64
+	}{
+		{feeSlow, big.NewInt(50), 100, 10, 40000 * 60},
+		{feeNormal, big.NewInt(50), 120, 12, 40000 * 62},
+		{feeFast, big.NewInt(50), 200, 20, 40000 * 70},
+		// a base fee above the cap is paid at the cap
+		{feeNormal, big.NewInt(500), 120, 12, 40000 * 132},
+		// without EIP-1559 the full gas price is paid
+		{feeNormal, nil, 120, 12, 40000 * 132},
+	}
+	for _, tt := range tests {
+		client.baseFee = tt.baseFee
+		f, err := estimateFee(context.Background(), client, ethereum.CallMsg{}, tt.speed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.GasLimit != 50000 || f.GasPrice.Int64() != tt.price || f.TipCap.Int64() != tt.tip || f.FeeCap.Int64() != tt.price+tt.tip {
+			t.Fatalf("%s: limit %d, price %s, tip %s, cap %s", tt.speed, f.GasLimit, f.GasPrice, f.TipCap, f.FeeCap)
+		}
+		if f.Expected.Int64() != tt.expected || f.Max.Int64() != 50000*(tt.price+tt.tip) {
+			t.Fatalf("%s: expected %s, max %s", tt.speed, f.Expected, f.Max)
+		}
+		if f.sufficient() != (f.Max.Int64() <= client.balance) {
+			t.Fatalf("%s: sufficient=%v with max %s and balance %d", tt.speed, f.sufficient(), f.Max, client.balance)
+		}
+	}
+}
+
+func TestWithFee(t *testing.T) {
+	if tipBoost(context.Background()) != transaction.DefaultTipBoostPercent {
+		t.Fatal("unexpected default tip boost")
+	}
+	f := &feeEstimate{Speed: feeFast, GasLimit: 50000, GasPrice: big.NewInt(200)}
+	ctx := withFee(context.Background(), f)
+	if sctx.GetGasLimit(ctx) != 50000 || sctx.GetGasPrice(ctx).Int64() != 200 || tipBoost(ctx) != feeFast.boostPercent() {
+		t.Fatal("fee not carried by the context")
+	}
+}
+
+func TestFormatNative(t *testing.T) {
+	if got := formatNative(big.NewInt(1_500_000_000_000_000), "xDAI"); got != "0.00150000 xDAI" {
+		t.Fatalf("got %q", got)
+	}
+	if got := formatGwei(big.NewInt(1_250_000_000)); got != "1.250 gwei" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+/*
+Key Fingerprints and Safety Numbers
+
+Public keys are 66 or 130 hex characters, too long to compare by eye or read
+out over the phone. Two shorter forms are derived from them:
+
+  - a fingerprint identifies one key: the first 10 bytes of
+    Keccak256("ACTivate fingerprint" || compressed key), as five groups of four
+    hex digits
+  - a safety number identifies a pair of keys: each key is hashed with
+    SHA-512 for fingerprintIterations rounds and turned into 30 digits, and the
+    two halves are joined in key order, so both sides see the same 60 digits
+
+Comparing the safety number over another channel proves that neither side was
+given a substituted key.
+*/
+
+const (
+	fingerprintDomain      = "ACTivate fingerprint"
+	displayFingerprintSize = 10
+	fingerprintIterations  = 5200
+	fingerprintVersion     = 0
+	safetyNumberGroups     = 6
+)
+
+// displayFingerprint returns the short fingerprint of pub shown to users
+func displayFingerprint(pub *ecdsa.PublicKey) string {
+	hash := crypto.Keccak256([]byte(fingerprintDomain), crypto.CompressPubkey(pub))
+	digits := strings.ToUpper(hex.EncodeToString(hash[:displayFingerprintSize]))
+
+	groups := make([]string, 0, len(digits)/4)
+	for n := 0; n < len(digits); n += 4 {
+		groups = append(groups, digits[n:n+4])
+	}
+	return strings.Join(groups, " ")
+}
+
+// safetyNumber returns the safety number of the key pair. It is the same
+// whichever key comes first.
+func safetyNumber(a, b *ecdsa.PublicKey) string {
+	ka, kb := crypto.CompressPubkey(a), crypto.CompressPubkey(b)
+	if bytes.Compare(ka, kb) > 0 {
+		ka, kb = kb, ka
+	}
+	return strings.Join(append(fingerprintDigits(ka), fingerprintDigits(kb)...), " ")
+}
+
+// fingerprintDigits returns six groups of five digits for a compressed key
+func fingerprintDigits(key []byte) []string {
+	hash := sha512.Sum512(append([]byte{0, fingerprintVersion}, key...))
+	for n := 0; n < fingerprintIterations; n++ {
+		hash = sha512.Sum512(append(hash[:], key...))
+	}
+
+	groups := make([]string, safetyNumberGroups)
+	for n := range groups {
+		var chunk [8]byte
+		copy(chunk[3:], hash[n*5:n*5+5])
+		groups[n] = fmt.Sprintf("%05d", binary.BigEndian.Uint64(chunk[:])%100000)
+	}
+	return groups
+}
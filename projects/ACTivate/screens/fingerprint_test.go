@@ -0,0 +1,92 @@
+package screens
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDisplayFingerprint(t *testing.T) {
+	key := mustGenerateKey(t)
+	fp := displayFingerprint(&key.PublicKey)
+
+	if !regexp.MustCompile(`^([0-9A-F]{4} ){4}[0-9A-F]{4}$`).MatchString(fp) {
+		t.Fatalf("unexpected fingerprint format %q", fp)
+	}
+	if displayFingerprint(&key.PublicKey) != fp {
+		t.Fatal("fingerprint not deterministic")
+	}
+	if displayFingerprint(&mustGenerateKey(t).PublicKey) == fp {
+		t.Fatal("different keys share a fingerprint")
+	}
+}
+
+func TestSafetyNumber(t *testing.T) {
+	a, b, c := mustGenerateKey(t), mustGenerateKey(t), mustGenerateKey(t)
+
+	ab := safetyNumber(&a.PublicKey, &b.PublicKey)
+	if !regexp.MustCompile(`^([0-9]{5} ){11}[0-9]{5}$`).MatchString(ab) {
+		t.Fatalf("unexpected safety number format %q", ab)
+	}
+	if ba := safetyNumber(&b.PublicKey, &a.PublicKey); ba != ab {
+		t.Fatalf("safety number depends on key order: %q and %q", ab, ba)
+	}
+	if ac := safetyNumber(&a.PublicKey, &c.PublicKey); ac == ab {
+		t.Fatal("substituted key gives the same safety number")
+	}
+}
+
+func TestContactKeyChange(t *testing.T) {
+	var book contactBook
+	first, second := mustGenerateKey(t), mustGenerateKey(t)
+
+	c, changed := book.save("alice", &first.PublicKey)
+	if changed || c.status() != "unverified" {
+		t.Fatalf("new contact: changed=%v status=%s", changed, c.status())
+	}
+	c.markVerified()
+
+	if _, changed := book.save("alice", &first.PublicKey); changed || c.status() != "verified" {
+		t.Fatalf("saving the same key: changed=%v status=%s", changed, c.status())
+	}
+
+	c, changed = book.save("alice", &second.PublicKey)
+	if !changed || c.status() != "key changed" || c.Verified {
+		t.Fatalf("key change: changed=%v status=%s", changed, c.status())
+	}
+	if len(book) != 1 || len(c.PreviousKeys) != 1 {
+		t.Fatalf("expected one contact with one previous key, got %d and %d", len(book), len(c.PreviousKeys))
+	}
+	if book.byKey(&second.PublicKey) != c || book.byKey(&first.PublicKey) != nil {
+		t.Fatal("contact not found by its current key only")
+	}
+
+	c.markVerified()
+	if c.status() != "verified" {
+		t.Fatalf("re-verified contact is %s", c.status())
+	}
+}
+
+func TestContactForwardedPayload(t *testing.T) {
+	var book contactBook
+	first, second := mustGenerateKey(t), mustGenerateKey(t)
+	c, _ := book.save("alice", &first.PublicKey)
+	c.markVerified()
+	sender := crypto.PubkeyToAddress(first.PublicKey)
+
+	if book.forwardedBy(sender, &first.PublicKey) != nil {
+		t.Fatal("payload signed by the contact's own key flagged")
+	}
+	if book.forwardedBy(crypto.PubkeyToAddress(second.PublicKey), &second.PublicKey) != nil {
+		t.Fatal("stranger matched a contact")
+	}
+
+	// alice's address relays a payload someone else signed, her key stays
+	if forwarded := book.forwardedBy(sender, &second.PublicKey); forwarded != c {
+		t.Fatalf("forwarded payload not flagged, got %v", forwarded)
+	}
+	if c.status() != "verified" || len(c.PreviousKeys) != 0 || book.byKey(&first.PublicKey) != c || book.byKey(&second.PublicKey) != nil {
+		t.Fatalf("contact changed by a forwarded payload: %+v", c)
+	}
+}
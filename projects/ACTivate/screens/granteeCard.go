@@ -2,6 +2,7 @@ package screens
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 
@@ -70,12 +71,7 @@ func (i *index) showGranteeCard() fyne.CanvasObject {
 	}
 
 	if eglrefString != "" {
-		eglrefBytes, err := hex.DecodeString(eglrefString)
-		if err == nil && len(eglrefBytes) == 64 {
-			currentEglRef = swarm.NewAddress(eglrefBytes)
-		} else {
-			i.logger.Log(fmt.Sprintf("Error decoding stored eglref '%s' (len %d) or invalid length: %v. Expected %d bytes.", eglrefString, len(eglrefBytes), err, 64))
-		}
+		currentEglRef = i.storedEglRef()
 	}
 
 	granteeList = widget.NewList(
@@ -83,15 +79,25 @@ func (i *index) showGranteeCard() fyne.CanvasObject {
 			return len(granteesData)
 		},
 		func() fyne.CanvasObject {
-			return widget.NewLabel("template grantee")
+			return widget.NewLabel("template grantee\nfingerprint")
 		},
 		func(id widget.ListItemID, item fyne.CanvasObject) {
 			if id < len(granteesData) {
-				item.(*widget.Label).SetText(granteesData[id])
+				item.(*widget.Label).SetText(i.describeKey(granteesData[id]))
 			}
 		},
 	)
 
+	selectedGrantee := ""
+	granteeList.OnSelected = func(id widget.ListItemID) {
+		if id < len(granteesData) {
+			selectedGrantee = granteesData[id]
+		}
+	}
+	granteeList.OnUnselected = func(id widget.ListItemID) {
+		selectedGrantee = ""
+	}
+
 	loadAndRefreshGrantees() // Initial asynchronous load
 
 	granteeScroll := container.NewScroll(granteeList)
@@ -99,6 +105,10 @@ func (i *index) showGranteeCard() fyne.CanvasObject {
 
 	newGranteeEntry := widget.NewEntry()
 	newGranteeEntry.SetPlaceHolder("New grantee public key (hex)")
+	granteeFingerprint := widget.NewLabel("")
+	newGranteeEntry.OnChanged = func(s string) {
+		granteeFingerprint.SetText(i.fingerprintHint(s))
+	}
 
 	historyEntry := widget.NewEntry()
 	savedHistoryRef := i.getPreferenceString(historyRefPrefKey)
@@ -195,17 +205,103 @@ func (i *index) showGranteeCard() fyne.CanvasObject {
 
 			loadAndRefreshGrantees() // Reload the list with the new EGL
 
+			// new members get the current group key, no rotation needed
+			bundleRef, err := i.publishGroupKeyBundle(context.Background(), batchHex, false)
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error publishing group key bundle: %v", err))
+				i.showError(fmt.Errorf("grantee added but the group key was not shared: %w", err))
+				return
+			}
+			// earlier files point at bundles without the new member, send it the new one
+			granteeKey, err := (&EncryptionUtils{}).ParsePublicKeyFromHex(granteeToAdd)
+			if err == nil {
+				err = i.shareGroupKeyBundle(context.Background(), bundleRef, []*ecdsa.PublicKey{granteeKey})
+			}
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error sending group key bundle to %s: %v", granteeToAdd, err))
+				i.showError(fmt.Errorf("grantee added but not sent the group key bundle, earlier files stay unreadable for it: %w", err))
+			}
+
 		}(currentEglRef, resolvedHistoryRef, newGranteeStr)
 	})
 
+	revokeButton := widget.NewButton("Revoke Selected Grantee", func() {
+		granteeToRevoke := selectedGrantee
+		if granteeToRevoke == "" {
+			i.showError(fmt.Errorf("select a grantee to revoke"))
+			return
+		}
+		if currentEglRef.IsZero() {
+			i.showError(fmt.Errorf("no grantee list to revoke from"))
+			return
+		}
+
+		stamp := i.getStamp()
+		if stamp == nil {
+			i.showError(fmt.Errorf("no usable postage stamp found"))
+			statusLabel.SetText("Error: No usable postage stamp.")
+			return
+		}
+		batchHex := hex.EncodeToString(stamp.ID())
+
+		historyRef := swarm.ZeroAddress
+		if historyEntry.Text != "" {
+			historyBytes, err := hex.DecodeString(historyEntry.Text)
+			if err != nil || len(historyBytes) != swarm.HashSize {
+				i.showError(fmt.Errorf("invalid history reference hex string or length: %v", err))
+				return
+			}
+			historyRef = swarm.NewAddress(historyBytes)
+		}
+
+		statusLabel.SetText("Revoking grantee...")
+
+		go func(currentEGLForOp swarm.Address) {
+			i.logger.Log(fmt.Sprintf("Revoking grantee %s from EGL: %s", granteeToRevoke, currentEGLForOp.String()))
+			newEglAddress, newHistoryAddress, err := i.bl.AddRevokeGrantees(
+				context.Background(),
+				batchHex,
+				currentEGLForOp,
+				historyRef,
+				[]string{},
+				[]string{granteeToRevoke},
+			)
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error in AddRevokeGrantees: %v", err))
+				i.showError(fmt.Errorf("failed to revoke grantee: %w", err))
+				statusLabel.SetText("Failed to revoke grantee.")
+				return
+			}
+
+			i.setPreference(eglrefPrefKey, newEglAddress.String())
+			i.setPreference(historyRefPrefKey, newHistoryAddress.String())
+			currentEglRef = newEglAddress
+			selectedGrantee = ""
+			granteeList.UnselectAll()
+			historyEntry.SetText(newHistoryAddress.String())
+
+			loadAndRefreshGrantees()
+
+			// a revoked member must not be able to read anything published
+			// from now on, so the group key is rotated
+			if _, err := i.publishGroupKeyBundle(context.Background(), batchHex, true); err != nil {
+				i.logger.Log(fmt.Sprintf("Error rotating group key: %v", err))
+				i.showError(fmt.Errorf("grantee revoked but the group key was not rotated: %w", err))
+			}
+		}(currentEglRef)
+	})
+
 	layout := container.NewVBox(
 		statusLabel,
 		granteeScroll,
 		widget.NewLabel("New Grantee Public Key:"),
 		newGranteeEntry,
+		granteeFingerprint,
 		widget.NewLabel("History Reference:"),
 		historyEntry,
 		submitButton,
+		revokeButton,
+		i.notifyMembersButton(),
 	)
 
 	return layout
@@ -253,6 +349,11 @@ func (i *index) createGranteeCard() fyne.CanvasObject {
 		i.setPreference(eglrefPrefKey, newEglRefString)
 		i.setPreference(historyRefPrefKey, newHistoryRefString)
 
+		if _, err := i.publishGroupKeyBundle(context.Background(), batchHex, true); err != nil {
+			i.logger.Log(fmt.Sprintf("Error publishing group key bundle: %v", err))
+			i.showError(fmt.Errorf("grantee list created but the group key was not shared: %w", err))
+		}
+
 		newGranteeEntry.SetText("")
 	})
 
@@ -266,6 +367,44 @@ func (i *index) createGranteeCard() fyne.CanvasObject {
 	return layout
 }
 
+// storedEglRef returns the encrypted grantee list reference saved in preferences
+func (i *index) storedEglRef() swarm.Address {
+	eglrefString := i.getPreferenceString(eglrefPrefKey)
+	if eglrefString == "" {
+		return swarm.ZeroAddress
+	}
+	eglrefBytes, err := hex.DecodeString(eglrefString)
+	if err != nil || len(eglrefBytes) != 64 {
+		i.logger.Log(fmt.Sprintf("Error decoding stored eglref '%s' (len %d) or invalid length: %v. Expected %d bytes.", eglrefString, len(eglrefBytes), err, 64))
+		return swarm.ZeroAddress
+	}
+	return swarm.NewAddress(eglrefBytes)
+}
+
+// granteePublicKeys returns the public keys of the current grantee list
+func (i *index) granteePublicKeys(ctx context.Context) ([]*ecdsa.PublicKey, error) {
+	eglRef := i.storedEglRef()
+	if eglRef.IsZero() {
+		return nil, nil
+	}
+
+	grantees, err := i.bl.GetGranteeList(ctx, eglRef, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grantee list: %w", err)
+	}
+
+	encryptionUtils := &EncryptionUtils{}
+	keys := make([]*ecdsa.PublicKey, 0, len(grantees))
+	for _, grantee := range grantees {
+		key, err := encryptionUtils.ParsePublicKeyFromHex(grantee)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grantee %s: %w", grantee, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 func (i *index) getStamp() *postage.StampIssuer {
 	stamps := i.bl.GetUsableBatches()
 
@@ -0,0 +1,404 @@
+package screens
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+/*
+Group Content Keys
+
+Each group has a symmetric content key on top of ACT. The key is wrapped for
+every grantee public key (and the admin) with EncryptData and the wrapped keys
+are published as a bundle on Swarm, next to the EGL and history reference.
+
+When a member is removed the content key rotates: a new key with the next
+epoch is generated and wrapped only for the remaining members. Earlier keys are
+sealed under the new key inside the bundle, so current members can still read
+old posts while revoked members cannot read anything published after their
+removal, even if they kept old ACT lookups.
+
+A member added later is wrapped into a new bundle, but files uploaded before
+point at the bundle of their time, which has no slot for them. The admin sends
+the new bundle's reference to added members as a group keys payload, and the
+keys they unwrap from it open the earlier files too.
+
+A node keeps its own key chain apart from the keys it unwraps from bundles of
+other admins. Only its own chain is rotated, sealed to and published, the
+received keys are only tried when opening content. A bundle pushed by anyone
+can then at most add keys this node reads with, never the key it uploads
+under.
+
+Content sealed with a group key uses the envelope format with algorithm
+AlgGroupKeyAESGCM. The fingerprint field holds the key ID and the body starts
+with the Swarm reference of the bundle the key can be recovered from.
+*/
+
+const (
+	// AlgGroupKeyAESGCM is AES-256-GCM under a group content key
+	AlgGroupKeyAESGCM byte = 0x03
+
+	groupKeySize     = 32
+	bundleRefSize    = swarm.HashSize
+	groupKeysPrefKey = "groupKeys"
+	groupKeyRefKey   = "groupKeyBundleRef"
+	// receivedGroupKeysPrefKey holds the keys of other admins' bundles
+	receivedGroupKeysPrefKey = "receivedGroupKeys"
+)
+
+var errUnknownGroupKey = errors.New("group key not available")
+
+// groupKey is one epoch of a group's content key
+type groupKey struct {
+	Epoch uint32 `json:"epoch"`
+	Key   []byte `json:"key"`
+}
+
+// id identifies the key inside envelopes without revealing it
+func (g *groupKey) id() [fingerprintSize]byte {
+	var id [fingerprintSize]byte
+	copy(id[:], crypto.Keccak256([]byte("ACTivate group key"), g.Key))
+	return id
+}
+
+// newGroupKey generates the content key following the given epoch's key
+func newGroupKey(previous *groupKey) (*groupKey, error) {
+	g := &groupKey{Key: make([]byte, groupKeySize)}
+	if previous != nil {
+		g.Epoch = previous.Epoch + 1
+	}
+	if _, err := rand.Read(g.Key); err != nil {
+		return nil, fmt.Errorf("failed to generate group key: %w", err)
+	}
+	return g, nil
+}
+
+// groupKeyBundle is the wrapped-key bundle stored on Swarm
+type groupKeyBundle struct {
+	Epoch uint32 `json:"epoch"`
+	// Slots holds the current key encrypted to each member, the recipient is
+	// identified by the fingerprint in the envelope header
+	Slots [][]byte `json:"slots"`
+	// Previous holds the earlier keys sealed under the current key
+	Previous []byte `json:"previous,omitempty"`
+}
+
+// newGroupKeyBundle wraps the current key for every member and seals the
+// earlier keys under it
+func (e *EncryptionUtils) newGroupKeyBundle(current *groupKey, previous []*groupKey, members []*ecdsa.PublicKey) (*groupKeyBundle, error) {
+	if len(members) == 0 {
+		return nil, errors.New("group key bundle needs at least one member")
+	}
+
+	currentData, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &groupKeyBundle{Epoch: current.Epoch}
+	for _, member := range members {
+		slot, err := e.EncryptData(currentData, member)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap group key: %w", err)
+		}
+		b.Slots = append(b.Slots, slot)
+	}
+
+	if len(previous) > 0 {
+		previousData, err := json.Marshal(previous)
+		if err != nil {
+			return nil, err
+		}
+		b.Previous, err = e.sealWithGroupKey(rand.Reader, previousData, current, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal previous group keys: %w", err)
+		}
+	}
+
+	return b, nil
+}
+
+// open unwraps the current and all earlier group keys with a member's private key
+func (b *groupKeyBundle) open(e *EncryptionUtils, privateKey *ecdsa.PrivateKey) ([]*groupKey, error) {
+	fingerprint := keyFingerprint(&privateKey.PublicKey)
+	for _, slot := range b.Slots {
+		h, _, err := parseEnvelopeHeader(slot)
+		if err != nil || h.fingerprint != fingerprint {
+			continue
+		}
+
+		currentData, err := e.DecryptData(slot, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap group key: %w", err)
+		}
+		current := &groupKey{}
+		if err := json.Unmarshal(currentData, current); err != nil {
+			return nil, fmt.Errorf("invalid group key: %w", err)
+		}
+		if current.Epoch != b.Epoch {
+			return nil, fmt.Errorf("group key epoch %d does not match bundle epoch %d", current.Epoch, b.Epoch)
+		}
+
+		keys := []*groupKey{current}
+		if len(b.Previous) > 0 {
+			previousData, err := e.openWithGroupKeys(b.Previous, keys)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open previous group keys: %w", err)
+			}
+			var previous []*groupKey
+			if err := json.Unmarshal(previousData, &previous); err != nil {
+				return nil, fmt.Errorf("invalid previous group keys: %w", err)
+			}
+			keys = append(keys, previous...)
+		}
+		return keys, nil
+	}
+
+	return nil, errNoKeySlot
+}
+
+// sealWithGroupKey encrypts data under a group key. bundleRef, if given, tells
+// readers where the key can be recovered from.
+func (e *EncryptionUtils) sealWithGroupKey(rnd io.Reader, data []byte, g *groupKey, bundleRef []byte) ([]byte, error) {
+	h := &envelopeHeader{
+		version:     e.envelopeVersion(),
+		algorithm:   AlgGroupKeyAESGCM,
+		fingerprint: g.id(),
+	}
+	if _, err := io.ReadFull(rnd, h.nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := newAESGCM(g.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := make([]byte, bundleRefSize)
+	copy(ref, bundleRef)
+	prefix := append(h.marshal(), ref...)
+	return aead.Seal(prefix, h.nonce[:], e.sealPlaintext(h.version, data), prefix), nil
+}
+
+// openWithGroupKeys decrypts data sealed with any of the given group keys
+func (e *EncryptionUtils) openWithGroupKeys(data []byte, keys []*groupKey) ([]byte, error) {
+	h, _, err := parseEnvelopeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.algorithm != AlgGroupKeyAESGCM {
+		return nil, fmt.Errorf("%w: 0x%02x", errEnvelopeAlgorithm, h.algorithm)
+	}
+
+	prefixLen := envelopeHeaderSize + bundleRefSize
+	if len(data) < prefixLen+eciesTagSize {
+		return nil, errCiphertextTooShort
+	}
+
+	for _, g := range keys {
+		if g.id() != h.fingerprint {
+			continue
+		}
+		aead, err := newAESGCM(g.Key)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := aead.Open(nil, h.nonce[:], data[prefixLen:], data[:prefixLen])
+		if err != nil {
+			return nil, errDecryptionFailed
+		}
+		return openPlaintext(h.version, plaintext)
+	}
+
+	return nil, errUnknownGroupKey
+}
+
+// groupKeyBundleRef returns the bundle reference carried by group-sealed data
+func groupKeyBundleRef(data []byte) ([]byte, error) {
+	if len(data) < envelopeHeaderSize+bundleRefSize || data[2] != AlgGroupKeyAESGCM {
+		return nil, errCiphertextTooShort
+	}
+	return data[envelopeHeaderSize : envelopeHeaderSize+bundleRefSize], nil
+}
+
+// loadGroupKeyChain returns this node's own group keys, newest first. Only
+// these are rotated and published. The caller holds groupKeysMu.
+func (i *index) loadGroupKeyChain() ([]*groupKey, error) {
+	var keys []*groupKey
+	if err := i.loadSealedPreference(groupKeysPrefKey, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// loadReceivedGroupKeys returns the keys unwrapped from other admins'
+// bundles by bundle reference. They only open content, they are never sealed
+// to or published for this node's members. The caller holds groupKeysMu.
+func (i *index) loadReceivedGroupKeys() (map[string][]*groupKey, error) {
+	received := map[string][]*groupKey{}
+	if err := i.loadSealedPreference(receivedGroupKeysPrefKey, &received); err != nil {
+		return nil, err
+	}
+	return received, nil
+}
+
+// storeReceivedGroupKeys keeps the keys unwrapped from a bundle
+func (i *index) storeReceivedGroupKeys(bundleRef []byte, keys []*groupKey) error {
+	i.groupKeysMu.Lock()
+	defer i.groupKeysMu.Unlock()
+
+	received, err := i.loadReceivedGroupKeys()
+	if err != nil {
+		return err
+	}
+	received[hex.EncodeToString(bundleRef)] = keys
+	return i.storeSealedPreference(receivedGroupKeysPrefKey, received)
+}
+
+// openingGroupKeys returns every group key content can be opened with, this
+// node's own followed by the received ones
+func (i *index) openingGroupKeys() ([]*groupKey, error) {
+	i.groupKeysMu.Lock()
+	defer i.groupKeysMu.Unlock()
+
+	keys, err := i.loadGroupKeyChain()
+	if err != nil {
+		return nil, err
+	}
+	received, err := i.loadReceivedGroupKeys()
+	if err != nil {
+		return keys, err
+	}
+	for _, bundleKeys := range received {
+		keys = append(keys, bundleKeys...)
+	}
+	return keys, nil
+}
+
+// publishGroupKeyBundle wraps the group content key for the admin and every
+// grantee and uploads the bundle. With rotate set a new key epoch is started,
+// otherwise the current key is re-wrapped for the new member list.
+func (i *index) publishGroupKeyBundle(ctx context.Context, batchHex string, rotate bool) (swarm.Address, error) {
+	// held over the upload, so two publishes cannot both rotate the chain
+	i.groupKeysMu.Lock()
+	defer i.groupKeysMu.Unlock()
+
+	keys, err := i.loadGroupKeyChain()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	var current *groupKey
+	if len(keys) > 0 {
+		current = keys[0]
+	}
+	if current == nil || rotate {
+		current, err = newGroupKey(current)
+		if err != nil {
+			return swarm.ZeroAddress, err
+		}
+		keys = append([]*groupKey{current}, keys...)
+	}
+
+	members, err := i.fileRecipients(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	encryptionUtils := &EncryptionUtils{}
+	bundle, err := encryptionUtils.newGroupKeyBundle(current, keys[1:], members)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	ref, _, err := i.bl.AddBytes(ctx, batchHex, false, swarm.ZeroAddress, false, 0, bytes.NewReader(data))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("failed to upload group key bundle: %w", err)
+	}
+
+	if err := i.storeSealedPreference(groupKeysPrefKey, keys); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	i.setPreference(groupKeyRefKey, ref.String())
+	i.logger.Log(fmt.Sprintf("Published group key bundle epoch %d for %d members: %s", current.Epoch, len(members), ref.String()))
+	return ref, nil
+}
+
+// shareGroupKeyBundle sends the reference of a group key bundle to members,
+// so they can read files uploaded before they were added
+func (i *index) shareGroupKeyBundle(ctx context.Context, bundleRef swarm.Address, members []*ecdsa.PublicKey) error {
+	if i.contractSvc == nil {
+		return errors.New("contract service not initialized")
+	}
+	payload := sharePayload{Kind: payloadKindGroupKeys, Reference: bundleRef.Bytes()}
+	_, err := i.notifyMembers(ctx, payload, members)
+	return err
+}
+
+// currentGroupKey returns the newest key of this node's own chain and the
+// reference of its bundle
+func (i *index) currentGroupKey() (*groupKey, []byte, error) {
+	i.groupKeysMu.Lock()
+	defer i.groupKeysMu.Unlock()
+
+	refHex := i.getPreferenceString(groupKeyRefKey)
+	if refHex == "" {
+		return nil, nil, nil
+	}
+	ref, err := hex.DecodeString(refHex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid group key bundle reference: %w", err)
+	}
+
+	keys, err := i.loadGroupKeyChain()
+	if err != nil || len(keys) == 0 {
+		return nil, nil, err
+	}
+	return keys[0], ref, nil
+}
+
+// fetchGroupKeys downloads a group key bundle and unwraps it with the node
+// key. The keys are kept apart from this node's own chain.
+func (i *index) fetchGroupKeys(ctx context.Context, bundleRef []byte) ([]*groupKey, error) {
+	key, err := i.nodePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := i.bl.GetBytes(ctx, swarm.NewAddress(bundleRef), nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download group key bundle: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &groupKeyBundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("invalid group key bundle: %w", err)
+	}
+
+	keys, err := bundle.open(&EncryptionUtils{}, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.storeReceivedGroupKeys(bundleRef, keys); err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to store group keys: %v", err))
+	}
+	return keys, nil
+}
@@ -0,0 +1,287 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustNewGroupKey(t *testing.T, previous *groupKey) *groupKey {
+	t.Helper()
+	g, err := newGroupKey(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGroupKeyBundleRoundTrip(t *testing.T) {
+	e := &EncryptionUtils{}
+	admin, alice := mustGenerateKey(t), mustGenerateKey(t)
+	current := mustNewGroupKey(t, nil)
+
+	bundle, err := e.newGroupKeyBundle(current, nil, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, member := range []*ecdsa.PrivateKey{admin, alice} {
+		keys, err := bundle.open(e, member)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 1 || !bytes.Equal(keys[0].Key, current.Key) || keys[0].Epoch != 0 {
+			t.Fatalf("unexpected keys %+v", keys)
+		}
+	}
+
+	if _, err := bundle.open(e, mustGenerateKey(t)); !errors.Is(err, errNoKeySlot) {
+		t.Fatalf("expected errNoKeySlot for outsider, got %v", err)
+	}
+}
+
+func TestGroupKeyRotationExcludesRevokedMember(t *testing.T) {
+	e := &EncryptionUtils{}
+	admin, alice, bob := mustGenerateKey(t), mustGenerateKey(t), mustGenerateKey(t)
+
+	epoch0 := mustNewGroupKey(t, nil)
+	oldBundle, err := e.newGroupKeyBundle(epoch0, nil, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey, &bob.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldPost, err := e.sealWithGroupKey(rand.Reader, []byte("before revocation"), epoch0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bob is revoked
+	epoch1 := mustNewGroupKey(t, epoch0)
+	if epoch1.Epoch != 1 {
+		t.Fatalf("expected epoch 1, got %d", epoch1.Epoch)
+	}
+	newBundle, err := e.newGroupKeyBundle(epoch1, []*groupKey{epoch0}, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPost, err := e.sealWithGroupKey(rand.Reader, []byte("after revocation"), epoch1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliceKeys, err := newBundle.open(e, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliceKeys) != 2 {
+		t.Fatalf("expected current and previous key, got %d", len(aliceKeys))
+	}
+	for _, post := range []struct {
+		sealed []byte
+		want   string
+	}{
+		{oldPost, "before revocation"},
+		{newPost, "after revocation"},
+	} {
+		got, err := e.openWithGroupKeys(post.sealed, aliceKeys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != post.want {
+			t.Fatalf("got %q, want %q", got, post.want)
+		}
+	}
+
+	if _, err := newBundle.open(e, bob); !errors.Is(err, errNoKeySlot) {
+		t.Fatalf("revoked member opened new bundle: %v", err)
+	}
+	bobKeys, err := oldBundle.open(e, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.openWithGroupKeys(oldPost, bobKeys); err != nil {
+		t.Fatalf("revoked member lost access to old post: %v", err)
+	}
+	if _, err := e.openWithGroupKeys(newPost, bobKeys); !errors.Is(err, errUnknownGroupKey) {
+		t.Fatalf("revoked member read new post: %v", err)
+	}
+}
+
+func TestSealWithGroupKeyTamper(t *testing.T) {
+	e := &EncryptionUtils{}
+	g := mustNewGroupKey(t, nil)
+	bundleRef := bytes.Repeat([]byte{0x42}, bundleRefSize)
+
+	sealed, err := e.sealWithGroupKey(rand.Reader, []byte("payload"), g, bundleRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := groupKeyBundleRef(sealed)
+	if err != nil || !bytes.Equal(ref, bundleRef) {
+		t.Fatalf("bundle reference not recovered: %x, %v", ref, err)
+	}
+
+	for pos := 0; pos < len(sealed); pos++ {
+		if pos >= 3 && pos < 3+fingerprintSize {
+			// a changed key ID no longer matches any key
+			continue
+		}
+		tampered := append([]byte{}, sealed...)
+		tampered[pos] ^= 0x01
+		if _, err := e.openWithGroupKeys(tampered, []*groupKey{g}); err == nil {
+			t.Fatalf("tampering byte %d went undetected", pos)
+		}
+	}
+}
+
+func TestGroupStreamFetchesBundle(t *testing.T) {
+	e := &EncryptionUtils{}
+	admin, alice := mustGenerateKey(t), mustGenerateKey(t)
+	g := mustNewGroupKey(t, nil)
+	bundle, err := e.newGroupKeyBundle(g, nil, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundleRef := bytes.Repeat([]byte{0x07}, bundleRefSize)
+
+	data := randomBytes(t, 3*defaultSegmentSize+5)
+	var buf bytes.Buffer
+	w, err := e.EncryptGroupStream(&buf, g, bundleRef, []*ecdsa.PublicKey{&admin.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched := 0
+	keys := &keyring{
+		privateKey: alice,
+		fetchGroupKeys: func(ref []byte) ([]*groupKey, error) {
+			fetched++
+			if !bytes.Equal(ref, bundleRef) {
+				t.Fatalf("unexpected bundle reference %x", ref)
+			}
+			return bundle.open(e, alice)
+		},
+	}
+	r, err := e.decryptStream(bytes.NewReader(buf.Bytes()), keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) || fetched != 1 {
+		t.Fatalf("group stream round trip failed (fetched %d)", fetched)
+	}
+
+	// the uploader decrypts with its own key slot
+	if got, err := decryptTestStream(buf.Bytes(), admin); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("uploader could not decrypt: %v", err)
+	}
+
+	outsider := &keyring{
+		privateKey: mustGenerateKey(t),
+		fetchGroupKeys: func([]byte) ([]*groupKey, error) {
+			return nil, errNoKeySlot
+		},
+	}
+	if _, err := e.decryptStream(bytes.NewReader(buf.Bytes()), outsider); !errors.Is(err, errNoKeySlot) {
+		t.Fatalf("expected errNoKeySlot, got %v", err)
+	}
+}
+
+func TestGroupKeysForLateMember(t *testing.T) {
+	e := &EncryptionUtils{}
+	admin, alice, carol := mustGenerateKey(t), mustGenerateKey(t), mustGenerateKey(t)
+	g := mustNewGroupKey(t, nil)
+	before, err := e.newGroupKeyBundle(g, nil, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforeRef := bytes.Repeat([]byte{0x01}, bundleRefSize)
+
+	// a file uploaded before carol was added
+	data := randomBytes(t, 2*defaultSegmentSize)
+	var buf bytes.Buffer
+	w, err := e.EncryptGroupStream(&buf, g, beforeRef, []*ecdsa.PublicKey{&admin.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fetchBefore := func([]byte) ([]*groupKey, error) {
+		return before.open(e, carol)
+	}
+	if _, err := e.decryptStream(bytes.NewReader(buf.Bytes()), &keyring{privateKey: carol, fetchGroupKeys: fetchBefore}); !errors.Is(err, errNoKeySlot) {
+		t.Fatalf("file bundle opened for a later member: %v", err)
+	}
+
+	// adding carol re-wraps the same key, and the bundle she is sent opens the file
+	after, err := e.newGroupKeyBundle(g, nil, []*ecdsa.PublicKey{&admin.PublicKey, &alice.PublicKey, &carol.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := after.open(e, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := e.decryptStream(bytes.NewReader(buf.Bytes()), &keyring{privateKey: carol, groupKeys: keys, fetchGroupKeys: fetchBefore})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("late member could not read the earlier file: %v", err)
+	}
+}
+
+func TestReceivedGroupKeysStayApart(t *testing.T) {
+	i := &index{app: test.NewTempApp(t), nodeConfig: &nodeConfig{}, nodeKey: mustGenerateKey(t), logger: &logger{}}
+	own := mustNewGroupKey(t, nil)
+	if err := i.storeSealedPreference(groupKeysPrefKey, []*groupKey{own}); err != nil {
+		t.Fatal(err)
+	}
+	i.setPreference(groupKeyRefKey, hex.EncodeToString(bytes.Repeat([]byte{0x01}, bundleRefSize)))
+
+	// a key pushed by another admin, or anyone, opens content but is not uploaded under
+	foreign := mustNewGroupKey(t, nil)
+	if err := i.storeReceivedGroupKeys(bytes.Repeat([]byte{0x02}, bundleRefSize), []*groupKey{foreign}); err != nil {
+		t.Fatal(err)
+	}
+	current, _, err := i.currentGroupKey()
+	if err != nil || current.id() != own.id() {
+		t.Fatalf("current key %v, %v, want the own one", current, err)
+	}
+	chain, err := i.loadGroupKeyChain()
+	if err != nil || len(chain) != 1 {
+		t.Fatalf("own chain has %d keys, %v", len(chain), err)
+	}
+	keys, err := i.openingGroupKeys()
+	if err != nil || len(keys) != 2 || keys[0].id() != own.id() || keys[1].id() != foreign.id() {
+		t.Fatalf("opening with %d keys, %v", len(keys), err)
+	}
+}
@@ -0,0 +1,261 @@
+package screens
+
+/*
+Group Registry
+
+The GroupRegistry contract is where members of a group find its current ACT
+state. The admin, the node that publishes the group's ACT, records the history
+reference after every grantee change, so members always download with the
+latest history instead of one passed around out of band.
+
+A group's ID is derived from the admin's address and a salt, so members only
+need the admin's public key to find it.
+*/
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+	"github.com/ethersphere/bee/v2/pkg/transaction"
+)
+
+// defaultGroupSalt is the salt of the group the app publishes, a node has one
+var defaultGroupSalt = common.Hash{}
+
+const unknownGroupReason = "GroupRegistry: unknown group"
+
+var (
+	errGroupNotFound = errors.New("group not found in the registry")
+	// ErrNotGroupUpdated is returned for logs of other events
+	ErrNotGroupUpdated = errors.New("log is not a GroupUpdated event")
+)
+
+type GroupRegistryInterface interface {
+	CreateGroup(ctx context.Context, salt common.Hash, adminKey *ecdsa.PublicKey, history, granteeList swarm.Address) (common.Hash, *types.Receipt, error)
+	UpdateGroup(ctx context.Context, id common.Hash, history, granteeList swarm.Address) (*types.Receipt, error)
+	TransferAdmin(ctx context.Context, id common.Hash, newAdmin *ecdsa.PublicKey) (*types.Receipt, error)
+	Group(ctx context.Context, caller ethereum.ContractCaller, id common.Hash) (*GroupState, error)
+}
+
+// GroupState is a group as the registry has it
+type GroupState struct {
+	ID       common.Hash
+	Admin    common.Address
+	AdminKey *ecdsa.PublicKey
+	// Version counts the updates, the first state is version 1
+	Version     uint64
+	History     swarm.Address
+	GranteeList swarm.Address
+}
+
+// GroupUpdatedEvent is a decoded GroupUpdated log
+type GroupUpdatedEvent struct {
+	ID          common.Hash
+	Version     uint64
+	History     swarm.Address
+	GranteeList swarm.Address
+	Raw         types.Log
+}
+
+// groupID is the ID of the group admin creates with salt, as the contract
+// computes it
+func groupID(admin common.Address, salt common.Hash) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(admin.Bytes(), common.HashLength), salt.Bytes())
+}
+
+// groupKeyBytes is the 64-byte uncompressed key the contract stores
+func groupKeyBytes(pub *ecdsa.PublicKey) []byte {
+	return crypto.FromECDSAPub(pub)[1:]
+}
+
+type groupRegistry struct {
+	contractTransactor
+}
+
+func NewGroupRegistry(
+	owner common.Address,
+	registryAddress common.Address,
+	registryABI abi.ABI,
+	transactionService transaction.Service,
+	setGasLimit bool,
+	journal *txJournal,
+) GroupRegistryInterface {
+	return &groupRegistry{
+		contractTransactor: newContractTransactor(owner, registryAddress, registryABI, transactionService, setGasLimit, journal),
+	}
+}
+
+// CreateGroup publishes a group with the node as admin, returning its ID.
+// adminKey must be the key of the node's address.
+func (c *groupRegistry) CreateGroup(ctx context.Context, salt common.Hash, adminKey *ecdsa.PublicKey, history, granteeList swarm.Address) (common.Hash, *types.Receipt, error) {
+	if crypto.PubkeyToAddress(*adminKey) != c.owner {
+		return common.Hash{}, nil, fmt.Errorf("create group: admin key is not the key of %s", c.owner.Hex())
+	}
+	historyRef, err := groupHistoryRef(history)
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("create group: %w", err)
+	}
+
+	callData, err := c.abi.Pack("createGroup", [32]byte(salt), groupKeyBytes(adminKey), historyRef, granteeList.Bytes())
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	receipt, err := c.sendTransaction(ctx, callData, "createGroup")
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("create group: %w", err)
+	}
+	return groupID(c.owner, salt), receipt, nil
+}
+
+// UpdateGroup publishes the group's state after a grantee change, only the
+// admin can update a group
+func (c *groupRegistry) UpdateGroup(ctx context.Context, id common.Hash, history, granteeList swarm.Address) (*types.Receipt, error) {
+	historyRef, err := groupHistoryRef(history)
+	if err != nil {
+		return nil, fmt.Errorf("update group: %w", err)
+	}
+
+	callData, err := c.abi.Pack("updateGroup", [32]byte(id), historyRef, granteeList.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.sendTransaction(ctx, callData, "updateGroup")
+	if err != nil {
+		return nil, fmt.Errorf("update group: %w", err)
+	}
+	return receipt, nil
+}
+
+// TransferAdmin hands the group to another node, which publishes its next state
+func (c *groupRegistry) TransferAdmin(ctx context.Context, id common.Hash, newAdmin *ecdsa.PublicKey) (*types.Receipt, error) {
+	callData, err := c.abi.Pack("transferAdmin", [32]byte(id), groupKeyBytes(newAdmin))
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.sendTransaction(ctx, callData, "transferAdmin")
+	if err != nil {
+		return nil, fmt.Errorf("transfer group admin: %w", err)
+	}
+	return receipt, nil
+}
+
+// Group reads the group's current state, errGroupNotFound if nobody created it
+func (c *groupRegistry) Group(ctx context.Context, caller ethereum.ContractCaller, id common.Hash) (*GroupState, error) {
+	if caller == nil {
+		return nil, errors.New("ethclient.Client is nil")
+	}
+	callData, err := c.abi.Pack("getGroup", [32]byte(id))
+	if err != nil {
+		return nil, err
+	}
+	output, err := caller.CallContract(ctx, ethereum.CallMsg{To: &c.address, Data: callData}, nil)
+	if err != nil {
+		if reason, ok := revertReason(err); ok && reason == unknownGroupReason {
+			return nil, errGroupNotFound
+		}
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+
+	values, err := c.abi.Unpack("getGroup", output)
+	if err != nil {
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+	admin, adminOK := values[0].(common.Address)
+	adminKey, adminKeyOK := values[1].([]byte)
+	version, versionOK := values[2].(uint64)
+	historyRef, historyOK := values[3].([32]byte)
+	granteeList, granteeListOK := values[4].([]byte)
+	if !adminOK || !adminKeyOK || !versionOK || !historyOK || !granteeListOK {
+		return nil, fmt.Errorf("get group: unexpected field types %T, %T, %T, %T, %T", values[0], values[1], values[2], values[3], values[4])
+	}
+	pub, err := crypto.UnmarshalPubkey(append([]byte{4}, adminKey...))
+	if err != nil {
+		return nil, fmt.Errorf("get group: invalid admin key: %w", err)
+	}
+
+	return &GroupState{
+		ID:          id,
+		Admin:       admin,
+		AdminKey:    pub,
+		Version:     version,
+		History:     swarm.NewAddress(historyRef[:]),
+		GranteeList: swarm.NewAddress(granteeList),
+	}, nil
+}
+
+// groupHistoryRef is the history reference as the contract's bytes32, which
+// cannot be zero
+func groupHistoryRef(history swarm.Address) ([32]byte, error) {
+	var ref [32]byte
+	if len(history.Bytes()) != swarm.HashSize {
+		return ref, fmt.Errorf("invalid history reference %q", history)
+	}
+	copy(ref[:], history.Bytes())
+	if ref == [32]byte{} {
+		return ref, errors.New("history reference cannot be zero")
+	}
+	return ref, nil
+}
+
+var groupUpdatedEvent = sync.OnceValue(func() abi.Event {
+	parsedABI, _ := ParseGroupRegistryABI()
+	return parsedABI.Events["GroupUpdated"]
+})
+
+// ParseGroupUpdated decodes a GroupUpdated log
+func ParseGroupUpdated(l types.Log) (*GroupUpdatedEvent, error) {
+	event := groupUpdatedEvent()
+	if len(l.Topics) == 0 || l.Topics[0] != event.ID {
+		return nil, ErrNotGroupUpdated
+	}
+	// the signature, then the indexed group ID
+	if len(l.Topics) != 2 {
+		return nil, fmt.Errorf("malformed GroupUpdated event: expected 2 topics, got %d", len(l.Topics))
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed GroupUpdated event: %w", err)
+	}
+	version, versionOK := values[0].(uint64)
+	historyRef, historyOK := values[1].([32]byte)
+	granteeList, granteeListOK := values[2].([]byte)
+	if !versionOK || !historyOK || !granteeListOK {
+		return nil, fmt.Errorf("malformed GroupUpdated event: unexpected field types %T, %T, %T", values[0], values[1], values[2])
+	}
+	return &GroupUpdatedEvent{
+		ID:          l.Topics[1],
+		Version:     version,
+		History:     swarm.NewAddress(historyRef[:]),
+		GranteeList: swarm.NewAddress(granteeList),
+		Raw:         l,
+	}, nil
+}
+
+// revertReason is the reason string of a reverted call, if the node returned
+// the revert data
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return "", false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok || !strings.HasPrefix(data, "0x") {
+		return "", false
+	}
+	reason, uErr := abi.UnpackRevert(common.FromHex(data))
+	if uErr != nil {
+		return "", false
+	}
+	return reason, true
+}
@@ -0,0 +1,128 @@
+package screens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+func (i *index) showGroupRegistryCard() *widget.Card {
+	if i.groupRegistry == nil {
+		return widget.NewCard("Group Registry", fmt.Sprintf("No group registry on %s", i.network().Name), nil)
+	}
+	if i.contractClient() == nil {
+		return widget.NewCard("Group Registry", "Not connected to the RPC endpoint", nil)
+	}
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	publishButton := widget.NewButton("Publish my group", nil)
+	publishButton.OnTapped = func() {
+		publishButton.Disable()
+		statusLabel.SetText("Publishing group state...")
+		go func() {
+			defer publishButton.Enable()
+			state, err := i.publishGroupState(context.Background())
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error publishing group state: %v", err))
+				i.showError(err)
+				statusLabel.SetText("Failed to publish the group state.")
+				return
+			}
+			statusLabel.SetText(fmt.Sprintf("Group published, version %d", state.Version))
+		}()
+	}
+
+	adminEntry := widget.NewEntry()
+	adminEntry.SetPlaceHolder("Admin public key or contact name")
+	result := container.NewVBox()
+	lookupButton := widget.NewButton("Find latest history", func() {
+		result.RemoveAll()
+		admin, err := i.groupAdminKey(strings.TrimSpace(adminEntry.Text))
+		if err != nil {
+			i.showError(err)
+			return
+		}
+		go func() {
+			state, err := i.groupRegistry.Group(context.Background(), i.contractClient(), groupID(crypto.PubkeyToAddress(*admin), defaultGroupSalt))
+			if errors.Is(err, errGroupNotFound) {
+				result.Add(widget.NewLabel("The admin has not published a group yet."))
+				return
+			}
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error looking up group: %v", err))
+				i.showError(err)
+				return
+			}
+			result.Add(widget.NewLabel(fmt.Sprintf("Version %d, admin %s", state.Version, displayFingerprint(state.AdminKey))))
+			result.Add(i.copyDialog(fmt.Sprintf("History: %s", shortenHashOrAddress(state.History.String())), state.History.String()))
+		}()
+	})
+
+	return widget.NewCard("Group Registry", "Where your group finds its latest ACT history",
+		container.NewVBox(
+			publishButton,
+			statusLabel,
+			widget.NewSeparator(),
+			adminEntry,
+			lookupButton,
+			result,
+		))
+}
+
+// publishGroupState records the node's current grantee list and history in
+// the registry, creating the node's group on the first publish
+func (i *index) publishGroupState(ctx context.Context) (*GroupState, error) {
+	history, err := swarm.ParseHexAddress(i.getPreferenceString(historyRefPrefKey))
+	if err != nil {
+		return nil, fmt.Errorf("no history reference to publish, add grantees first: %w", err)
+	}
+	granteeList := i.storedEglRef()
+	admin := i.bl.PublicKey()
+	id := groupID(crypto.PubkeyToAddress(*admin), defaultGroupSalt)
+
+	current, err := i.groupRegistry.Group(ctx, i.contractClient(), id)
+	switch {
+	case errors.Is(err, errGroupNotFound):
+		if _, _, err := i.groupRegistry.CreateGroup(ctx, defaultGroupSalt, admin, history, granteeList); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case current.History.Equal(history) && current.GranteeList.Equal(granteeList):
+		return current, nil
+	default:
+		if _, err := i.groupRegistry.UpdateGroup(ctx, id, history, granteeList); err != nil {
+			return nil, err
+		}
+	}
+	i.logger.Log(fmt.Sprintf("Published group %s with history %s", id.Hex(), history))
+	return i.groupRegistry.Group(ctx, i.contractClient(), id)
+}
+
+// groupAdminKey reads the admin's key from a contact name or a hex key
+func (i *index) groupAdminKey(input string) (*ecdsa.PublicKey, error) {
+	if input == "" {
+		return nil, errors.New("enter the admin's public key or contact name")
+	}
+	if contacts, err := i.loadContacts(); err == nil {
+		if c := contacts.byName(input); c != nil {
+			return c.key()
+		}
+	}
+	encryptionUtils := &EncryptionUtils{}
+	pub, err := encryptionUtils.ParsePublicKeyFromHex(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin public key: %w", err)
+	}
+	return pub, nil
+}
@@ -0,0 +1,186 @@
+package screens
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// testdata/GroupRegistry.bin is the creation code of
+// contract/contracts/GroupRegistry.sol, built like testdata/DataContract.bin.
+const groupRegistryBinPath = "testdata/GroupRegistry.bin"
+
+type groupRegistryHarness struct {
+	chain   *evmChain
+	address common.Address
+	admin   *ecdsa.PrivateKey
+	member  *ecdsa.PrivateKey
+	// registries sending from the admin and the member
+	adminRegistry  *groupRegistry
+	memberRegistry *groupRegistry
+}
+
+func newGroupRegistryHarness(t *testing.T) *groupRegistryHarness {
+	t.Helper()
+	admin, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	member, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := newEVMChain(types.GenesisAlloc{
+		crypto.PubkeyToAddress(admin.PublicKey):  {Balance: big.NewInt(params.Ether)},
+		crypto.PubkeyToAddress(member.PublicKey): {Balance: big.NewInt(params.Ether)},
+	})
+	address := deployContract(t, chain, admin, groupRegistryBinPath)
+
+	registryABI, err := ParseGroupRegistryABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := func(key *ecdsa.PrivateKey) *groupRegistry {
+		return NewGroupRegistry(crypto.PubkeyToAddress(key.PublicKey), address, registryABI, newTestTxService(t, chain, key), false, nil).(*groupRegistry)
+	}
+	return &groupRegistryHarness{
+		chain:          chain,
+		address:        address,
+		admin:          admin,
+		member:         member,
+		adminRegistry:  registry(admin),
+		memberRegistry: registry(member),
+	}
+}
+
+func testHistory(b byte) swarm.Address {
+	return swarm.NewAddress(bytes.Repeat([]byte{b}, swarm.HashSize))
+}
+
+func TestGroupRegistryOnChain(t *testing.T) {
+	h := newGroupRegistryHarness(t)
+	ctx := context.Background()
+	adminAddress := crypto.PubkeyToAddress(h.admin.PublicKey)
+	granteeList := swarm.NewAddress(bytes.Repeat([]byte{7}, 2*swarm.HashSize))
+
+	id := groupID(adminAddress, defaultGroupSalt)
+	if _, err := h.memberRegistry.Group(ctx, h.chain, id); !errors.Is(err, errGroupNotFound) {
+		t.Fatalf("group before creation: %v", err)
+	}
+
+	created, _, err := h.adminRegistry.CreateGroup(ctx, defaultGroupSalt, &h.admin.PublicKey, testHistory(1), granteeList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != id {
+		t.Fatalf("created %s, want %s", created.Hex(), id.Hex())
+	}
+	// the contract derives the same ID
+	callData, _ := h.adminRegistry.abi.Pack("groupId", adminAddress, [32]byte(defaultGroupSalt))
+	if output, err := h.chain.CallContract(ctx, ethereum.CallMsg{To: &h.address, Data: callData}, nil); err != nil || common.BytesToHash(output) != id {
+		t.Fatalf("contract group id %x, %v", output, err)
+	}
+
+	// a member finds the group from the admin's key alone
+	state, err := h.memberRegistry.Group(ctx, h.chain, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Admin != adminAddress || !state.AdminKey.Equal(&h.admin.PublicKey) || state.Version != 1 ||
+		!state.History.Equal(testHistory(1)) || !state.GranteeList.Equal(granteeList) {
+		t.Fatalf("got group %+v", state)
+	}
+
+	if _, err := h.adminRegistry.UpdateGroup(ctx, id, testHistory(2), granteeList); err != nil {
+		t.Fatal(err)
+	}
+	state, err = h.memberRegistry.Group(ctx, h.chain, id)
+	if err != nil || state.Version != 2 || !state.History.Equal(testHistory(2)) {
+		t.Fatalf("after update got %+v, %v", state, err)
+	}
+
+	// every update is announced
+	logs, err := h.chain.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{h.address},
+		Topics:    [][]common.Hash{{groupUpdatedEvent().ID}, {id}},
+	})
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("got %d GroupUpdated logs, %v", len(logs), err)
+	}
+	event, err := ParseGroupUpdated(logs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != id || event.Version != 2 || !event.History.Equal(testHistory(2)) || !event.GranteeList.Equal(granteeList) {
+		t.Fatalf("got event %+v", event)
+	}
+}
+
+func TestGroupRegistryOnlyAdmin(t *testing.T) {
+	h := newGroupRegistryHarness(t)
+	ctx := context.Background()
+	id, _, err := h.adminRegistry.CreateGroup(ctx, defaultGroupSalt, &h.admin.PublicKey, testHistory(1), swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.memberRegistry.UpdateGroup(ctx, id, testHistory(2), swarm.ZeroAddress); err == nil || !strings.Contains(err.Error(), "caller is not the admin") {
+		t.Fatalf("member updated the group: %v", err)
+	}
+	if _, err := h.memberRegistry.TransferAdmin(ctx, id, &h.member.PublicKey); err == nil || !strings.Contains(err.Error(), "caller is not the admin") {
+		t.Fatalf("member took the group: %v", err)
+	}
+	// the group is taken, and nobody creates groups under another admin's key
+	if _, _, err := h.adminRegistry.CreateGroup(ctx, defaultGroupSalt, &h.admin.PublicKey, testHistory(2), swarm.ZeroAddress); err == nil || !strings.Contains(err.Error(), "group exists") {
+		t.Fatalf("created the group twice: %v", err)
+	}
+	if _, _, err := h.memberRegistry.CreateGroup(ctx, defaultGroupSalt, &h.admin.PublicKey, testHistory(2), swarm.ZeroAddress); err == nil {
+		t.Fatal("created a group with another node's key")
+	}
+	if _, err := h.adminRegistry.UpdateGroup(ctx, id, swarm.NewAddress(make([]byte, swarm.HashSize)), swarm.ZeroAddress); err == nil {
+		t.Fatal("published a zero history")
+	}
+
+	state, err := h.memberRegistry.Group(ctx, h.chain, id)
+	if err != nil || state.Version != 1 || !state.History.Equal(testHistory(1)) {
+		t.Fatalf("rejected updates changed the group: %+v, %v", state, err)
+	}
+}
+
+func TestGroupRegistryTransferAdmin(t *testing.T) {
+	h := newGroupRegistryHarness(t)
+	ctx := context.Background()
+	id, _, err := h.adminRegistry.CreateGroup(ctx, defaultGroupSalt, &h.admin.PublicKey, testHistory(1), swarm.ZeroAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.adminRegistry.TransferAdmin(ctx, id, &h.member.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	// the group keeps its ID, the new admin publishes and the old one cannot
+	if _, err := h.adminRegistry.UpdateGroup(ctx, id, testHistory(2), swarm.ZeroAddress); err == nil {
+		t.Fatal("previous admin updated the group")
+	}
+	if _, err := h.memberRegistry.UpdateGroup(ctx, id, testHistory(3), swarm.ZeroAddress); err != nil {
+		t.Fatal(err)
+	}
+	state, err := h.adminRegistry.Group(ctx, h.chain, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Admin != crypto.PubkeyToAddress(h.member.PublicKey) || !state.AdminKey.Equal(&h.member.PublicKey) || !state.History.Equal(testHistory(3)) {
+		t.Fatalf("got group %+v", state)
+	}
+}
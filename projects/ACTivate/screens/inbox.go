@@ -0,0 +1,158 @@
+package screens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	inboxPrefKey = "inbox"
+	// maxInboxEntries bounds the stored inbox, the oldest shares are dropped
+	maxInboxEntries = 500
+)
+
+// inboxEntry is a received share notification
+type inboxEntry struct {
+	Log          logID     `json:"log"`
+	Publisher    string    `json:"publisher"`
+	Reference    string    `json:"reference"`
+	History      string    `json:"history"`
+	Verification string    `json:"verification"`
+	Timestamp    time.Time `json:"timestamp"`
+	Read         bool      `json:"read,omitempty"`
+}
+
+// inbox holds the received shares, newest first
+type inbox []*inboxEntry
+
+func (b inbox) unread() int {
+	n := 0
+	for _, e := range b {
+		if !e.Read {
+			n++
+		}
+	}
+	return n
+}
+
+func (b inbox) byLog(id logID) *inboxEntry {
+	for _, e := range b {
+		if e.Log == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (b inbox) byReference(ref string) *inboxEntry {
+	for _, e := range b {
+		if e.Reference == ref {
+			return e
+		}
+	}
+	return nil
+}
+
+// add stores a share unless the same log was stored before
+func (b *inbox) add(e *inboxEntry) bool {
+	if b.byLog(e.Log) != nil {
+		return false
+	}
+	*b = slices.Insert(*b, 0, e)
+	if len(*b) > maxInboxEntries {
+		*b = (*b)[:maxInboxEntries]
+	}
+	return true
+}
+
+// remove drops the share received with the log
+func (b *inbox) remove(id logID) bool {
+	n := len(*b)
+	*b = slices.DeleteFunc(*b, func(e *inboxEntry) bool { return e.Log == id })
+	return len(*b) != n
+}
+
+func (i *index) loadInbox() (inbox, error) {
+	entries := inbox{}
+	stored := i.getPreferenceString(inboxPrefKey)
+	if stored == "" {
+		return entries, nil
+	}
+	if err := json.Unmarshal([]byte(stored), &entries); err != nil {
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+	return entries, nil
+}
+
+func (i *index) storeInbox(entries inbox) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	i.setPreference(inboxPrefKey, string(data))
+	return nil
+}
+
+// updateInbox changes the stored inbox and refreshes the inbox list. The event
+// listener and the UI both write to it, so changes are serialised.
+func (i *index) updateInbox(fn func(entries *inbox) bool) error {
+	i.inboxMu.Lock()
+	entries, err := i.loadInbox()
+	if err == nil && fn(&entries) {
+		err = i.storeInbox(entries)
+	}
+	if err == nil {
+		i.inbox = entries
+	}
+	i.inboxMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if i.inboxList != nil {
+		i.inboxList.Refresh()
+	}
+	if i.inboxCard != nil {
+		i.inboxCard.SetSubTitle(inboxSubtitle(entries))
+	}
+	return nil
+}
+
+func inboxSubtitle(entries inbox) string {
+	return fmt.Sprintf("%d shares, %d unread", len(entries), entries.unread())
+}
+
+// receiveShare files a share notification in the inbox
+func (i *index) receiveShare(vLog types.Log, entry *inboxEntry) {
+	entry.Log = logIDOf(vLog)
+	entry.Timestamp = i.blockTime(vLog)
+	err := i.updateInbox(func(entries *inbox) bool {
+		return entries.add(entry)
+	})
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to store share in the inbox: %v", err))
+		return
+	}
+	i.logger.Log(fmt.Sprintf("Share %s from block %d added to the inbox", shortenHashOrAddress(entry.Reference), vLog.BlockNumber))
+}
+
+// blockTime returns when the log's block was mined, or now if that is unknown
+func (i *index) blockTime(vLog types.Log) time.Time {
+	client := i.contractClient()
+	if client == nil {
+		return time.Now()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	header, err := client.HeaderByHash(ctx, vLog.BlockHash)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to get the time of block %d: %v", vLog.BlockNumber, err))
+		return time.Now()
+	}
+	return time.Unix(int64(header.Time), 0)
+}
@@ -0,0 +1,60 @@
+package screens
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testInboxEntry(block uint64) *inboxEntry {
+	return &inboxEntry{
+		Log:       logID{Block: block, BlockHash: common.Hash{byte(block)}, TxHash: common.Hash{1}},
+		Reference: fmt.Sprintf("%064x", block),
+	}
+}
+
+func TestInbox(t *testing.T) {
+	var entries inbox
+	first, second := testInboxEntry(1), testInboxEntry(2)
+	if !entries.add(first) || !entries.add(second) {
+		t.Fatal("failed to add shares")
+	}
+	if entries.add(testInboxEntry(1)) {
+		t.Fatal("added the same log twice")
+	}
+	if entries[0] != second || entries.unread() != 2 {
+		t.Fatalf("expected newest first with 2 unread, got %d unread", entries.unread())
+	}
+	if entries.byReference(first.Reference) != first {
+		t.Fatal("share not found by reference")
+	}
+
+	first.Read = true
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored inbox
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.unread() != 1 || stored.byLog(first.Log) == nil || !stored.byLog(first.Log).Read {
+		t.Fatal("read state or log id lost in storage")
+	}
+
+	if !stored.remove(second.Log) || stored.remove(second.Log) || len(stored) != 1 {
+		t.Fatalf("removing a share left %d", len(stored))
+	}
+}
+
+func TestInboxDropsOldest(t *testing.T) {
+	var entries inbox
+	for block := range uint64(maxInboxEntries + 1) {
+		entries.add(testInboxEntry(block))
+	}
+	if len(entries) != maxInboxEntries || entries.byLog(testInboxEntry(0).Log) != nil {
+		t.Fatalf("inbox kept %d shares, including the oldest", len(entries))
+	}
+}
@@ -1,10 +1,15 @@
 package screens
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -17,51 +22,39 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 
-	// "github.com/ethereum/go-ethereum/crypto" // Temporarily commented out
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethersphere/bee/v2/pkg/api"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
 	"github.com/ethersphere/bee/v2/pkg/transaction" // For transaction.Service, though might be nil
 )
 
 const (
-	TestnetChainID         = 11155111
-	TestnetNetworkID       = uint64(10)
-	MainnetChainID         = 100
-	MainnetNetworkID       = uint64(1)
-	NativeTokenSymbol      = "xDAI"
-	SwarmTokenSymbol       = "xBZZ"
-	defaultRPC             = "wss://gnosis-mainnet.g.alchemy.com/v2/YtM4LIorMJrGNRWkvAOFWSKTDzhNsCMz"
-	defaultTestRPC         = "https://eth-sepolia.g.alchemy.com/v2/atcICv4EFi9hXKew1D4LvnH36cm5-96S"
-	defaultWelcomeMsg      = "Welcome from ACTivate!"
-	defaultPassword        = "defaultpassword"
-	defaultNatAddress      = ""
-	defaultSwapEnable      = true
-	dataContractAddressHex = "0x242A2174fa8d8586a784aBdB4fF03C3181E96bee"
-	infoLogLevel           = "3"
-	defaultDepth           = "21"
-	defaultAmount          = "500000000"
-	defaultImmutable       = true
-	passwordPrefKey        = "password"
-	welcomeMessagePrefKey  = "welcomeMessage"
-	swapEnablePrefKey      = "swapEnable"
-	natAddressPrefKey      = "natAddress"
-	rpcEndpointPrefKey     = "rpcEndpoint"
-	selectedStampPrefKey   = "selected_stamp"
-	batchPrefKey           = "batch"
-	uploadsPrefKey         = "uploads"
-	overlayAddrPrefKey     = "overlayAddress"
-	eglrefPrefKey          = "eglref"
-	historyRefPrefKey      = "historyRef"
-)
-
-var (
-	MainnetBootnodes = []string{
-		"/dnsaddr/mainnet.ethswarm.org",
-	}
-
-	TestnetBootnodes = []string{
-		"/dnsaddr/testnet.ethswarm.org",
-	}
+	defaultWelcomeMsg     = "Welcome from ACTivate!"
+	defaultPassword       = "defaultpassword"
+	defaultNatAddress     = ""
+	defaultSwapEnable     = true
+	infoLogLevel          = "3"
+	defaultDepth          = "21"
+	defaultAmount         = "500000000"
+	defaultImmutable      = true
+	passwordPrefKey       = "password"
+	welcomeMessagePrefKey = "welcomeMessage"
+	swapEnablePrefKey     = "swapEnable"
+	natAddressPrefKey     = "natAddress"
+	rpcEndpointPrefKey    = "rpcEndpoint"
+	selectedStampPrefKey  = "selected_stamp"
+	batchPrefKey          = "batch"
+	uploadsPrefKey        = "uploads"
+	overlayAddrPrefKey    = "overlayAddress"
+	eglrefPrefKey         = "eglref"
+	historyRefPrefKey     = "historyRef"
+	contactsPrefKey       = "contacts"
+
+	eventPublicKeyPrefKey    = "eventPublicKey"
+	eventReferencePrefKey    = "event32ByteHex"
+	eventActRefPrefKey       = "eventActRef"
+	eventVerificationPrefKey = "eventVerification"
 )
 
 type logger struct{}
@@ -85,18 +78,44 @@ type index struct {
 	bl         *beelite.Beelite
 	logger     *logger
 	nodeConfig *nodeConfig
-
-	ethClient            *ethclient.Client
-	contractSvc          DataContractInterface
-	dataContractABI      abi.ABI // Store the parsed ABI here
-	eventLogSubscription ethereum.Subscription
-	eventMessageLabel    *widget.Label
+	nodeKey    *ecdsa.PrivateKey
+
+	contractSvc             DataContractInterface
+	groupRegistry           GroupRegistryInterface
+	dataContractABI         abi.ABI // Store the parsed ABI here
+	eventLogSubscription    ethereum.Subscription
+	cancelEventSubscription context.CancelFunc
+	eventMessageLabel       *widget.Label
+	connectionLabel         *widget.Label
+	eventJournal            eventJournal
+	txJournal               *txJournal
+
+	// ethClientMu guards ethClient, which the event supervisor replaces when
+	// it redials. Read it through contractClient.
+	ethClientMu sync.RWMutex
+	ethClient   *ethclient.Client
+
+	// contractFunctions are the data contract functions the deployed contract
+	// has, looked up on first use
+	contractFunctionsMu sync.Mutex
+	contractFunctions   map[string]bool
+
+	// groupKeysMu guards loading, merging and storing the group keys
+	groupKeysMu sync.Mutex
+
+	inboxMu   sync.Mutex
+	inbox     inbox
+	inboxList *widget.List
+	inboxCard *widget.Card
+
+	sessionMu    sync.Mutex
+	chatMessages []chatMessage
+	chatList     *widget.List
 }
 
 func (i *index) initContract(txService transaction.Service) {
-	rpcEndpoint := defaultRPC
-	var err error
-	i.ethClient, err = ethclient.DialContext(context.Background(), rpcEndpoint)
+	rpcEndpoint := i.contractRPCEndpoint()
+	err := i.redialEthClient(context.Background())
 	if err != nil {
 		i.logger.Log(fmt.Sprintf("Failed to connect to Ethereum client via %s: %v", rpcEndpoint, err))
 	}
@@ -107,24 +126,62 @@ func (i *index) initContract(txService transaction.Service) {
 		i.showError(err)
 	}
 
-	dataContractAddr := common.HexToAddress(dataContractAddressHex)
+	i.txJournal = newTxJournal(
+		func() string { return i.getPreferenceString(txJournalPrefKey) },
+		func(s string) { i.setPreference(txJournalPrefKey, s) },
+	)
 
 	if i.dataContractABI.Events != nil { // Check if ABI was parsed and has events
 		i.contractSvc = NewDataContract(
 			i.bl.OverlayEthAddress(),
-			dataContractAddr,
+			i.network().ContractAddress,
 			i.dataContractABI,
 			txService,
 			true, // setGasLimit
+			i.eventPollInterval(),
+			i.eventConfirmations(),
+			i.txJournal,
 		)
 	} else {
 		i.logger.Log("Data contract ABI not parsed or no events found, contractSvc not initialized.")
 	}
 
+	if address := i.network().GroupRegistryAddress; address != (common.Address{}) {
+		registryABI, err := ParseGroupRegistryABI()
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Failed to parse group registry ABI JSON: %v", err))
+		} else {
+			i.groupRegistry = NewGroupRegistry(i.bl.OverlayEthAddress(), address, registryABI, txService, true, i.txJournal)
+		}
+	}
+
 	// Initialize UI elements for events
 	i.eventMessageLabel = widget.NewLabel("Initializing event listener...")
 	i.eventMessageLabel.Wrapping = fyne.TextWrapWord
 	i.eventMessageLabel.Alignment = fyne.TextAlignCenter
+	i.connectionLabel = widget.NewLabel(fmt.Sprintf("Contract events: %s", stateConnecting))
+	i.connectionLabel.Alignment = fyne.TextAlignCenter
+}
+
+// contractHas reports whether the deployed data contract has method. Sends
+// fall back to sendDataToTarget, which every deployment has, when it does not
+// or the contract code cannot be read.
+func (i *index) contractHas(ctx context.Context, method string) bool {
+	i.contractFunctionsMu.Lock()
+	defer i.contractFunctionsMu.Unlock()
+	if i.contractFunctions == nil {
+		client := i.contractClient()
+		if client == nil {
+			return false
+		}
+		functions, err := deployedFunctions(ctx, client, i.network().ContractAddress, i.dataContractABI)
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Failed to read the data contract's functions: %v", err))
+			return false
+		}
+		i.contractFunctions = functions
+	}
+	return i.contractFunctions[method]
 }
 
 func Make(a fyne.App, w fyne.Window) fyne.CanvasObject {
@@ -149,7 +206,8 @@ func Make(a fyne.App, w fyne.Window) fyne.CanvasObject {
 	i.nodeConfig.welcomeMessage = defaultWelcomeMsg
 	i.nodeConfig.password = defaultPassword
 	i.nodeConfig.natAddress = defaultNatAddress
-	i.nodeConfig.rpcEndpoint = defaultRPC
+	i.nodeConfig.network = i.getPreferenceString(networkPrefKey)
+	i.nodeConfig.rpcEndpoint = i.network().RPCURL
 	i.nodeConfig.swapEnable = defaultSwapEnable
 
 	i.view = container.NewBorder(container.NewVBox(i.intro), nil, nil, nil, container.NewStack(i.showStartView(false)))
@@ -199,17 +257,13 @@ func (i *index) start(path, password, welcomeMessage, natAddress, rpcEndpoint st
 func (i *index) initSwarm(dataDir, welcomeMessage, password, natAddress, rpcEndpoint string, swapEnable bool) error {
 	i.logger.Log(welcomeMessage)
 
-	// isMainnet := rpcEndpoint == defaultRPC
-	isMainnet := true
-	networkID := MainnetNetworkID
-	if !isMainnet {
-		networkID = TestnetNetworkID
-	}
+	network := i.network()
+	i.logger.Log(fmt.Sprintf("Joining the Swarm network %d of %s", network.SwarmNetworkID, network.Name))
 
 	lo := &beelite.LiteOptions{
 		FullNodeMode:             false,
 		BootnodeMode:             false,
-		Bootnodes:                MainnetBootnodes,
+		Bootnodes:                network.Bootnodes,
 		DataDir:                  dataDir,
 		WelcomeMessage:           welcomeMessage,
 		BlockchainRpcEndpoint:    rpcEndpoint,
@@ -218,8 +272,8 @@ func (i *index) initSwarm(dataDir, welcomeMessage, password, natAddress, rpcEndp
 		SwapEnable:               swapEnable,
 		ChequebookEnable:         true,
 		UsePostageSnapshot:       false,
-		Mainnet:                  isMainnet,
-		NetworkID:                networkID,
+		Mainnet:                  network.SwarmMainnet,
+		NetworkID:                network.SwarmNetworkID,
 		NATAddr:                  natAddress,
 		CacheCapacity:            32 * 1024 * 1024,
 		DBOpenFilesLimit:         50,
@@ -257,6 +311,21 @@ func (i *index) loadMenuView() {
 	downloadCard := i.showDownloadCard()
 	menuContent.Add(downloadCard)
 
+	chatCard := i.showChatCard()
+	menuContent.Add(chatCard)
+
+	contactsCard := i.showContactsCard()
+	menuContent.Add(contactsCard)
+
+	groupRegistryCard := i.showGroupRegistryCard()
+	menuContent.Add(groupRegistryCard)
+
+	transactionsCard := i.showTransactionsCard()
+	menuContent.Add(transactionsCard)
+
+	if i.connectionLabel != nil {
+		menuContent.Add(i.connectionLabel)
+	}
 	if i.eventMessageLabel != nil {
 		menuContent.Add(i.eventMessageLabel)
 	} else {
@@ -278,292 +347,198 @@ func (i *index) loadMenuView() {
 }
 
 func (i *index) setupDataContractSubscription() {
-	if i.eventLogSubscription != nil {
-		i.eventLogSubscription.Unsubscribe() // Unsubscribe from previous if any
+	if i.cancelEventSubscription != nil {
+		i.cancelEventSubscription() // Stop the previous supervisor if any
 	}
 
-	logs := make(chan types.Log)
-	var err error
-
-	// Use a new context for the subscription goroutine, or manage it with the app's lifecycle
+	// Use a new context for the supervisor and the transaction watcher, cancelled with the window
 	subCtx, cancelSubCtx := context.WithCancel(context.Background())
+	i.cancelEventSubscription = cancelSubCtx
 	i.Window.SetOnClosed(func() { // Ensure cancellation when window closes
 		cancelSubCtx()
 	})
 
-	i.eventLogSubscription, err = i.contractSvc.SubscribeDataSentToTarget(subCtx, i.ethClient, logs)
-	if err != nil {
-		errMsg := fmt.Sprintf("Failed to subscribe to DataSentToTarget: %v", err)
-		i.logger.Log(errMsg)
-		if i.eventMessageLabel != nil {
-			i.eventMessageLabel.SetText(errMsg)
-		}
-		cancelSubCtx() // Cancel context if subscription fails
+	go i.superviseEventSubscription(subCtx)
+	// transactions left pending by the last run are settled first
+	go i.watchTransactions(subCtx)
+}
+
+// handleDataSentToTarget parses a DataSentToTarget or DataSentToTargetV2 log
+// and acts on its payload
+func (i *index) handleDataSentToTarget(vLog types.Log) {
+	i.logger.Log(fmt.Sprintf("Received log: Block %d, TxHash %s, Topics %d, Data %d bytes", vLog.BlockNumber, vLog.TxHash.Hex(), len(vLog.Topics), len(vLog.Data)))
+	effects := newEventEffects(vLog)
+	defer i.eventJournal.add(effects)
+
+	if isDataSentToTargetV2(vLog) {
+		i.handleDataSentToTargetV2(vLog, effects)
 		return
 	}
 
-	i.logger.Log("Successfully subscribed to DataSentToTarget events.")
-	if i.eventMessageLabel != nil {
-		i.eventMessageLabel.SetText("Subscribed. Waiting for 'DataSentToTarget' events...")
+	event, err := ParseDataSentToTarget(vLog)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Skipping log: %v", err))
+		return
 	}
+	i.logger.Log("Processing 'DataSentToTarget' event...")
 
-	go func() {
-		defer func() {
-			if i.eventLogSubscription != nil {
-				i.eventLogSubscription.Unsubscribe()
-			}
-			cancelSubCtx() // Ensure context is cancelled when goroutine exits
-			i.logger.Log("Event listener goroutine stopped.")
-		}()
-
-		for {
-			select {
-			case <-subCtx.Done():
-				i.logger.Log("Event listener context cancelled. Unsubscribing.")
-				return
-			case err := <-i.eventLogSubscription.Err():
-				errMsg := fmt.Sprintf("Event subscription error: %v", err)
-				i.logger.Log(errMsg)
-				if i.eventMessageLabel != nil {
-					i.eventMessageLabel.SetText("Subscription error. Check logs.")
-				}
-				// Depending on the error, you might want to attempt to resubscribe.
-				// For now, we stop listening on error.
-				return
-			case vLog := <-logs:
-				i.logger.Log(fmt.Sprintf("Received log: Block %d, TxHash %s, Topics %d, Data %d bytes", vLog.BlockNumber, vLog.TxHash.Hex(), len(vLog.Topics), len(vLog.Data)))
-
-				eventName := "DataSentToTarget"
-				eventAbi, ok := i.dataContractABI.Events[eventName]
-				if !ok {
-					i.logger.Log(fmt.Sprintf("Event %s not found in ABI. Cannot parse.", eventName))
-					continue
-				}
-
-				// Check if this log is indeed for DataSentToTarget based on Topic[0]
-				if len(vLog.Topics) == 0 || vLog.Topics[0] != eventAbi.ID {
-					i.logger.Log(fmt.Sprintf("Received log does not match %s event signature. Skipping. Log Topic0: %s, Expected: %s", eventName, vLog.Topics[0].Hex(), eventAbi.ID.Hex()))
-					continue
-				}
-				i.logger.Log(fmt.Sprintf("Processing '%s' event...", eventName))
-
-				var targetAddr common.Address
-				var ownerBytes []byte
-				var actRefBytes []byte
-				var topicString string
-
-				// Unpack indexed fields from Topics
-				topicIdx := 1 // Topics[0] is the event signature itself
-				for _, input := range eventAbi.Inputs {
-					if input.Indexed {
-						if topicIdx < len(vLog.Topics) {
-							if input.Name == "target" { // Assuming 'target' is the name in ABI
-								targetAddr = common.BytesToAddress(vLog.Topics[topicIdx].Bytes())
-							}
-							// Add other indexed fields here if any, by checking input.Name or type
-							topicIdx++
-						} else {
-							i.logger.Log(fmt.Sprintf("Warning: Mismatch count for indexed ABI inputs and log topics for event %s.", eventName))
-							break
-						}
-					}
-				}
-
-				// Prepare to unpack non-indexed fields from Data
-				var nonIndexedArgs abi.Arguments
-				for _, input := range eventAbi.Inputs {
-					if !input.Indexed {
-						nonIndexedArgs = append(nonIndexedArgs, input)
-					}
-				}
-
-				if len(nonIndexedArgs) > 0 {
-					unpackedData, err := nonIndexedArgs.Unpack(vLog.Data)
-					if err != nil {
-						i.logger.Log(fmt.Sprintf("Failed to unpack non-indexed data for event %s: %v", eventName, err))
-					} else {
-						// Debug: Log the unpacked data structure
-						i.logger.Log(fmt.Sprintf("Unpacked data count: %d", len(unpackedData)))
-						for idx, data := range unpackedData {
-							i.logger.Log(fmt.Sprintf("Unpacked data[%d]: %T = %v", idx, data, data))
-						}
-
-						// Debug: Log the argument names and types
-						for idx, arg := range nonIndexedArgs {
-							i.logger.Log(fmt.Sprintf("Arg[%d]: Name='%s', Type='%s'", idx, arg.Name, arg.Type.String()))
-						}
-
-						// Assign to variables based on the order of non-indexed args in ABI
-						// The ABI shows: owner (bytes32), actref (bytes32), topic (string)
-						currentUnpackedIdx := 0
-						for _, arg := range nonIndexedArgs {
-							if currentUnpackedIdx >= len(unpackedData) {
-								break
-							}
-							switch arg.Name { // Match the actual ABI field names
-							case "owner":
-								if val, ok := unpackedData[currentUnpackedIdx].([32]byte); ok {
-									ownerBytes = val[:]
-								} else if val, ok := unpackedData[currentUnpackedIdx].([]byte); ok {
-									ownerBytes = val
-								}
-							case "actref": // Note: ABI uses "actref" not "actRef"
-								if val, ok := unpackedData[currentUnpackedIdx].([32]byte); ok {
-									actRefBytes = val[:]
-								} else if val, ok := unpackedData[currentUnpackedIdx].([]byte); ok {
-									actRefBytes = val
-								}
-							case "topic":
-								if val, ok := unpackedData[currentUnpackedIdx].(string); ok {
-									topicString = val
-								}
-							}
-							currentUnpackedIdx++
-						}
-					}
-				}
+	targetAddr := event.To
+	ownerBytes := event.Owner[:]
+	actRefBytes := event.ActRef[:]
+	topicString := event.Topic
 
-				parsedMsg := fmt.Sprintf("'DataSentToTarget' Event! Block: %d.", vLog.BlockNumber)
-				if targetAddr != (common.Address{}) {
-					parsedMsg += fmt.Sprintf(" Target: %s.", targetAddr.Hex())
-				}
-				if len(ownerBytes) > 0 {
-					parsedMsg += fmt.Sprintf(" Owner: 0x%x.", ownerBytes)
-				}
-				if len(actRefBytes) > 0 {
-					parsedMsg += fmt.Sprintf(" ActRef: 0x%x.", actRefBytes)
-				}
-				if topicString != "" {
-					parsedMsg += fmt.Sprintf(" Topic: '%s'.", topicString)
-				}
-
-				i.logger.Log("Formatted event message: " + parsedMsg)
-				if i.eventMessageLabel != nil {
-					i.eventMessageLabel.SetText(parsedMsg)
-				}
-
-				// TODO: Temporarily commented out decryption - uncomment when needed
-				/*
-					// Decrypt the data before storing it using a placeholder private key
-					encryptionUtils := &EncryptionUtils{}
-
-					// Placeholder private key (hex encoded) - replace with actual private key in production
-					placeholderPrivateKeyHex := "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	parsedMsg := fmt.Sprintf("'DataSentToTarget' Event! Block: %d. Target: %s. Owner: 0x%x. ActRef: 0x%x.", vLog.BlockNumber, targetAddr.Hex(), ownerBytes, actRefBytes)
+	if topicString != "" {
+		parsedMsg += fmt.Sprintf(" Topic: '%s'.", topicString)
+	}
 
-					// Parse the private key
-					privateKeyBytes, err := hex.DecodeString(placeholderPrivateKeyHex)
-					if err != nil {
-						i.logger.Log(fmt.Sprintf("Failed to decode placeholder private key: %v", err))
-					} else {
-						// Convert to ECDSA private key
-						privateKey, err := crypto.ToECDSA(privateKeyBytes)
-						if err != nil {
-							i.logger.Log(fmt.Sprintf("Failed to convert to ECDSA private key: %v", err))
-						} else {
-							// Print the corresponding public key
-							publicKeyBytes := crypto.FromECDSAPub(&privateKey.PublicKey)
-							publicKeyHex := hex.EncodeToString(publicKeyBytes)
-							i.logger.Log(fmt.Sprintf("Placeholder private key's public key: %s", publicKeyHex))
-
-							// Attempt to decrypt the data
-							var decryptedOwnerBytes []byte
-							var decryptedActRefBytes []byte
-							var decryptedTopicString string
-
-							// Decrypt owner bytes
-							if len(ownerBytes) > 0 {
-								decryptedOwner, err := encryptionUtils.DecryptData(ownerBytes, privateKey)
-								if err != nil {
-									i.logger.Log(fmt.Sprintf("Failed to decrypt owner data, using original: %v", err))
-									decryptedOwnerBytes = ownerBytes
-								} else {
-									decryptedOwnerBytes = decryptedOwner
-									i.logger.Log("Successfully decrypted owner data")
-								}
-							} else {
-								decryptedOwnerBytes = ownerBytes
-							}
+	i.logger.Log("Formatted event message: " + parsedMsg)
+	if i.eventMessageLabel != nil {
+		i.eventMessageLabel.SetText(parsedMsg)
+	}
 
-							// Decrypt actRef bytes
-							if len(actRefBytes) > 0 {
-								decryptedActRef, err := encryptionUtils.DecryptData(actRefBytes, privateKey)
-								if err != nil {
-									i.logger.Log(fmt.Sprintf("Failed to decrypt actRef data, using original: %v", err))
-									decryptedActRefBytes = actRefBytes
-								} else {
-									decryptedActRefBytes = decryptedActRef
-									i.logger.Log("Successfully decrypted actRef data")
-								}
-							} else {
-								decryptedActRefBytes = actRefBytes
-							}
+	// Encrypted payloads are addressed to one node only
+	if isEncryptedTopic(topicString) {
+		privateKey, err := i.nodePrivateKey()
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Cannot decrypt event payload: %v", err))
+			return
+		}
+		encryptionUtils := &EncryptionUtils{}
+		decryptedTopic, err := encryptionUtils.decryptTopic(topicString, privateKey)
+		if errors.Is(err, errWrongRecipient) {
+			i.logger.Log("Encrypted event payload is addressed to another node. Skipping.")
+			return
+		}
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Failed to decrypt event payload: %v", err))
+			return
+		}
+		i.logger.Log("Successfully decrypted event payload")
+		topicString = decryptedTopic
+	} else {
+		i.logger.Log("Event payload is not encrypted")
+	}
 
-							// Decrypt topic string
-							if topicString != "" {
-								decryptedTopic, err := encryptionUtils.DecryptData([]byte(topicString), privateKey)
-								if err != nil {
-									i.logger.Log(fmt.Sprintf("Failed to decrypt topic data, using original: %v", err))
-									decryptedTopicString = topicString
-								} else {
-									decryptedTopicString = string(decryptedTopic)
-									i.logger.Log("Successfully decrypted topic data")
-								}
-							} else {
-								decryptedTopicString = topicString
-							}
+	payload, err := parseSharePayload(topicString)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to parse topic payload: %v", err))
+	} else {
+		var consumed bool
+		actRefBytes, consumed = i.receivePayload(vLog, effects, event.From, targetAddr, payload, actRefBytes, parsedMsg)
+		if consumed {
+			return
+		}
+	}
 
-							// Use decrypted data for storage
-							ownerBytes = decryptedOwnerBytes
-							actRefBytes = decryptedActRefBytes
-							topicString = decryptedTopicString
-						}
-					}
-				*/
-
-				i.logger.Log("Using raw event data without decryption") // Parse the modified topic data (publicKey + 32-byte hex string)
-				if len(topicString) >= 194 {                            // 130 chars (public key) + 64 chars (32-byte hex) = 194 chars
-					// Extract the public key (first 130 characters if it starts with 04, otherwise first 128)
-					var extractedPublicKey string
-					var extracted32ByteHex string
-
-					if len(topicString) >= 130 && topicString[:2] == "04" {
-						// Uncompressed public key format (130 chars)
-						extractedPublicKey = topicString[:130]
-						if len(topicString) >= 194 {
-							extracted32ByteHex = topicString[130:194]
-						} else {
-							extracted32ByteHex = topicString[130:]
-						}
-					} else if len(topicString) >= 128 {
-						// Compressed public key or other format (128 chars)
-						extractedPublicKey = topicString[:128]
-						if len(topicString) >= 192 {
-							extracted32ByteHex = topicString[128:192]
-						} else {
-							extracted32ByteHex = topicString[128:]
-						}
-					}
+	// use setPreference to store the owner, actRef, and topic
+	i.setEventPreference(effects, "eventOwner", hex.EncodeToString(ownerBytes))
+	i.setEventPreference(effects, eventActRefPrefKey, hex.EncodeToString(actRefBytes))
+	i.setEventPreference(effects, "eventTopic", topicString)
+	i.logger.Log("Stored owner, actRef, and topic in preferences.")
+	i.logger.Log("Event processing complete.")
+}
 
-					i.logger.Log(fmt.Sprintf("Extracted from topic - PublicKey: %s", extractedPublicKey))
-					i.logger.Log(fmt.Sprintf("Extracted from topic - 32ByteHex: %s", extracted32ByteHex))
+// handleDataSentToTargetV2 opens the notification of a DataSentToTargetV2 log
+// and acts on its payload
+func (i *index) handleDataSentToTargetV2(vLog types.Log, effects *eventEffects) {
+	event, err := ParseDataSentToTargetV2(vLog)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Skipping log: %v", err))
+		return
+	}
+	n := event.notification()
+	parsedMsg := fmt.Sprintf("'DataSentToTargetV2' Event! Block: %d. Target: %s. Notification: %s, %d bytes.", vLog.BlockNumber, event.To.Hex(), n.Version, len(n.Payload))
+	i.logger.Log("Formatted event message: " + parsedMsg)
+	if i.eventMessageLabel != nil {
+		i.eventMessageLabel.SetText(parsedMsg)
+	}
 
-					// Store both parts separately
-					i.setPreference("eventPublicKey", extractedPublicKey)
-					i.setPreference("event32ByteHex", extracted32ByteHex)
-				} else {
-					i.logger.Log(fmt.Sprintf("Topic string too short (%d chars) to contain publicKey + 32-byte hex", len(topicString)))
-				}
+	// sealed notifications are addressed to one node only
+	var privateKey *ecdsa.PrivateKey
+	if n.Version == notificationSealed {
+		if privateKey, err = i.nodePrivateKey(); err != nil {
+			i.logger.Log(fmt.Sprintf("Cannot decrypt event payload: %v", err))
+			return
+		}
+	}
+	encryptionUtils := &EncryptionUtils{}
+	payload, err := encryptionUtils.openNotification(n, privateKey)
+	if errors.Is(err, errWrongRecipient) {
+		i.logger.Log("Sealed notification is addressed to another node. Skipping.")
+		return
+	}
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to open notification: %v", err))
+		return
+	}
 
-				// use setPreference to store the owner, actRef, and topic
-				i.setPreference("eventOwner", hex.EncodeToString(ownerBytes))
-				i.setPreference("eventActRef", hex.EncodeToString(actRefBytes))
-				i.setPreference("eventTopic", topicString)
-				i.logger.Log("Stored owner, actRef, and topic in preferences.")
-				i.logger.Log("Event processing complete.")
+	history, consumed := i.receivePayload(vLog, effects, event.From, event.To, payload, n.HistoryRef[:], parsedMsg)
+	if consumed {
+		return
+	}
+	i.setEventPreference(effects, eventActRefPrefKey, hex.EncodeToString(history))
+	i.logger.Log("Event processing complete.")
+}
 
+// receivePayload acts on the share payload of an event sender sent to target.
+// It returns the history reference to use, the signed one over the event's,
+// and whether the payload was consumed as a session message.
+func (i *index) receivePayload(vLog types.Log, effects *eventEffects, sender, target common.Address, payload *sharePayload, actRefBytes []byte, parsedMsg string) ([]byte, bool) {
+	status := payload.verify(target)
+	publisherHex := hex.EncodeToString(crypto.FromECDSAPub(payload.Publisher))
+	i.logger.Log(fmt.Sprintf("Received %s from publisher %s: %s", payload.Kind, publisherHex, status))
+	if status == payloadVerified {
+		i.checkContactKey(sender, payload.Publisher)
+	}
+	if payload.Kind == payloadKindSession {
+		// session messages go to the chat, not the download form
+		if status != payloadVerified {
+			i.logger.Log("Ignoring session message without a valid signature.")
+			return actRefBytes, true
+		}
+		go func(payload *sharePayload) {
+			if err := i.receiveSessionMessage(context.Background(), payload); err != nil {
+				i.logger.Log(fmt.Sprintf("Failed to receive session message: %v", err))
 			}
+		}(payload)
+		return actRefBytes, true
+	}
+	if payload.Kind == payloadKindGroupKeys {
+		if status != payloadVerified || len(payload.Reference) != bundleRefSize {
+			i.logger.Log("Ignoring group keys without a valid signature or bundle reference.")
+			return actRefBytes, true
 		}
-	}()
+		go func(bundleRef []byte) {
+			keys, err := i.fetchGroupKeys(context.Background(), bundleRef)
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Failed to receive group keys: %v", err))
+				return
+			}
+			i.logger.Log(fmt.Sprintf("Received %d group keys from %s", len(keys), i.describeKey(publisherHex)))
+		}(payload.Reference)
+		return actRefBytes, true
+	}
+	if len(payload.History) > 0 && common.BytesToHash(actRefBytes) != (common.Hash{}) && !bytes.Equal(payload.History, actRefBytes) {
+		i.logger.Log("Signed history reference differs from the event's actref, using the signed one.")
+	}
+	if len(payload.History) > 0 {
+		actRefBytes = payload.History
+	}
+
+	i.setEventPreference(effects, eventPublicKeyPrefKey, publisherHex)
+	i.setEventPreference(effects, eventReferencePrefKey, hex.EncodeToString(payload.Reference))
+	i.setEventPreference(effects, eventVerificationPrefKey, status.String())
+	i.receiveShare(vLog, &inboxEntry{
+		Publisher:    publisherHex,
+		Reference:    hex.EncodeToString(payload.Reference),
+		History:      hex.EncodeToString(actRefBytes),
+		Verification: status.String(),
+	})
+	if i.eventMessageLabel != nil {
+		i.eventMessageLabel.SetText(fmt.Sprintf("%s\nSender: %s\nSender signature: %s", parsedMsg, i.describeKey(publisherHex), status))
+	}
+	return actRefBytes, false
 }
 
 func (i *index) sendTransactionButton() *widget.Button {
@@ -574,53 +549,55 @@ func (i *index) sendTransactionButton() *widget.Button {
 		}
 
 		// Initialize encryption utils
-		encryptionUtils := &EncryptionUtils{}
-
-		// Create input form for transaction parameters
-		targetEntry := widget.NewEntry()
-		targetEntry.SetPlaceHolder("Target address (0x...)")
-		targetEntry.SetText("0x1234567890123456789012345678901234567890")
-
-		ownerEntry := widget.NewEntry()
-		ownerEntry.SetPlaceHolder("Owner address (0x...)")
-		ownerEntry.SetText("0x1234567890123456789012345678901234567890")
+		encryptionUtils := i.encryptionUtils()
+
+		// The payload is encrypted to the recipient's public key, the target
+		// address is derived from it
+		recipientEntry := widget.NewSelectEntry(nil)
+		recipientEntry.SetPlaceHolder("Recipient public key (hex) or pick a grantee")
+		targetLabel := widget.NewLabel("")
+		recipientEntry.OnChanged = func(s string) {
+			publicKey, err := encryptionUtils.ParsePublicKeyFromHex(s)
+			if err != nil {
+				targetLabel.SetText("")
+				return
+			}
+			targetLabel.SetText(crypto.PubkeyToAddress(*publicKey).Hex())
+		}
+		go func() {
+			eglRef := i.storedEglRef()
+			if eglRef.IsZero() {
+				return
+			}
+			grantees, err := i.bl.GetGranteeList(context.Background(), eglRef, false)
+			if err != nil {
+				i.logger.Log(fmt.Sprintf("Error fetching grantee list for recipients: %v", err))
+				return
+			}
+			recipientEntry.SetOptions(grantees)
+		}()
 
 		actRefEntry := widget.NewEntry()
 		actRefEntry.SetPlaceHolder("ACT reference (hex string)")
-		actRefEntry.SetText("14b4fe81bf1445c429a236cf74aecaa6cc915f1f461e333d4c83091b114012e0")
+		actRefEntry.SetText(i.getPreferenceString(historyRefPrefKey))
 
-		topicEntry := widget.NewEntry()
-		topicEntry.SetPlaceHolder("Topic")
-		topicEntry.SetText("example-topic")
+		referenceEntry := widget.NewEntry()
+		referenceEntry.SetPlaceHolder("Swarm reference (hex string)")
 
-		// Add public key input with default value
-		publicKeyEntry := widget.NewEntry()
-		publicKeyEntry.SetPlaceHolder("ECDSA Public Key (hex format)")
-		publicKeyEntry.SetText(encryptionUtils.GetDefaultPublicKey())
+		kindSelect := widget.NewSelect([]string{payloadKindACT.String(), payloadKindMessage.String(), payloadKindInvite.String()}, nil)
+		kindSelect.SetSelectedIndex(0)
 
-		// Add button to generate new key pair
-		generateKeyButton := widget.NewButton("Generate New Key Pair", func() {
-			publicKeyHex, _, err := encryptionUtils.GenerateKeyPair()
-			if err != nil {
-				i.showError(fmt.Errorf("failed to generate key pair: %w", err))
-				return
-			}
-			publicKeyEntry.SetText(publicKeyHex)
-			dialog.ShowInformation("Key Generated", "New ECDSA key pair generated successfully!", i.Window)
-		})
-
-		// Create encryption checkbox
-		encryptDataCheck := widget.NewCheck("Encrypt transaction data", nil)
-		encryptDataCheck.SetChecked(true)
+		topicEntry := widget.NewEntry()
+		topicEntry.SetPlaceHolder("Message")
 
 		form := &widget.Form{
 			Items: []*widget.FormItem{
-				widget.NewFormItem("Target Address", targetEntry),
-				widget.NewFormItem("Owner Data", ownerEntry),
+				widget.NewFormItem("Recipient", recipientEntry),
+				widget.NewFormItem("Target Address", targetLabel),
 				widget.NewFormItem("ACT Reference", actRefEntry),
-				widget.NewFormItem("Topic", topicEntry),
-				widget.NewFormItem("", encryptDataCheck),
-				widget.NewFormItem("Public Key", container.NewBorder(nil, generateKeyButton, nil, nil, publicKeyEntry)),
+				widget.NewFormItem("Payload", kindSelect),
+				widget.NewFormItem("Swarm Reference", referenceEntry),
+				widget.NewFormItem("Message", topicEntry),
 			},
 		}
 
@@ -630,128 +607,135 @@ func (i *index) sendTransactionButton() *widget.Button {
 				return
 			}
 
-			// Validate target address
-			if !common.IsHexAddress(targetEntry.Text) {
-				i.showError(fmt.Errorf("invalid target address"))
-				return
-			}
-
-			// Validate owner address
-			if !common.IsHexAddress(ownerEntry.Text) {
-				i.showError(fmt.Errorf("invalid owner address"))
+			// Validate recipient public key
+			recipient, err := encryptionUtils.ParsePublicKeyFromHex(recipientEntry.Text)
+			if err != nil {
+				i.showError(fmt.Errorf("invalid recipient public key: %w", err))
 				return
 			}
 
 			// Validate ACT reference as hex string
-			actRefData := actRefEntry.Text
-			if len(actRefData) > 2 && actRefData[:2] == "0x" {
-				actRefData = actRefData[2:] // Remove 0x prefix if present
-			}
-			if len(actRefData)%2 != 0 {
-				i.showError(fmt.Errorf("ACT reference must be valid hex string (even number of characters)"))
+			actRefBytes, err := hex.DecodeString(strings.TrimPrefix(actRefEntry.Text, "0x"))
+			if err != nil {
+				i.showError(fmt.Errorf("ACT reference must be valid hex string: %w", err))
 				return
 			}
-			// Validate hex format
-			if _, err := hex.DecodeString(actRefData); err != nil {
-				i.showError(fmt.Errorf("ACT reference must be valid hex string: %w", err))
+
+			referenceBytes, err := hex.DecodeString(strings.TrimPrefix(referenceEntry.Text, "0x"))
+			if err != nil || (len(referenceBytes) != 0 && len(referenceBytes) != swarm.HashSize && len(referenceBytes) != 2*swarm.HashSize) {
+				i.showError(fmt.Errorf("swarm reference must be a 32 or 64 byte hex string"))
 				return
 			}
 
 			// Show progress dialog
 			i.showProgressWithMessage("Processing and sending transaction...")
 
-			target := common.HexToAddress(targetEntry.Text)
-			ownerAddr := common.HexToAddress(ownerEntry.Text) // Owner as address
-			actRefData = actRefEntry.Text                     // ACT ref as hex string
-			// topicData := topicEntry.Text // Not used since we create custom topic data
-
-			// Process ACT reference hex string
-			if len(actRefData) > 2 && actRefData[:2] == "0x" {
-				actRefData = actRefData[2:] // Remove 0x prefix if present
-			}
-			actRefBytes, err := hex.DecodeString(actRefData)
-			if err != nil {
-				i.showError(fmt.Errorf("failed to decode ACT reference hex: %w", err))
-				return
-			}
+			topicData := topicEntry.Text
+			kind := payloadKind(kindSelect.SelectedIndex() + 1)
 
 			go func() {
 				defer i.hideProgress()
 
-				var owner, actRef []byte
-				var topic string
-				var encryptionInfo string
-
-				// TODO: Temporarily commented out encryption - uncomment when needed
-				/*
-					// Apply encryption if enabled
-					if encryptDataCheck.Checked {
-						// Parse public key from hex
-						publicKey, err := encryptionUtils.ParsePublicKeyFromHex(publicKeyEntry.Text)
-						if err != nil {
-							i.showError(fmt.Errorf("invalid public key: %w", err))
-							return
-						}
-
-						// Encrypt owner address bytes directly
-						encryptedOwnerBytes, err := encryptionUtils.EncryptData(ownerAddr.Bytes(), publicKey)
-						if err != nil {
-							i.showError(fmt.Errorf("failed to encrypt owner address: %w", err))
-							return
-						}
+				// Sign the payload with the node key so the target can verify the sender
+				nodeKey, err := i.nodePrivateKey()
+				if err != nil {
+					i.showError(fmt.Errorf("cannot sign payload: %w", err))
+					return
+				}
+				payload := sharePayload{
+					Kind:      kind,
+					Reference: referenceBytes,
+					History:   actRefBytes,
+				}
+				if kind != payloadKindACT {
+					payload.Body = []byte(topicData)
+				}
 
-						// Encrypt ACT reference bytes directly (same size output)
-						encryptedActRefBytes, err := encryptionUtils.EncryptData(actRefBytes, publicKey)
-						if err != nil {
-							i.showError(fmt.Errorf("failed to encrypt ACT reference: %w", err))
-							return
-						}
+				ctx := context.Background()
+				// deployments without sendDataToTargetV2 take an encrypted topic
+				var target common.Address
+				var estimateTx func(feeClient, feeSpeed) (*feeEstimate, error)
+				var sendTx func(context.Context) (*types.Receipt, error)
+				if i.contractHas(ctx, "sendDataToTargetV2") {
+					var n *notification
+					target, n, err = sealNotification(nodeKey, payload, recipient)
+					if err != nil {
+						i.showError(err)
+						return
+					}
+					i.logger.Log(fmt.Sprintf("Encrypted signed %s payload for %s: %d bytes", kind, target.Hex(), len(n.Payload)))
+					estimateTx = func(client feeClient, speed feeSpeed) (*feeEstimate, error) {
+						return i.contractSvc.EstimateSendNotification(ctx, client, speed, target, n)
+					}
+					sendTx = func(ctx context.Context) (*types.Receipt, error) {
+						return i.contractSvc.SendNotification(ctx, target, n)
+					}
+				} else {
+					var topic string
+					target, topic, err = sealTopic(nodeKey, payload, recipient)
+					if err != nil {
+						i.showError(err)
+						return
+					}
+					i.logger.Log(fmt.Sprintf("Encrypted signed %s payload for %s as a topic: %d bytes", kind, target.Hex(), len(topic)))
+					estimateTx = func(client feeClient, speed feeSpeed) (*feeEstimate, error) {
+						return i.contractSvc.EstimateSendDataToTarget(ctx, client, speed, target, topic)
+					}
+					sendTx = func(ctx context.Context) (*types.Receipt, error) {
+						return i.contractSvc.SendDataToTarget(ctx, target, nil, nil, topic)
+					}
+				}
+				estimate := func(speed feeSpeed) (*feeEstimate, error) {
+					client := i.contractClient()
+					if client == nil {
+						return nil, fmt.Errorf("not connected to %s", i.contractRPCEndpoint())
+					}
+					return estimateTx(client, speed)
+				}
+				// without gas the node can still notify through a relayer
+				var relay func()
+				if i.canRelay(ctx) {
+					relay = func() {
+						i.showProgressWithMessage("Sending through the relayer...")
+						go func() {
+							defer i.hideProgress()
+							// the relayer submits DataSentToTarget, which takes a topic string
+							_, topic, err := sealTopic(nodeKey, payload, recipient)
+							if err != nil {
+								i.showError(err)
+								return
+							}
+							txHash, err := i.relayDataToTarget(ctx, target, topic)
+							if err != nil {
+								i.showError(err)
+								return
+							}
+							dialog.ShowInformation("Transaction Relayed",
+								fmt.Sprintf("The relayer sent the notification for you.\nTransaction Hash: %s\n\nPayload encrypted for %s", txHash.Hex(), target.Hex()),
+								i.Window)
+						}()
+					}
+				}
+				// the user sees the fee before anything is sent
+				i.confirmFee("Transaction Fee", estimate, func(fee *feeEstimate) {
+					i.showProgressWithMessage("Sending transaction...")
+					go func() {
+						defer i.hideProgress()
 
-						// Encrypt topic
-						encryptedTopicBytes, err := encryptionUtils.EncryptData([]byte(topicData), publicKey)
+						receipt, err := sendTx(withFee(ctx, fee))
 						if err != nil {
-							i.showError(fmt.Errorf("failed to encrypt topic: %w", err))
+							i.showError(fmt.Errorf("failed to send transaction: %w", err))
 							return
 						}
 
-						// Use encrypted data directly
-						owner = encryptedOwnerBytes
-						actRef = encryptedActRefBytes
-						topic = string(encryptedTopicBytes)
-
-						// Log the sizes for verification
-						i.logger.Log(fmt.Sprintf("Original actRef: %d bytes, Encrypted: %d bytes", len(actRefBytes), len(encryptedActRefBytes)))
-						encryptionInfo = "encrypted"
-					} else {
-				*/
-				// Use plain data
-				owner = ownerAddr.Bytes() // Address as 20 bytes
-				actRef = actRefBytes      // Hex decoded bytes
-
-				// Create modified topic data: concatenate placeholder public key + 32-byte hex string
-				placeholderPublicKey := "04b753ee0222be7e1de96416b8074c095d9bda96f58cd3f942cd60911853533afa46964b640fa6a2480686656cfefca846a7facd7abbb33d437ef4384659273098"
-
-				// CHANGE BEFORE SENDING TRANSACTION
-				placeholder32ByteHex := "b12c1997de882193ff595660cd9bbb6d7908bae89634e390014b746ed3aac272"
-				topic = placeholderPublicKey + placeholder32ByteHex
-				i.logger.Log(fmt.Sprintf("Modified topic data: publicKey(%d chars) + 32byteHex(%d chars) = %d total chars", len(placeholderPublicKey), len(placeholder32ByteHex), len(topic)))
-
-				i.logger.Log("Transaction data sent without encryption")
-				// }
-
-				ctx := context.Background()
-				receipt, err := i.contractSvc.SendDataToTarget(ctx, target, owner, actRef, topic)
-				if err != nil {
-					i.showError(fmt.Errorf("failed to send transaction: %w", err))
-					return
-				}
-
-				// Show success message with transaction hash and encryption info
-				successMsg := fmt.Sprintf("Transaction sent successfully!\nTransaction Hash: %s\nBlock Number: %d\n\nEncryption: %s",
-					receipt.TxHash.Hex(), receipt.BlockNumber.Uint64(), encryptionInfo)
+						// Show success message with transaction hash
+						successMsg := fmt.Sprintf("Transaction sent successfully!\nTransaction Hash: %s\nBlock Number: %d\n\nPayload encrypted for %s",
+							receipt.TxHash.Hex(), receipt.BlockNumber.Uint64(), target.Hex())
 
-				dialog.ShowInformation("Transaction Success", successMsg, i.Window)
-				i.logger.Log(fmt.Sprintf("Transaction successful: %s (%s)", receipt.TxHash.Hex(), encryptionInfo))
+						dialog.ShowInformation("Transaction Success", successMsg, i.Window)
+						i.logger.Log(fmt.Sprintf("Transaction successful: %s", receipt.TxHash.Hex()))
+					}()
+				}, relay)
 			}()
 		}, i.Window)
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -27,6 +28,7 @@ func (i *index) showInfoCard(ultraLightMode bool) *widget.Card {
 		infoContent = container.NewVBox(addressContent, pubkeyContent, stampsContent, buyBatchButton)
 	}
 	infoContent.Add(walletDataButton)
+	infoContent.Add(i.recoveryPhraseButton())
 
 	infoCard := widget.NewCard("Info",
 		fmt.Sprintf("Connected with %d peers", i.bl.ConnectedPeerCount()), infoContent)
@@ -60,6 +62,42 @@ func (i *index) walletDataButton() *widget.Button {
 	return button
 }
 
+func (i *index) recoveryPhraseButton() *widget.Button {
+	button := widget.NewButton("Show recovery phrase", func() {
+		dialog.ShowConfirm("Recovery phrase",
+			"Anyone who sees the recovery phrase can act as this node and read everything shared with it.\nMake sure nobody is watching your screen.",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				key, err := i.nodePrivateKey()
+				if err != nil {
+					i.showError(err)
+					return
+				}
+				phrase, err := recoveryPhrase(key)
+				if err != nil {
+					i.showError(fmt.Errorf("failed to create recovery phrase: %w", err))
+					return
+				}
+
+				words := container.NewGridWithColumns(3)
+				for n, word := range strings.Fields(phrase) {
+					words.Add(widget.NewLabel(fmt.Sprintf("%d. %s", n+1, word)))
+				}
+				content := container.NewVBox(
+					widget.NewLabel("Write these words down in order and keep them offline.\nThey restore this identity on a new device."),
+					words,
+				)
+				d := dialog.NewCustom("Recovery phrase", "Done", content, i.Window)
+				d.Show()
+			}, i.Window)
+	})
+	button.Importance = widget.DangerImportance
+
+	return button
+}
+
 func (i *index) addressContent() *fyne.Container {
 	addrCopyButton := i.copyButton(i.bl.OverlayEthAddress().String())
 	addrHeader := container.NewHBox(widget.NewLabel("Overlay address:"))
@@ -0,0 +1,212 @@
+package screens
+
+/*
+Network Registry
+
+Everything that differs between the chains the app runs on: the chain, its
+default RPC endpoint and explorer, the deployed contract and the Swarm network
+bee joins on it. Each network is a deployment JSON file in networks/, which the
+deploy scripts in contract/scripts update after a deployment.
+
+The network is picked once, before the node starts, and every component reads
+it through index.network so they cannot disagree.
+*/
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	networkPrefKey     = "network"
+	defaultNetworkName = "gnosis"
+)
+
+//go:embed networks/*.json
+var networkFiles embed.FS
+
+// network is a chain the app can run on, read from its registry entry. The
+// contract fields of an entry are synced from the deployment record in
+// contract/deployments by contract/scripts/networkRegistry.ts.
+type network struct {
+	Name            string         `json:"network"`
+	ChainID         uint64         `json:"chainId"`
+	ContractName    string         `json:"contractName"`
+	ContractAddress common.Address `json:"contractAddress"`
+	// BlockNumber is where the contract was deployed, 0 if the deployment
+	// did not record it, then it is looked up by TransactionHash. One of the
+	// two is required, events are read from there.
+	BlockNumber     uint64      `json:"blockNumber"`
+	TransactionHash common.Hash `json:"transactionHash"`
+	RPCURL          string      `json:"rpcUrl"`
+	ExplorerURL     string      `json:"explorerUrl"`
+	// GroupRegistryAddress is the group registry, zero where none is deployed
+	GroupRegistryAddress common.Address `json:"groupRegistryAddress"`
+	// RelayerURL is the default relayer of gasless notifications, if any
+	RelayerURL     string   `json:"relayerUrl"`
+	NativeToken    string   `json:"nativeToken"`
+	SwarmToken     string   `json:"swarmToken"`
+	SwarmNetworkID uint64   `json:"swarmNetworkId"`
+	SwarmMainnet   bool     `json:"swarmMainnet"`
+	Bootnodes      []string `json:"bootnodes"`
+}
+
+func (n *network) validate() error {
+	switch {
+	case n.Name == "":
+		return errors.New("network name is missing")
+	case n.ChainID == 0:
+		return fmt.Errorf("%s: chain id is missing", n.Name)
+	case n.ContractAddress == (common.Address{}):
+		return fmt.Errorf("%s: contract address is missing", n.Name)
+	case n.BlockNumber == 0 && n.TransactionHash == (common.Hash{}):
+		return fmt.Errorf("%s: deploy block and transaction hash are missing", n.Name)
+	case n.SwarmNetworkID == 0:
+		return fmt.Errorf("%s: swarm network id is missing", n.Name)
+	case len(n.Bootnodes) == 0:
+		return fmt.Errorf("%s: bootnodes are missing", n.Name)
+	}
+	return nil
+}
+
+// txURL links to a transaction on the network's explorer
+func (n *network) txURL(hash common.Hash) string {
+	if n.ExplorerURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/tx/%s", n.ExplorerURL, hash.Hex())
+}
+
+// parseNetworks reads the deployment JSON files of a registry directory
+func parseNetworks(files embed.FS, dir string) (map[string]*network, error) {
+	entries, err := files.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	networks := map[string]*network{}
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		n := &network{}
+		if err := json.Unmarshal(data, n); err != nil {
+			return nil, fmt.Errorf("invalid network file %s: %w", entry.Name(), err)
+		}
+		if err := n.validate(); err != nil {
+			return nil, fmt.Errorf("invalid network file %s: %w", entry.Name(), err)
+		}
+		if _, ok := networks[n.Name]; ok {
+			return nil, fmt.Errorf("network %s is defined twice", n.Name)
+		}
+		networks[n.Name] = n
+	}
+	return networks, nil
+}
+
+// networks is the registry built into the app
+var networks = sync.OnceValues(func() (map[string]*network, error) {
+	return parseNetworks(networkFiles, "networks")
+})
+
+// networkNames lists the registered networks, sorted
+func networkNames() []string {
+	registry, err := networks()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func networkByName(name string) (*network, error) {
+	registry, err := networks()
+	if err != nil {
+		return nil, err
+	}
+	n, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %q", name)
+	}
+	return n, nil
+}
+
+// network returns the selected network, the default one if the selection
+// is not in the registry
+func (i *index) network() *network {
+	name := i.nodeConfig.network
+	if name == "" {
+		name = defaultNetworkName
+	}
+	n, err := networkByName(name)
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Using network %s: %v", defaultNetworkName, err))
+		if n, err = networkByName(defaultNetworkName); err != nil {
+			// the registry is built in, it is only broken by a bad build
+			panic(err)
+		}
+	}
+	return n
+}
+
+// chainIDClient is the part of the chain client checking the network needs
+type chainIDClient interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// checkChain fails unless the client is connected to the network's chain
+func (n *network) checkChain(ctx context.Context, client chainIDClient) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %w", err)
+	}
+	if !chainID.IsUint64() || chainID.Uint64() != n.ChainID {
+		return fmt.Errorf("rpc endpoint is on chain %s, but %s is chain %d", chainID, n.Name, n.ChainID)
+	}
+	return nil
+}
+
+// receiptClient is the part of the chain client finding the deploy block needs
+type receiptClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// deployBlock returns the block the contract was deployed in, looking it up
+// when the deployment did not record it. A failed lookup is an error, reading
+// events from genesis instead would take thousands of requests.
+func (n *network) deployBlock(ctx context.Context, client receiptClient) (uint64, error) {
+	if n.BlockNumber != 0 {
+		return n.BlockNumber, nil
+	}
+	if n.TransactionHash == (common.Hash{}) {
+		return 0, fmt.Errorf("%s: deploy block unknown", n.Name)
+	}
+	if client == nil {
+		return 0, errors.New("ethclient.Client is nil")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	receipt, err := client.TransactionReceipt(ctx, n.TransactionHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up the deploy block of %s: %w", n.Name, err)
+	}
+	if receipt.BlockNumber == nil {
+		return 0, fmt.Errorf("failed to look up the deploy block of %s: receipt without block", n.Name)
+	}
+	return receipt.BlockNumber.Uint64(), nil
+}
@@ -0,0 +1,114 @@
+package screens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestNetworkRegistry(t *testing.T) {
+	registry, err := networks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := registry[defaultNetworkName]; !ok {
+		t.Fatalf("default network %s is not registered", defaultNetworkName)
+	}
+	chains := map[uint64]string{}
+	for name, n := range registry {
+		if other, ok := chains[n.ChainID]; ok {
+			t.Errorf("%s and %s are both chain %d", name, other, n.ChainID)
+		}
+		chains[n.ChainID] = name
+		if n.RPCURL == "" || n.ExplorerURL == "" || n.NativeToken == "" || n.SwarmToken == "" {
+			t.Errorf("%s is missing endpoints or token symbols: %+v", name, n)
+		}
+		if n.BlockNumber == 0 && n.TransactionHash == (common.Hash{}) {
+			t.Errorf("%s has neither a deploy block nor a deploy transaction", name)
+		}
+	}
+	if got := networkNames(); len(got) != len(registry) {
+		t.Fatalf("listed %v", got)
+	}
+}
+
+// TestNetworkRegistryMatchesDeployments checks every entry with a deployment
+// record agrees with it, the records are the source of the contract fields
+func TestNetworkRegistryMatchesDeployments(t *testing.T) {
+	registry, err := networks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, n := range registry {
+		data, err := os.ReadFile(filepath.Join("..", "contract", "deployments", name+"-deployment.json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var deployment network
+		if err := json.Unmarshal(data, &deployment); err != nil {
+			t.Fatalf("%s deployment record: %v", name, err)
+		}
+		if n.ChainID != deployment.ChainID || n.ContractAddress != deployment.ContractAddress || n.BlockNumber != deployment.BlockNumber ||
+			n.TransactionHash != deployment.TransactionHash || n.GroupRegistryAddress != deployment.GroupRegistryAddress {
+			t.Errorf("%s registry entry differs from its deployment record, run npm run registry:sync in contract", name)
+		}
+	}
+}
+
+func TestNetworkCheckChain(t *testing.T) {
+	gnosis, err := networkByName("gnosis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fakeTxClient is on chain 100
+	if err := gnosis.checkChain(context.Background(), &fakeTxClient{}); err != nil {
+		t.Fatal(err)
+	}
+	sepolia, err := networkByName("sepolia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sepolia.checkChain(context.Background(), &fakeTxClient{}); err == nil || !strings.Contains(err.Error(), "sepolia") {
+		t.Fatalf("got %v, want a chain mismatch", err)
+	}
+	if _, err := networkByName("unknown"); err == nil {
+		t.Fatal("found an unknown network")
+	}
+}
+
+func TestNetworkDeployBlock(t *testing.T) {
+	n := &network{TransactionHash: common.Hash{1}}
+	client := &fakeTxClient{receipts: map[common.Hash]*types.Receipt{
+		{1}: {TxHash: common.Hash{1}, BlockNumber: big.NewInt(1234)},
+	}}
+	if got, err := n.deployBlock(context.Background(), client); err != nil || got != 1234 {
+		t.Fatalf("deploy block %d, %v, want it from the receipt", got, err)
+	}
+	// a failed lookup does not start from genesis
+	n.TransactionHash = common.Hash{2}
+	if got, err := n.deployBlock(context.Background(), client); err == nil {
+		t.Fatalf("deploy block %d without a receipt", got)
+	}
+	if got, err := n.deployBlock(context.Background(), nil); err == nil {
+		t.Fatalf("deploy block %d without a client", got)
+	}
+	n.BlockNumber = 99
+	if got, err := n.deployBlock(context.Background(), client); err != nil || got != 99 {
+		t.Fatalf("deploy block %d, %v, want the recorded one", got, err)
+	}
+	if got := n.txURL(common.Hash{}); got != "" {
+		t.Fatalf("explorer link %q without an explorer", got)
+	}
+}
@@ -0,0 +1,42 @@
+package screens
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethersphere/bee/v2/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+)
+
+const swarmKeyName = "swarm"
+
+var errNodeKeyUnavailable = errors.New("node key is not available with an in-memory keystore")
+
+// nodePrivateKey loads the swarm key bee-lite uses from the keystore in the data dir
+func (i *index) nodePrivateKey() (*ecdsa.PrivateKey, error) {
+	if i.nodeKey != nil {
+		return i.nodeKey, nil
+	}
+	if i.nodeConfig.isKeyStoreMem || i.nodeConfig.path == "" {
+		return nil, errNodeKeyUnavailable
+	}
+
+	keystore := filekeystore.New(filepath.Join(i.nodeConfig.path, "keys"))
+	exists, err := keystore.Exists(swarmKeyName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("swarm key not found in %s", i.nodeConfig.path)
+	}
+
+	key, _, err := keystore.Key(swarmKeyName, i.nodeConfig.password, crypto.EDGSecp256_K1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load swarm key: %w", err)
+	}
+
+	i.nodeKey = key
+	return key, nil
+}
@@ -0,0 +1,112 @@
+package screens
+
+/*
+Compact Notifications
+
+DataSentToTargetV2 carries a share payload as raw bytes next to typed fields,
+instead of the hex topic strings of DataSentToTarget, which took twice the
+calldata per encoding and left the receiver guessing at offsets:
+
+	version       uint8    what the payload holds
+	publisherKey  bytes    compressed publisher key, empty if the payload hides it
+	historyRef    bytes32  ACT history reference, zero if the payload hides it
+	payload       bytes
+
+Versions:
+
+	1  signed  the binary signed share payload, the bytes of an "actv1:" topic.
+	           publisherKey and historyRef repeat its publisher and history so
+	           they can be read without decoding it, and must agree with it.
+	2  sealed  an envelope (see envelope.go) encrypting the binary signed share
+	           payload to the target. publisherKey and historyRef stay empty,
+	           the chain only shows that someone notified the target.
+
+The app sends sealed notifications. On deployments without
+sendDataToTargetV2 it sends the same signed payload as an encrypted
+DataSentToTarget topic. DataSentToTarget events, from those, earlier builds and
+the relayer, are still decoded.
+*/
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// notificationVersion is the typed version field of DataSentToTargetV2
+type notificationVersion uint8
+
+const (
+	notificationSigned notificationVersion = iota + 1
+	notificationSealed
+)
+
+func (v notificationVersion) String() string {
+	switch v {
+	case notificationSigned:
+		return "signed"
+	case notificationSealed:
+		return "sealed"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(v))
+	}
+}
+
+// notification is what a DataSentToTargetV2 event carries
+type notification struct {
+	Version      notificationVersion
+	PublisherKey []byte
+	HistoryRef   common.Hash
+	Payload      []byte
+}
+
+// sealedNotification encrypts a signed payload to the target's public key
+func (e *EncryptionUtils) sealedNotification(p *sharePayload, target *ecdsa.PublicKey) (*notification, error) {
+	b, err := p.binary()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := e.EncryptData(b, target)
+	if err != nil {
+		return nil, err
+	}
+	return &notification{Version: notificationSealed, Payload: sealed}, nil
+}
+
+// openNotification decodes the payload of a notification. Sealed ones are
+// decrypted with key and fail with errWrongRecipient if addressed to another
+// key.
+func (e *EncryptionUtils) openNotification(n *notification, key *ecdsa.PrivateKey) (*sharePayload, error) {
+	switch n.Version {
+	case notificationSigned:
+		p, err := parseBinarySharePayload(n.Payload)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(n.PublisherKey, crypto.CompressPubkey(p.Publisher)) {
+			return nil, fmt.Errorf("%w: publisher key differs from the signed payload", errInvalidPayload)
+		}
+		if n.HistoryRef != (common.Hash{}) && !bytes.Equal(n.HistoryRef[:], p.History) {
+			return nil, fmt.Errorf("%w: history reference differs from the signed payload", errInvalidPayload)
+		}
+		return p, nil
+	case notificationSealed:
+		if key == nil {
+			return nil, errors.New("no key to open a sealed notification")
+		}
+		if !isEnvelope(n.Payload) {
+			return nil, fmt.Errorf("%w: not an envelope", errInvalidPayload)
+		}
+		plaintext, err := e.DecryptData(n.Payload, key)
+		if err != nil {
+			return nil, err
+		}
+		return parseBinarySharePayload(plaintext)
+	default:
+		return nil, fmt.Errorf("%w: unknown notification version %d", errInvalidPayload, uint8(n.Version))
+	}
+}
@@ -0,0 +1,93 @@
+package screens
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signedNotification carries a signed payload in the clear, with the
+// publisher and history repeated in the typed fields
+func signedNotification(t *testing.T, p *sharePayload) *notification {
+	t.Helper()
+	b, err := p.binary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &notification{
+		Version:      notificationSigned,
+		PublisherKey: crypto.CompressPubkey(p.Publisher),
+		HistoryRef:   common.BytesToHash(p.History),
+		Payload:      b,
+	}
+}
+
+func TestOpenNotification(t *testing.T) {
+	publisher := mustGenerateKey(t)
+	recipient := mustGenerateKey(t)
+	target := crypto.PubkeyToAddress(recipient.PublicKey)
+	payload := &sharePayload{Kind: payloadKindACT, Reference: bytes.Repeat([]byte{1}, 32), History: bytes.Repeat([]byte{2}, 32)}
+	if err := payload.sign(publisher, target); err != nil {
+		t.Fatal(err)
+	}
+	encryptionUtils := &EncryptionUtils{}
+
+	signed := signedNotification(t, payload)
+	sealed, err := encryptionUtils.sealedNotification(payload, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a sealed notification hides everything but that the target was notified
+	if len(sealed.PublisherKey) != 0 || sealed.HistoryRef != (common.Hash{}) {
+		t.Fatalf("sealed notification shows %x, %s", sealed.PublisherKey, sealed.HistoryRef)
+	}
+
+	for _, n := range []*notification{signed, sealed} {
+		t.Run(n.Version.String(), func(t *testing.T) {
+			received, err := encryptionUtils.openNotification(n, recipient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status := received.verify(target); status != payloadVerified {
+				t.Fatalf("got a %s payload", status)
+			}
+			if !bytes.Equal(received.Reference, payload.Reference) || !bytes.Equal(received.History, payload.History) {
+				t.Fatalf("got %+v", received)
+			}
+		})
+	}
+
+	// the payload is the binary of the v1 topic, without its hex encoding
+	topic, err := payload.topic(paddingNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if 2*len(signed.Payload)+len(signedPayloadPrefix) != len(topic) {
+		t.Fatalf("payload %d bytes for a %d-char topic", len(signed.Payload), len(topic))
+	}
+
+	other := mustGenerateKey(t)
+	tests := []struct {
+		name string
+		n    *notification
+	}{
+		{"other publisher key", &notification{Version: notificationSigned, PublisherKey: crypto.CompressPubkey(&other.PublicKey), HistoryRef: signed.HistoryRef, Payload: signed.Payload}},
+		{"other history", &notification{Version: notificationSigned, PublisherKey: signed.PublisherKey, HistoryRef: common.Hash{9}, Payload: signed.Payload}},
+		{"truncated payload", &notification{Version: notificationSigned, PublisherKey: signed.PublisherKey, Payload: signed.Payload[:40]}},
+		{"sealed payload not an envelope", &notification{Version: notificationSealed, Payload: signed.Payload}},
+		{"unknown version", &notification{Version: 9, Payload: signed.Payload}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := encryptionUtils.openNotification(tt.n, recipient); !errors.Is(err, errInvalidPayload) {
+				t.Fatalf("opened %+v, %v", p, err)
+			}
+		})
+	}
+	if _, err := encryptionUtils.openNotification(sealed, other); !errors.Is(err, errWrongRecipient) {
+		t.Fatalf("opened a notification sealed for another key: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package screens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethersphere/bee/v2/pkg/swarm"
+)
+
+// sealNotification signs payload for the recipient's address and encrypts it
+// to the recipient, returning the notification to send on chain. The payload
+// is copied, so one payload can be sealed for several recipients.
+func sealNotification(nodeKey *ecdsa.PrivateKey, payload sharePayload, recipient *ecdsa.PublicKey) (common.Address, *notification, error) {
+	target := crypto.PubkeyToAddress(*recipient)
+	if err := payload.sign(nodeKey, target); err != nil {
+		return target, nil, fmt.Errorf("failed to sign payload: %w", err)
+	}
+	// Only the target can read who shares what, the publisher key and history
+	// stay empty on chain
+	encryptionUtils := &EncryptionUtils{}
+	n, err := encryptionUtils.sealedNotification(&payload, recipient)
+	if err != nil {
+		return target, nil, fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+	return target, n, nil
+}
+
+// sealTopic is sealNotification for DataSentToTarget, as an encrypted topic
+// string. The relayer only submits these.
+func sealTopic(nodeKey *ecdsa.PrivateKey, payload sharePayload, recipient *ecdsa.PublicKey) (common.Address, string, error) {
+	target := crypto.PubkeyToAddress(*recipient)
+	if err := payload.sign(nodeKey, target); err != nil {
+		return target, "", fmt.Errorf("failed to sign payload: %w", err)
+	}
+	signedTopic, err := payload.topic(paddingNone)
+	if err != nil {
+		return target, "", err
+	}
+	encryptionUtils := &EncryptionUtils{}
+	topic, err := encryptionUtils.encryptTopic(signedTopic, recipient)
+	if err != nil {
+		return target, "", fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+	return target, topic, nil
+}
+
+// sendSealed signs payload for the recipient and sends it as a compact
+// notification, or as an encrypted DataSentToTarget topic on deployments
+// without sendDataToTargetV2
+func (i *index) sendSealed(ctx context.Context, nodeKey *ecdsa.PrivateKey, payload sharePayload, recipient *ecdsa.PublicKey) (*types.Receipt, error) {
+	if !i.contractHas(ctx, "sendDataToTargetV2") {
+		target, topic, err := sealTopic(nodeKey, payload, recipient)
+		if err != nil {
+			return nil, err
+		}
+		return i.contractSvc.SendDataToTarget(ctx, target, nil, nil, topic)
+	}
+	target, n, err := sealNotification(nodeKey, payload, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return i.contractSvc.SendNotification(ctx, target, n)
+}
+
+// notifyError is a notifyMembers run that stopped at a member. The members
+// before Failed were notified in the transactions returned with it.
+type notifyError struct {
+	Failed int // index of the first recipient not notified
+	err    error
+}
+
+func (e *notifyError) Error() string { return e.err.Error() }
+func (e *notifyError) Unwrap() error { return e.err }
+
+// notifyMembers sends payload to every recipient, batching as many as the
+// contract accepts into each transaction, or one transaction per recipient on
+// deployments without sendDataToTargetsV2. It returns the transaction hashes,
+// those sent before a failure with a *notifyError.
+func (i *index) notifyMembers(ctx context.Context, payload sharePayload, recipients []*ecdsa.PublicKey) ([]common.Hash, error) {
+	nodeKey, err := i.nodePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign payload: %w", err)
+	}
+
+	var txHashes []common.Hash
+	if !i.contractHas(ctx, "sendDataToTargetsV2") {
+		i.logger.Log("The data contract has no sendDataToTargetsV2, notifying members one by one")
+		for n, recipient := range recipients {
+			receipt, err := i.sendSealed(ctx, nodeKey, payload, recipient)
+			if err != nil {
+				return txHashes, &notifyError{Failed: n, err: fmt.Errorf("failed to notify member %d: %w", n+1, err)}
+			}
+			txHashes = append(txHashes, receipt.TxHash)
+		}
+		return txHashes, nil
+	}
+
+	targets := make([]common.Address, 0, len(recipients))
+	notifications := make([]*notification, 0, len(recipients))
+	for _, recipient := range recipients {
+		target, n, err := sealNotification(nodeKey, payload, recipient)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+		notifications = append(notifications, n)
+	}
+	for start := 0; start < len(targets); start += maxNotifyTargets {
+		end := min(start+maxNotifyTargets, len(targets))
+		receipt, err := i.contractSvc.SendNotifications(ctx, targets[start:end], notifications[start:end])
+		if err != nil {
+			return txHashes, &notifyError{Failed: start, err: fmt.Errorf("failed to notify members %d to %d: %w", start+1, end, err)}
+		}
+		i.logger.Log(fmt.Sprintf("Notified %d members in transaction %s", end-start, receipt.TxHash.Hex()))
+		txHashes = append(txHashes, receipt.TxHash)
+	}
+	return txHashes, nil
+}
+
+// notifyMembersButton sends a signed payload to every grantee of the list
+func (i *index) notifyMembersButton() *widget.Button {
+	return widget.NewButton("Notify All Members", func() {
+		if i.contractSvc == nil {
+			i.showError(fmt.Errorf("contract service not initialized"))
+			return
+		}
+
+		actRefEntry := widget.NewEntry()
+		actRefEntry.SetPlaceHolder("ACT reference (hex string)")
+		actRefEntry.SetText(i.getPreferenceString(historyRefPrefKey))
+
+		referenceEntry := widget.NewEntry()
+		referenceEntry.SetPlaceHolder("Swarm reference (hex string)")
+
+		kindSelect := widget.NewSelect([]string{payloadKindACT.String(), payloadKindMessage.String(), payloadKindInvite.String()}, nil)
+		kindSelect.SetSelectedIndex(0)
+
+		messageEntry := widget.NewEntry()
+		messageEntry.SetPlaceHolder("Message")
+
+		form := &widget.Form{
+			Items: []*widget.FormItem{
+				widget.NewFormItem("ACT Reference", actRefEntry),
+				widget.NewFormItem("Payload", kindSelect),
+				widget.NewFormItem("Swarm Reference", referenceEntry),
+				widget.NewFormItem("Message", messageEntry),
+			},
+		}
+
+		d := dialog.NewCustomConfirm("Notify All Members", "Send", "Cancel", form, func(confirm bool) {
+			if !confirm {
+				return
+			}
+
+			actRefBytes, err := hex.DecodeString(strings.TrimPrefix(actRefEntry.Text, "0x"))
+			if err != nil {
+				i.showError(fmt.Errorf("ACT reference must be valid hex string: %w", err))
+				return
+			}
+			referenceBytes, err := hex.DecodeString(strings.TrimPrefix(referenceEntry.Text, "0x"))
+			if err != nil || (len(referenceBytes) != 0 && len(referenceBytes) != swarm.HashSize && len(referenceBytes) != 2*swarm.HashSize) {
+				i.showError(fmt.Errorf("swarm reference must be a 32 or 64 byte hex string"))
+				return
+			}
+
+			payload := sharePayload{
+				Kind:      payloadKind(kindSelect.SelectedIndex() + 1),
+				Reference: referenceBytes,
+				History:   actRefBytes,
+			}
+			if payload.Kind != payloadKindACT {
+				payload.Body = []byte(messageEntry.Text)
+			}
+
+			i.showProgressWithMessage("Notifying members...")
+			go func() {
+				defer i.hideProgress()
+
+				ctx := context.Background()
+				members, err := i.granteePublicKeys(ctx)
+				if err != nil {
+					i.showError(err)
+					return
+				}
+				if len(members) == 0 {
+					i.showError(fmt.Errorf("the grantee list has no members to notify"))
+					return
+				}
+
+				txHashes, err := i.notifyMembers(ctx, payload, members)
+				hashes := make([]string, len(txHashes))
+				for n, h := range txHashes {
+					hashes[n] = h.Hex()
+				}
+				var partial *notifyError
+				if errors.As(err, &partial) && partial.Failed > 0 {
+					i.showError(fmt.Errorf("%w\nMembers 1 to %d of %d were notified in %d transaction(s):\n%s",
+						err, partial.Failed, len(members), len(txHashes), strings.Join(hashes, "\n")))
+					return
+				}
+				if err != nil {
+					i.showError(err)
+					return
+				}
+				dialog.ShowInformation("Members Notified",
+					fmt.Sprintf("Notified %d members in %d transaction(s):\n%s", len(members), len(txHashes), strings.Join(hashes, "\n")),
+					i.Window)
+			}()
+		}, i.Window)
+
+		d.Resize(fyne.NewSize(600, 300))
+		d.Show()
+	})
+}
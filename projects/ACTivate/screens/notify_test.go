@@ -0,0 +1,190 @@
+package screens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeDataContract records the notifications and topics it is asked to send.
+// The transaction numbered fail, counting from 1, fails.
+type fakeDataContract struct {
+	fail          int
+	batches       [][]common.Address
+	notifications map[common.Address]*notification
+	topics        map[common.Address]string
+}
+
+func (f *fakeDataContract) SendDataToTarget(ctx context.Context, target common.Address, owner, actRef []byte, topic string) (*types.Receipt, error) {
+	if f.topics == nil {
+		return nil, errors.New("sent a DataSentToTarget topic")
+	}
+	f.topics[target] = topic
+	return &types.Receipt{TxHash: common.Hash{byte(len(f.topics))}}, nil
+}
+
+func (f *fakeDataContract) SendDataToTargets(ctx context.Context, targets []common.Address, owner, actRef []byte, topics []string) (*types.Receipt, error) {
+	return nil, errors.New("sent DataSentToTarget topics")
+}
+
+func (f *fakeDataContract) SendNotification(ctx context.Context, target common.Address, n *notification) (*types.Receipt, error) {
+	return f.SendNotifications(ctx, []common.Address{target}, []*notification{n})
+}
+
+func (f *fakeDataContract) SendNotifications(ctx context.Context, targets []common.Address, notifications []*notification) (*types.Receipt, error) {
+	if len(f.batches)+1 == f.fail {
+		return nil, errors.New("transaction reverted")
+	}
+	f.batches = append(f.batches, targets)
+	for n, target := range targets {
+		f.notifications[target] = notifications[n]
+	}
+	return &types.Receipt{TxHash: common.Hash{byte(len(f.batches))}}, nil
+}
+
+func (f *fakeDataContract) EstimateSendNotification(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, n *notification) (*feeEstimate, error) {
+	return nil, nil
+}
+
+func (f *fakeDataContract) EstimateSendDataToTarget(ctx context.Context, client feeClient, speed feeSpeed, target common.Address, topic string) (*feeEstimate, error) {
+	return nil, nil
+}
+
+func (f *fakeDataContract) SubscribeDataSentToTarget(ctx context.Context, client logClient, recipients []common.Address, from logCheckpoint, sink chan<- streamedLog) (ethereum.Subscription, error) {
+	return nil, nil
+}
+
+func TestNotifyMembers(t *testing.T) {
+	publisher := mustGenerateKey(t)
+	contract := &fakeDataContract{notifications: make(map[common.Address]*notification)}
+	i := &index{logger: &logger{}, nodeKey: publisher, contractSvc: contract, contractFunctions: map[string]bool{"sendDataToTargetsV2": true}}
+
+	members := make([]*ecdsa.PrivateKey, maxNotifyTargets*2+5)
+	recipients := make([]*ecdsa.PublicKey, len(members))
+	for n := range members {
+		members[n] = mustGenerateKey(t)
+		recipients[n] = &members[n].PublicKey
+	}
+
+	payload := sharePayload{Kind: payloadKindACT, Reference: make([]byte, 32), History: make([]byte, 32)}
+	txHashes, err := i.notifyMembers(context.Background(), payload, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txHashes) != 3 || len(contract.batches[0]) != maxNotifyTargets || len(contract.batches[2]) != 5 {
+		t.Fatalf("expected batches of %d, %d and 5, got %d transactions", maxNotifyTargets, maxNotifyTargets, len(txHashes))
+	}
+
+	// every member reads a payload signed for its own address
+	encryptionUtils := &EncryptionUtils{}
+	for _, member := range []*ecdsa.PrivateKey{members[0], members[maxNotifyTargets], members[len(members)-1]} {
+		target := crypto.PubkeyToAddress(member.PublicKey)
+		received, err := encryptionUtils.openNotification(contract.notifications[target], member)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := received.verify(target); status != payloadVerified {
+			t.Fatalf("member %s got a %s payload", target.Hex(), status)
+		}
+	}
+
+	// a member cannot pass its payload off as sent to another member
+	other := crypto.PubkeyToAddress(members[1].PublicKey)
+	received, err := encryptionUtils.openNotification(contract.notifications[crypto.PubkeyToAddress(members[0].PublicKey)], members[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.verify(other) == payloadVerified {
+		t.Fatal("payload verifies for another member")
+	}
+}
+
+func TestNotifyMembersWithoutBatch(t *testing.T) {
+	contract := &fakeDataContract{notifications: make(map[common.Address]*notification)}
+	// deployed before sendDataToTargetsV2
+	i := &index{logger: &logger{}, nodeKey: mustGenerateKey(t), contractSvc: contract, contractFunctions: map[string]bool{"sendDataToTarget": true, "sendDataToTargetV2": true}}
+
+	recipients := []*ecdsa.PublicKey{&mustGenerateKey(t).PublicKey, &mustGenerateKey(t).PublicKey, &mustGenerateKey(t).PublicKey}
+	payload := sharePayload{Kind: payloadKindACT, Reference: make([]byte, 32), History: make([]byte, 32)}
+	txHashes, err := i.notifyMembers(context.Background(), payload, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txHashes) != len(recipients) || len(contract.notifications) != len(recipients) {
+		t.Fatalf("sent %d transactions for %d members", len(txHashes), len(recipients))
+	}
+	for _, batch := range contract.batches {
+		if len(batch) != 1 {
+			t.Fatalf("sent a batch of %d", len(batch))
+		}
+	}
+}
+
+func TestNotifyMembersPartialFailure(t *testing.T) {
+	recipients := make([]*ecdsa.PublicKey, maxNotifyTargets+3)
+	for n := range recipients {
+		recipients[n] = &mustGenerateKey(t).PublicKey
+	}
+	payload := sharePayload{Kind: payloadKindACT, Reference: make([]byte, 32), History: make([]byte, 32)}
+	for _, test := range []struct {
+		name      string
+		functions map[string]bool
+		failed    int
+	}{
+		{"batched", map[string]bool{"sendDataToTargetsV2": true}, maxNotifyTargets},
+		{"one by one", map[string]bool{"sendDataToTargetV2": true}, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			contract := &fakeDataContract{fail: 2, notifications: make(map[common.Address]*notification)}
+			i := &index{logger: &logger{}, nodeKey: mustGenerateKey(t), contractSvc: contract, contractFunctions: test.functions}
+			txHashes, err := i.notifyMembers(context.Background(), payload, recipients)
+			var partial *notifyError
+			if !errors.As(err, &partial) || partial.Failed != test.failed {
+				t.Fatalf("expected to stop at member %d, got %v", test.failed, err)
+			}
+			if len(txHashes) != 1 {
+				t.Fatalf("got %d transactions sent before the failure", len(txHashes))
+			}
+		})
+	}
+}
+
+func TestNotifyMembersV1Contract(t *testing.T) {
+	contract := &fakeDataContract{notifications: make(map[common.Address]*notification), topics: make(map[common.Address]string)}
+	// deployed before the compact notifications
+	i := &index{logger: &logger{}, nodeKey: mustGenerateKey(t), contractSvc: contract, contractFunctions: map[string]bool{"sendDataToTarget": true}}
+
+	members := []*ecdsa.PrivateKey{mustGenerateKey(t), mustGenerateKey(t)}
+	recipients := []*ecdsa.PublicKey{&members[0].PublicKey, &members[1].PublicKey}
+	payload := sharePayload{Kind: payloadKindACT, Reference: make([]byte, 32), History: make([]byte, 32)}
+	txHashes, err := i.notifyMembers(context.Background(), payload, recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txHashes) != len(members) || len(contract.notifications) != 0 {
+		t.Fatalf("sent %d transactions, %d compact notifications", len(txHashes), len(contract.notifications))
+	}
+
+	// the members read the signed payload from the encrypted topic
+	encryptionUtils := &EncryptionUtils{}
+	for _, member := range members {
+		target := crypto.PubkeyToAddress(member.PublicKey)
+		topic, err := encryptionUtils.decryptTopic(contract.topics[target], member)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received, err := parseSharePayload(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := received.verify(target); status != payloadVerified {
+			t.Fatalf("member %s got a %s payload", target.Hex(), status)
+		}
+	}
+}
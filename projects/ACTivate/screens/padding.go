@@ -0,0 +1,293 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+/*
+Length-Hiding Padding
+
+Ciphertexts are as long as their plaintexts, which tells an observer what kind
+of document or message is being shared. Before encryption the plaintext is
+padded up to a bucket size chosen by the padding policy:
+
+  - Padmé rounds up by dropping the low bits of the length, leaking
+    O(log log n) bits with at most 12% overhead
+  - power of two rounds up to the next power of two, leaking O(log log n) bits
+    with up to 100% overhead but far fewer distinct sizes
+
+Padding follows ISO/IEC 7816-4: a 0x80 marker byte and then zero bytes. The
+marker is always present, so padded data can be stripped without knowing the
+policy it was padded with. Formats tell padded from unpadded plaintexts by
+their version byte (envelope and stream version 2, "actv2:" topics).
+*/
+
+const (
+	paddingMarker        byte = 0x80
+	paddingPolicyPrefKey      = "paddingPolicy"
+)
+
+var errInvalidPadding = errors.New("invalid padding")
+
+// paddingPolicy decides the padded size of a plaintext
+type paddingPolicy int
+
+const (
+	paddingNone paddingPolicy = iota
+	paddingPadme
+	paddingPowerOfTwo
+)
+
+// defaultPaddingPolicy is used until the user picks one
+const defaultPaddingPolicy = paddingPadme
+
+var paddingPolicyNames = map[paddingPolicy]string{
+	paddingNone:       "none",
+	paddingPadme:      "padme",
+	paddingPowerOfTwo: "pow2",
+}
+
+func (p paddingPolicy) String() string {
+	if name, ok := paddingPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", int(p))
+}
+
+// parsePaddingPolicy returns the policy stored under name
+func parsePaddingPolicy(name string) (paddingPolicy, error) {
+	for policy, n := range paddingPolicyNames {
+		if n == name {
+			return policy, nil
+		}
+	}
+	return paddingNone, fmt.Errorf("unknown padding policy %q", name)
+}
+
+// paddedSize returns the bucket a plaintext of n bytes, marker included, is padded to
+func (p paddingPolicy) paddedSize(n int64) int64 {
+	if n <= 2 {
+		return n
+	}
+	switch p {
+	case paddingPadme:
+		e := 63 - bits.LeadingZeros64(uint64(n))
+		s := 64 - bits.LeadingZeros64(uint64(e))
+		mask := int64(1)<<(e-s) - 1
+		return (n + mask) &^ mask
+	case paddingPowerOfTwo:
+		return int64(1) << (64 - bits.LeadingZeros64(uint64(n-1)))
+	default:
+		return n
+	}
+}
+
+// pad appends the marker and zeros up to the policy's bucket size
+func (p paddingPolicy) pad(data []byte) []byte {
+	size := p.paddedSize(int64(len(data)) + 1)
+	padded := make([]byte, size)
+	copy(padded, data)
+	padded[len(data)] = paddingMarker
+	return padded
+}
+
+// unpad strips the padding added by pad
+func unpad(data []byte) ([]byte, error) {
+	for n := len(data) - 1; n >= 0; n-- {
+		switch data[n] {
+		case 0:
+		case paddingMarker:
+			return data[:n], nil
+		default:
+			return nil, errInvalidPadding
+		}
+	}
+	return nil, errInvalidPadding
+}
+
+// padWriter pads everything written through it when closed
+type padWriter struct {
+	w      io.WriteCloser
+	policy paddingPolicy
+	n      int64
+}
+
+func newPadWriter(w io.WriteCloser, policy paddingPolicy) *padWriter {
+	return &padWriter{w: w, policy: policy}
+}
+
+func (p *padWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.n += int64(n)
+	return n, err
+}
+
+// Close writes the padding and closes the underlying writer
+func (p *padWriter) Close() error {
+	size := p.policy.paddedSize(p.n + 1)
+	if _, err := p.w.Write([]byte{paddingMarker}); err != nil {
+		return err
+	}
+
+	zeros := make([]byte, min(size-p.n-1, 32*1024))
+	for remaining := size - p.n - 1; remaining > 0; {
+		chunk := zeros[:min(remaining, int64(len(zeros)))]
+		if _, err := p.w.Write(chunk); err != nil {
+			return err
+		}
+		remaining -= int64(len(chunk))
+	}
+	return p.w.Close()
+}
+
+// unpadReader strips padding from a stream. A possible marker and the zeros
+// after it are held back as a counter until it is known whether they are
+// padding or data, so memory use does not grow with the padding.
+type unpadReader struct {
+	r   io.Reader
+	buf []byte
+
+	// held back marker and zeros that may turn out to be padding
+	heldMarker bool
+	heldZeros  int64
+
+	// output ready to be returned, in order
+	emitMarker bool
+	emitZeros  int64
+	emitData   []byte
+	eof        bool
+}
+
+func newUnpadReader(r io.Reader) *unpadReader {
+	return &unpadReader{r: r, buf: make([]byte, 32*1024)}
+}
+
+func (u *unpadReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		if n := u.drain(p); n > 0 {
+			return n, nil
+		}
+		if u.eof {
+			return 0, io.EOF
+		}
+		if err := u.fill(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// drain copies pending output into p
+func (u *unpadReader) drain(p []byte) int {
+	n := 0
+	if u.emitMarker && n < len(p) {
+		p[n] = paddingMarker
+		u.emitMarker = false
+		n++
+	}
+	for u.emitZeros > 0 && n < len(p) {
+		p[n] = 0
+		u.emitZeros--
+		n++
+	}
+	if !u.emitMarker && u.emitZeros == 0 {
+		c := copy(p[n:], u.emitData)
+		u.emitData = u.emitData[c:]
+		n += c
+	}
+	return n
+}
+
+// fill reads the next chunk and decides which of it is data
+func (u *unpadReader) fill() error {
+	n, err := u.r.Read(u.buf)
+	chunk := u.buf[:n]
+
+	last := -1
+	for j := len(chunk) - 1; j >= 0; j-- {
+		if chunk[j] != 0 {
+			last = j
+			break
+		}
+	}
+
+	switch {
+	case last == -1 && u.heldMarker:
+		u.heldZeros += int64(len(chunk))
+	case last == -1:
+		// zeros without a marker before them are data
+		u.emitData = chunk
+	default:
+		// whatever was held back is followed by data, so it is data too
+		u.release()
+		if chunk[last] == paddingMarker {
+			u.emitData = chunk[:last]
+			u.heldMarker = true
+			u.heldZeros = int64(len(chunk) - last - 1)
+		} else {
+			u.emitData = chunk
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		if !u.heldMarker {
+			return errInvalidPadding
+		}
+		u.eof = true
+		return nil
+	}
+	return err
+}
+
+// release turns the held back marker and zeros into output
+func (u *unpadReader) release() {
+	if u.heldMarker {
+		u.emitMarker = true
+		u.emitZeros = u.heldZeros
+	}
+	u.heldMarker = false
+	u.heldZeros = 0
+}
+
+// encryptionUtils returns the encryption utilities configured with the
+// padding policy from preferences
+func (i *index) encryptionUtils() *EncryptionUtils {
+	policy := defaultPaddingPolicy
+	if name := i.getPreferenceString(paddingPolicyPrefKey); name != "" {
+		parsed, err := parsePaddingPolicy(name)
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Invalid padding policy preference: %v", err))
+		} else {
+			policy = parsed
+		}
+	}
+	return &EncryptionUtils{padding: policy}
+}
+
+// paddingPolicySelect lets the user pick the padding policy stored in preferences
+func (i *index) paddingPolicySelect() *widget.Select {
+	labels := []string{"Padmé", "Power of two", "None"}
+	policies := []paddingPolicy{paddingPadme, paddingPowerOfTwo, paddingNone}
+
+	sel := widget.NewSelect(labels, func(label string) {
+		for n, l := range labels {
+			if l == label {
+				i.setPreference(paddingPolicyPrefKey, policies[n].String())
+			}
+		}
+	})
+	current := i.encryptionUtils().padding
+	for n, policy := range policies {
+		if policy == current {
+			sel.SetSelectedIndex(n)
+		}
+	}
+	return sel
+}
@@ -0,0 +1,201 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPaddedSize(t *testing.T) {
+	for _, tc := range []struct {
+		policy paddingPolicy
+		n      int64
+		want   int64
+	}{
+		{paddingNone, 1000, 1000},
+		{paddingPadme, 1, 1},
+		{paddingPadme, 9, 10},
+		{paddingPadme, 100, 104},
+		{paddingPadme, 1000, 1024},
+		{paddingPadme, 1025, 1088},
+		{paddingPadme, 1 << 20, 1 << 20},
+		{paddingPadme, 1<<20 + 1, 1<<20 + 1<<15},
+		{paddingPowerOfTwo, 3, 4},
+		{paddingPowerOfTwo, 4, 4},
+		{paddingPowerOfTwo, 1000, 1024},
+		{paddingPowerOfTwo, 1025, 2048},
+	} {
+		if got := tc.policy.paddedSize(tc.n); got != tc.want {
+			t.Errorf("%s.paddedSize(%d) = %d, want %d", tc.policy, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestPadmeOverhead(t *testing.T) {
+	for n := int64(3); n < 1<<16; n++ {
+		size := paddingPadme.paddedSize(n)
+		if size < n || float64(size-n) > 0.12*float64(n)+1 {
+			t.Fatalf("padme(%d) = %d", n, size)
+		}
+	}
+}
+
+func TestPadUnpad(t *testing.T) {
+	for _, policy := range []paddingPolicy{paddingNone, paddingPadme, paddingPowerOfTwo} {
+		for _, data := range [][]byte{
+			{},
+			{0x80},
+			{0x00, 0x00},
+			{0x80, 0x00, 0x00},
+			bytes.Repeat([]byte("ACTivate"), 100),
+		} {
+			padded := policy.pad(data)
+			if int64(len(padded)) != policy.paddedSize(int64(len(data))+1) {
+				t.Fatalf("%s: padded %d bytes to %d", policy, len(data), len(padded))
+			}
+			got, err := unpad(padded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("%s: got %x, want %x", policy, got, data)
+			}
+		}
+	}
+}
+
+func TestUnpadInvalid(t *testing.T) {
+	for _, data := range [][]byte{nil, {0x00}, {0x01}, {0x80, 0x01}} {
+		if _, err := unpad(data); !errors.Is(err, errInvalidPadding) {
+			t.Errorf("unpad(%x): expected errInvalidPadding, got %v", data, err)
+		}
+	}
+}
+
+func TestUnpadReader(t *testing.T) {
+	// zeros and markers inside the data must survive chunk boundaries
+	data := append(bytes.Repeat([]byte{0x80, 0x00, 0x00}, 20000), bytes.Repeat([]byte{0x00}, 70000)...)
+	data = append(data, 0x42, 0x80)
+
+	for _, policy := range []paddingPolicy{paddingNone, paddingPadme, paddingPowerOfTwo} {
+		padded := policy.pad(data)
+		readers := map[string]io.Reader{
+			"whole":    bytes.NewReader(padded),
+			"one byte": iotest.OneByteReader(bytes.NewReader(padded)),
+			"half":     iotest.HalfReader(bytes.NewReader(padded)),
+		}
+		for name, r := range readers {
+			got, err := io.ReadAll(newUnpadReader(r))
+			if err != nil {
+				t.Fatalf("%s/%s: %v", policy, name, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("%s/%s: got %d bytes, want %d", policy, name, len(got), len(data))
+			}
+		}
+	}
+
+	if _, err := io.ReadAll(newUnpadReader(bytes.NewReader([]byte{0x01, 0x00}))); !errors.Is(err, errInvalidPadding) {
+		t.Fatalf("expected errInvalidPadding, got %v", err)
+	}
+}
+
+func TestPaddedEnvelope(t *testing.T) {
+	key := mustGenerateKey(t)
+	e := &EncryptionUtils{padding: paddingPowerOfTwo}
+
+	sizes := map[int]bool{}
+	for n := 100; n < 120; n++ {
+		data := bytes.Repeat([]byte{0x61}, n)
+		sealed, err := e.EncryptData(data, &key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sealed[1] != envelopeVersion2 {
+			t.Fatalf("padded envelope written as version %d", sealed[1])
+		}
+		sizes[len(sealed)] = true
+
+		got, err := e.DecryptData(sealed, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("round trip of %d bytes failed", n)
+		}
+	}
+	if len(sizes) != 1 {
+		t.Fatalf("expected one ciphertext size for 100..119 byte messages, got %d", len(sizes))
+	}
+}
+
+func TestPaddedStream(t *testing.T) {
+	key := mustGenerateKey(t)
+	e := &EncryptionUtils{padding: paddingPadme}
+
+	encrypt := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w, err := e.EncryptStream(&buf, []*ecdsa.PublicKey{&key.PublicKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	a := randomBytes(t, 200*1024)
+	b := randomBytes(t, 200*1024+3000)
+	ca, cb := encrypt(a), encrypt(b)
+	if len(ca) != len(cb) {
+		t.Fatalf("sizes in the same bucket encrypt to %d and %d bytes", len(ca), len(cb))
+	}
+
+	for _, tc := range []struct{ plain, cipher []byte }{{a, ca}, {b, cb}} {
+		got, err := decryptTestStream(tc.cipher, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tc.plain) {
+			t.Fatal("padded stream round trip failed")
+		}
+	}
+}
+
+func TestPaddedTopic(t *testing.T) {
+	p := &sharePayload{Kind: payloadKindMessage, Body: []byte("short")}
+	if err := p.sign(mustGenerateKey(t), testTarget); err != nil {
+		t.Fatal(err)
+	}
+	topic, err := p.topic(paddingPowerOfTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	longer := &sharePayload{Kind: payloadKindMessage, Body: []byte("a little longer")}
+	if err := longer.sign(mustGenerateKey(t), testTarget); err != nil {
+		t.Fatal(err)
+	}
+	longerTopic, err := longer.topic(paddingPowerOfTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topic) != len(longerTopic) {
+		t.Fatalf("topic lengths differ: %d and %d", len(topic), len(longerTopic))
+	}
+
+	got, err := parseSharePayload(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Body) != "short" || got.verify(testTarget) != payloadVerified {
+		t.Fatalf("padded topic did not round trip: %+v", got)
+	}
+}
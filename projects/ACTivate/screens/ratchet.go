@@ -0,0 +1,423 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+/*
+Double Ratchet Sessions
+
+Two-way communication pairs two nodes that each hold the other's public key.
+A ratchet session between them gives every message a fresh key, so a key
+stolen today does not reveal earlier messages, and the session heals once new
+Diffie-Hellman keys have been exchanged.
+
+The construction follows the Signal double ratchet over secp256k1:
+
+  - the root key starts as DeriveSharedSecret of the two node keys
+  - the initiator's first ratchet step is against the responder's node key, so
+    the initiator can send right away
+  - KDF_RK is HKDF-SHA256 salted with the root key, KDF_CK is HMAC-SHA256
+  - message keys encrypt with AES-256-GCM, the header is additional data
+
+Limitation: there are no one-time prekeys, the responder's first ratchet key is
+its long-term node key. Messages the initiator sends before the first reply are
+therefore only as safe as that key: whoever later steals the responder's node
+key can decrypt them. Forward secrecy starts with the responder's first reply,
+which brings a fresh ratchet key. The chat card warns about this.
+
+Whoever sends first initiates. If both sides initiate at the same time the side
+with the larger compressed node key yields and becomes the responder. A first
+message that does not fit the current session starts a new one, unless its
+ratchet key already started one, so replaying it cannot reset the session.
+
+Message layout:
+
+	offset  size  field
+	0       1     magic (0xAC)
+	1       1     format version
+	2       1     algorithm ID (AlgRatchetAESGCM)
+	3       1     flags (0x01: sent before the initiator heard back)
+	4       33    sender ratchet public key (compressed)
+	37      4     previous sending chain length
+	41      4     message number
+	45      12    nonce
+	57      ...   AES-256-GCM ciphertext and tag
+*/
+
+const (
+	// AlgRatchetAESGCM is a double ratchet message encrypted with AES-256-GCM
+	AlgRatchetAESGCM byte = 0x04
+
+	ratchetFlagInit   byte = 0x01
+	ratchetHeaderSize      = 4 + compressedKeySize + 4 + 4 + envelopeNonceSize
+	ratchetKDFInfo         = "ACTivate ratchet root"
+
+	// maxSkippedMessageKeys bounds how far ahead a chain may be advanced
+	maxSkippedMessageKeys = 1000
+
+	// maxSeenInitKeys bounds how many initial ratchet keys are remembered
+	// against replayed session starts
+	maxSeenInitKeys = 16
+)
+
+var (
+	errSessionNotReady   = errors.New("waiting for the peer's first message before sending")
+	errTooManySkipped    = errors.New("too many skipped ratchet messages")
+	errNotRatchetMessage = errors.New("not a ratchet message")
+)
+
+// ratchetState is the local state of one session. It is persisted, so all
+// fields are exported for JSON.
+type ratchetState struct {
+	Peer        []byte            `json:"peer"`
+	DHs         []byte            `json:"dhs"`
+	DHr         []byte            `json:"dhr,omitempty"`
+	RK          []byte            `json:"rk"`
+	CKs         []byte            `json:"cks,omitempty"`
+	CKr         []byte            `json:"ckr,omitempty"`
+	Ns          uint32            `json:"ns"`
+	Nr          uint32            `json:"nr"`
+	PN          uint32            `json:"pn"`
+	Skipped     map[string][]byte `json:"skipped,omitempty"`
+	Initiator   bool              `json:"initiator"`
+	Established bool              `json:"established"`
+	SeenInits   [][]byte          `json:"seenInits,omitempty"`
+}
+
+// ratchetHeader precedes every ratchet message
+type ratchetHeader struct {
+	flags byte
+	dh    []byte
+	pn    uint32
+	n     uint32
+	nonce [envelopeNonceSize]byte
+}
+
+func (h *ratchetHeader) marshal() []byte {
+	b := make([]byte, 0, ratchetHeaderSize)
+	b = append(b, envelopeMagic, envelopeVersion1, AlgRatchetAESGCM, h.flags)
+	b = append(b, h.dh...)
+	b = binary.BigEndian.AppendUint32(b, h.pn)
+	b = binary.BigEndian.AppendUint32(b, h.n)
+	return append(b, h.nonce[:]...)
+}
+
+func parseRatchetHeader(data []byte) (*ratchetHeader, error) {
+	if len(data) < ratchetHeaderSize+eciesTagSize {
+		return nil, errCiphertextTooShort
+	}
+	if data[0] != envelopeMagic || data[2] != AlgRatchetAESGCM {
+		return nil, errNotRatchetMessage
+	}
+	if data[1] != envelopeVersion1 {
+		return nil, fmt.Errorf("%w: %d", errEnvelopeVersion, data[1])
+	}
+
+	h := &ratchetHeader{
+		flags: data[3],
+		dh:    append([]byte{}, data[4:4+compressedKeySize]...),
+		pn:    binary.BigEndian.Uint32(data[37:41]),
+		n:     binary.BigEndian.Uint32(data[41:45]),
+	}
+	copy(h.nonce[:], data[45:ratchetHeaderSize])
+	return h, nil
+}
+
+// newInitiatorSession starts a session that can send immediately
+func (e *EncryptionUtils) newInitiatorSession(ownKey *ecdsa.PrivateKey, peer *ecdsa.PublicKey) (*ratchetState, error) {
+	sk, err := e.DeriveSharedSecret(ownKey, peer)
+	if err != nil {
+		return nil, err
+	}
+	dhs, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ratchetState{
+		Peer:      crypto.CompressPubkey(peer),
+		DHs:       crypto.FromECDSA(dhs),
+		DHr:       crypto.CompressPubkey(peer),
+		Initiator: true,
+	}
+	dhOut, err := e.DeriveSharedSecret(dhs, peer)
+	if err != nil {
+		return nil, err
+	}
+	s.RK, s.CKs = kdfRoot(sk, dhOut)
+	return s, nil
+}
+
+// newResponderSession waits for the initiator's first message. Its first
+// ratchet key is the node key the initiator ratcheted against.
+func (e *EncryptionUtils) newResponderSession(ownKey *ecdsa.PrivateKey, peer *ecdsa.PublicKey) (*ratchetState, error) {
+	sk, err := e.DeriveSharedSecret(ownKey, peer)
+	if err != nil {
+		return nil, err
+	}
+	return &ratchetState{
+		Peer: crypto.CompressPubkey(peer),
+		DHs:  crypto.FromECDSA(ownKey),
+		RK:   sk,
+	}, nil
+}
+
+// ratchetEncrypt encrypts the next message of the session
+func (e *EncryptionUtils) ratchetEncrypt(s *ratchetState, plaintext, ad []byte) ([]byte, error) {
+	if s.CKs == nil {
+		return nil, errSessionNotReady
+	}
+	dhs, err := crypto.ToECDSA(s.DHs)
+	if err != nil {
+		return nil, err
+	}
+
+	var mk []byte
+	s.CKs, mk = kdfChain(s.CKs)
+
+	h := &ratchetHeader{
+		dh: crypto.CompressPubkey(&dhs.PublicKey),
+		pn: s.PN,
+		n:  s.Ns,
+	}
+	if s.Initiator && !s.Established {
+		h.flags |= ratchetFlagInit
+	}
+	if _, err := io.ReadFull(rand.Reader, h.nonce[:]); err != nil {
+		return nil, err
+	}
+	s.Ns++
+
+	aead, err := newAESGCM(mk)
+	if err != nil {
+		return nil, err
+	}
+	header := h.marshal()
+	return aead.Seal(header, h.nonce[:], plaintext, append(header, ad...)), nil
+}
+
+// ratchetDecrypt decrypts a message of the session. The state is only
+// changed if the message authenticates.
+func (e *EncryptionUtils) ratchetDecrypt(s *ratchetState, message, ad []byte) ([]byte, error) {
+	h, err := parseRatchetHeader(message)
+	if err != nil {
+		return nil, err
+	}
+
+	next := s.clone()
+	plaintext, err := e.ratchetDecryptState(next, h, message, ad)
+	if err != nil {
+		return nil, err
+	}
+	*s = *next
+	return plaintext, nil
+}
+
+func (e *EncryptionUtils) ratchetDecryptState(s *ratchetState, h *ratchetHeader, message, ad []byte) ([]byte, error) {
+	if mk, ok := s.Skipped[skippedKeyID(h.dh, h.n)]; ok {
+		delete(s.Skipped, skippedKeyID(h.dh, h.n))
+		return openRatchetMessage(mk, h, message, ad)
+	}
+
+	if !bytes.Equal(h.dh, s.DHr) {
+		if err := s.skipMessageKeys(h.pn); err != nil {
+			return nil, err
+		}
+		if err := e.dhRatchet(s, h.dh); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.skipMessageKeys(h.n); err != nil {
+		return nil, err
+	}
+
+	var mk []byte
+	s.CKr, mk = kdfChain(s.CKr)
+	s.Nr++
+	plaintext, err := openRatchetMessage(mk, h, message, ad)
+	if err != nil {
+		return nil, err
+	}
+	s.Established = true
+	return plaintext, nil
+}
+
+// dhRatchet steps the root chain with the peer's new ratchet key
+func (e *EncryptionUtils) dhRatchet(s *ratchetState, dh []byte) error {
+	peerRatchetKey, err := crypto.DecompressPubkey(dh)
+	if err != nil {
+		return fmt.Errorf("invalid ratchet key: %w", err)
+	}
+	dhs, err := crypto.ToECDSA(s.DHs)
+	if err != nil {
+		return err
+	}
+
+	s.PN = s.Ns
+	s.Ns = 0
+	s.Nr = 0
+	s.DHr = dh
+
+	dhOut, err := e.DeriveSharedSecret(dhs, peerRatchetKey)
+	if err != nil {
+		return err
+	}
+	s.RK, s.CKr = kdfRoot(s.RK, dhOut)
+
+	newDHs, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	s.DHs = crypto.FromECDSA(newDHs)
+	dhOut, err = e.DeriveSharedSecret(newDHs, peerRatchetKey)
+	if err != nil {
+		return err
+	}
+	s.RK, s.CKs = kdfRoot(s.RK, dhOut)
+	return nil
+}
+
+// skipMessageKeys stores the keys of messages that have not arrived yet
+func (s *ratchetState) skipMessageKeys(until uint32) error {
+	if s.CKr == nil || until <= s.Nr {
+		return nil
+	}
+	if until-s.Nr > maxSkippedMessageKeys || len(s.Skipped)+int(until-s.Nr) > maxSkippedMessageKeys {
+		return errTooManySkipped
+	}
+	if s.Skipped == nil {
+		s.Skipped = map[string][]byte{}
+	}
+	for s.Nr < until {
+		var mk []byte
+		s.CKr, mk = kdfChain(s.CKr)
+		s.Skipped[skippedKeyID(s.DHr, s.Nr)] = mk
+		s.Nr++
+	}
+	return nil
+}
+
+func (s *ratchetState) clone() *ratchetState {
+	c := *s
+	c.Skipped = make(map[string][]byte, len(s.Skipped))
+	for k, v := range s.Skipped {
+		c.Skipped[k] = v
+	}
+	return &c
+}
+
+func skippedKeyID(dh []byte, n uint32) string {
+	return fmt.Sprintf("%s:%d", hex.EncodeToString(dh), n)
+}
+
+func openRatchetMessage(mk []byte, h *ratchetHeader, message, ad []byte) ([]byte, error) {
+	aead, err := newAESGCM(mk)
+	if err != nil {
+		return nil, err
+	}
+	header := message[:ratchetHeaderSize]
+	plaintext, err := aead.Open(nil, h.nonce[:], message[ratchetHeaderSize:], append(append([]byte{}, header...), ad...))
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// kdfRoot derives the next root key and a chain key
+func kdfRoot(rk, dhOut []byte) (root, chain []byte) {
+	out := make([]byte, 64)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, dhOut, rk, []byte(ratchetKDFInfo)), out); err != nil {
+		panic(err) // HKDF-SHA256 can produce far more than 64 bytes
+	}
+	return out[:32], out[32:]
+}
+
+// kdfChain derives the next chain key and a message key
+func kdfChain(ck []byte) (chain, message []byte) {
+	m := hmac.New(sha256.New, ck)
+	m.Write([]byte{0x01})
+	message = m.Sum(nil)
+	m = hmac.New(sha256.New, ck)
+	m.Write([]byte{0x02})
+	return m.Sum(nil), message
+}
+
+// ratchetAD binds messages to the two node keys of a session
+func ratchetAD(sender, receiver *ecdsa.PublicKey) []byte {
+	return append(crypto.CompressPubkey(sender), crypto.CompressPubkey(receiver)...)
+}
+
+// sessionYields reports whether our initiated session gives way to the peer's
+// when both sides initiated at the same time
+func sessionYields(own, peer *ecdsa.PublicKey) bool {
+	return bytes.Compare(crypto.CompressPubkey(own), crypto.CompressPubkey(peer)) > 0
+}
+
+// receiveRatchetMessage decrypts a message from peer, creating or replacing
+// the session as needed. It returns the session to store.
+func (e *EncryptionUtils) receiveRatchetMessage(s *ratchetState, ownKey *ecdsa.PrivateKey, peer *ecdsa.PublicKey, message []byte) ([]byte, *ratchetState, error) {
+	h, err := parseRatchetHeader(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	ad := ratchetAD(peer, &ownKey.PublicKey)
+
+	if s != nil {
+		plaintext, err := e.ratchetDecrypt(s, message, ad)
+		if err == nil || h.flags&ratchetFlagInit == 0 || s.seenInit(h.dh) {
+			return plaintext, s, err
+		}
+	}
+
+	// an initial message that does not fit the current session starts a new one
+	responder, err := e.newResponderSession(ownKey, peer)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := e.ratchetDecrypt(responder, message, ad)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s != nil {
+		responder.SeenInits = s.SeenInits
+		if s.Initiator && !s.Established && !sessionYields(&ownKey.PublicKey, peer) {
+			// both sides initiated and ours wins, the peer will switch to it
+			s.addSeenInit(h.dh)
+			return plaintext, s, nil
+		}
+	}
+	responder.addSeenInit(h.dh)
+	return plaintext, responder, nil
+}
+
+// seenInit reports whether a session was already started from the initial
+// ratchet key dh, so a replayed first message cannot reset the session
+func (s *ratchetState) seenInit(dh []byte) bool {
+	for _, seen := range s.SeenInits {
+		if bytes.Equal(seen, dh) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ratchetState) addSeenInit(dh []byte) {
+	s.SeenInits = append(s.SeenInits, dh)
+	if len(s.SeenInits) > maxSeenInitKeys {
+		s.SeenInits = s.SeenInits[len(s.SeenInits)-maxSeenInitKeys:]
+	}
+}
@@ -0,0 +1,230 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+type ratchetPeer struct {
+	key     *ecdsa.PrivateKey
+	session *ratchetState
+}
+
+func (p *ratchetPeer) send(t *testing.T, to *ratchetPeer, text string) []byte {
+	t.Helper()
+	e := &EncryptionUtils{}
+	if p.session == nil {
+		s, err := e.newInitiatorSession(p.key, &to.key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.session = s
+	}
+	msg, err := e.ratchetEncrypt(p.session, []byte(text), ratchetAD(&p.key.PublicKey, &to.key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func (p *ratchetPeer) receive(from *ratchetPeer, msg []byte) (string, error) {
+	e := &EncryptionUtils{}
+	plaintext, next, err := e.receiveRatchetMessage(p.session, p.key, &from.key.PublicKey, msg)
+	if err != nil {
+		return "", err
+	}
+	p.session = next
+	return string(plaintext), nil
+}
+
+func (p *ratchetPeer) mustReceive(t *testing.T, from *ratchetPeer, msg []byte, want string) {
+	t.Helper()
+	got, err := p.receive(from, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func newRatchetPeers(t *testing.T) (*ratchetPeer, *ratchetPeer) {
+	return &ratchetPeer{key: mustGenerateKey(t)}, &ratchetPeer{key: mustGenerateKey(t)}
+}
+
+func TestRatchetConversation(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+
+	for round := 0; round < 3; round++ {
+		for n := 0; n < 3; n++ {
+			text := fmt.Sprintf("alice %d/%d", round, n)
+			bob.mustReceive(t, alice, alice.send(t, bob, text), text)
+		}
+		for n := 0; n < 2; n++ {
+			text := fmt.Sprintf("bob %d/%d", round, n)
+			alice.mustReceive(t, bob, bob.send(t, alice, text), text)
+		}
+	}
+	if !alice.session.Initiator || bob.session.Initiator {
+		t.Fatal("roles not assigned by who sent first")
+	}
+}
+
+func TestRatchetOutOfOrder(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+
+	first := alice.send(t, bob, "first")
+	second := alice.send(t, bob, "second")
+	third := alice.send(t, bob, "third")
+
+	bob.mustReceive(t, alice, first, "first")
+	bob.mustReceive(t, alice, third, "third")
+	reply := bob.send(t, alice, "reply")
+	alice.mustReceive(t, bob, reply, "reply")
+	next := alice.send(t, bob, "next chain")
+	bob.mustReceive(t, alice, next, "next chain")
+
+	// a message from an old chain still opens with its skipped key, once
+	bob.mustReceive(t, alice, second, "second")
+	if _, err := bob.receive(alice, second); err == nil {
+		t.Fatal("replayed message decrypted")
+	}
+}
+
+func TestRatchetTooManySkipped(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+	bob.mustReceive(t, alice, alice.send(t, bob, "hello"), "hello")
+
+	for n := 0; n < maxSkippedMessageKeys+1; n++ {
+		alice.send(t, bob, "lost")
+	}
+	if _, err := bob.receive(alice, alice.send(t, bob, "too late")); !errors.Is(err, errTooManySkipped) {
+		t.Fatalf("expected errTooManySkipped, got %v", err)
+	}
+}
+
+func TestRatchetForwardSecrecy(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+	bob.mustReceive(t, alice, alice.send(t, bob, "hello"), "hello")
+	alice.mustReceive(t, bob, bob.send(t, alice, "hi"), "hi")
+
+	// a copy of bob's state taken now must not open later messages once the
+	// session has ratcheted on
+	stolen := bob.session.clone()
+	bob.mustReceive(t, alice, alice.send(t, bob, "one"), "one")
+	alice.mustReceive(t, bob, bob.send(t, alice, "two"), "two")
+	later := alice.send(t, bob, "secret")
+	bob.mustReceive(t, alice, later, "secret")
+
+	e := &EncryptionUtils{}
+	if _, err := e.ratchetDecrypt(stolen, later, ratchetAD(&alice.key.PublicKey, &bob.key.PublicKey)); err == nil {
+		t.Fatal("old state decrypted a later message")
+	}
+
+	// and the current state holds no key for messages already read
+	if _, err := e.ratchetDecrypt(bob.session, later, ratchetAD(&alice.key.PublicKey, &bob.key.PublicKey)); err == nil {
+		t.Fatal("message decrypted twice")
+	}
+}
+
+func TestRatchetTampering(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+	msg := alice.send(t, bob, "hello")
+
+	for _, pos := range []int{3, 10, 40, ratchetHeaderSize - 1, ratchetHeaderSize, len(msg) - 1} {
+		tampered := append([]byte{}, msg...)
+		tampered[pos] ^= 0x01
+		if _, err := bob.receive(alice, tampered); err == nil {
+			t.Fatalf("tampered byte %d accepted", pos)
+		}
+	}
+
+	// failures leave the session usable
+	bob.mustReceive(t, alice, msg, "hello")
+
+	// messages are bound to the two node keys
+	eve := &ratchetPeer{key: mustGenerateKey(t)}
+	if _, err := eve.receive(alice, alice.send(t, bob, "not for eve")); err == nil {
+		t.Fatal("message opened by another node")
+	}
+}
+
+func TestRatchetSimultaneousInitiation(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+
+	fromAlice := alice.send(t, bob, "from alice")
+	fromBob := bob.send(t, alice, "from bob")
+	alice.mustReceive(t, bob, fromBob, "from bob")
+	bob.mustReceive(t, alice, fromAlice, "from alice")
+
+	// both sides settled on the same session
+	if alice.session.Initiator == bob.session.Initiator {
+		t.Fatal("both sides kept the same role")
+	}
+	for n := 0; n < 2; n++ {
+		alice.mustReceive(t, bob, bob.send(t, alice, "ping"), "ping")
+		bob.mustReceive(t, alice, alice.send(t, bob, "pong"), "pong")
+	}
+}
+
+func TestRatchetResponderWaits(t *testing.T) {
+	alice, bob := newRatchetPeers(t)
+	e := &EncryptionUtils{}
+
+	s, err := e.newResponderSession(bob.key, &alice.key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.ratchetEncrypt(s, []byte("too early"), nil); !errors.Is(err, errSessionNotReady) {
+		t.Fatalf("expected errSessionNotReady, got %v", err)
+	}
+
+	if _, err := e.ratchetDecrypt(s, bytes.Repeat([]byte{0x01}, ratchetHeaderSize+eciesTagSize), nil); !errors.Is(err, errNotRatchetMessage) {
+		t.Fatalf("expected errNotRatchetMessage, got %v", err)
+	}
+}
+
+func TestSessionStoredBeforeSend(t *testing.T) {
+	alice := &index{app: test.NewTempApp(t), nodeConfig: &nodeConfig{}, nodeKey: mustGenerateKey(t), logger: &logger{}}
+	bob := &ratchetPeer{key: mustGenerateKey(t)}
+	encrypt := func(text string) []byte {
+		t.Helper()
+		message, err := alice.withSession(&bob.key.PublicKey, func(e *EncryptionUtils, s *ratchetState) (*ratchetState, []byte, error) {
+			if s == nil {
+				var err error
+				if s, err = e.newInitiatorSession(alice.nodeKey, &bob.key.PublicKey); err != nil {
+					return nil, nil, err
+				}
+			}
+			message, err := e.ratchetEncrypt(s, []byte(text), ratchetAD(&alice.nodeKey.PublicKey, &bob.key.PublicKey))
+			return s, message, err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+
+	// the send of the first message seemed to fail, but it may still arrive
+	first := encrypt("first")
+	second := encrypt("second")
+	h1, err := parseRatchetHeader(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := parseRatchetHeader(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1.n == h2.n {
+		t.Fatalf("the next message reused message key %d", h1.n)
+	}
+	sender := &ratchetPeer{key: alice.nodeKey}
+	bob.mustReceive(t, sender, second, "second")
+	bob.mustReceive(t, sender, first, "first")
+}
@@ -0,0 +1,118 @@
+package screens
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	beecrypto "github.com/ethersphere/bee/v2/pkg/crypto"
+	"github.com/ethersphere/bee/v2/pkg/keystore"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+	"github.com/tyler-smith/go-bip39"
+)
+
+/*
+Recovery Phrase
+
+The node key is the identity grantees know the publisher by, so losing the
+device loses the group. The 32-byte swarm key is exported as the entropy of a
+24-word BIP-39 mnemonic: the phrase is the key itself, not a seed a key is
+derived from, so the existing identity can be backed up as it is.
+
+Restoring writes the key into the file keystore bee-lite reads on start,
+encrypted with the password chosen in the setup wizard. bee-lite ties the
+overlay address stored in the data dir to the key, so restoring is only
+possible while no swarm key exists yet.
+*/
+
+const recoveryPhraseWords = 24
+
+var (
+	errInvalidRecoveryPhrase = errors.New("invalid recovery phrase")
+	errIdentityExists        = errors.New("this device already has a node identity")
+)
+
+// recoveryPhrase returns the mnemonic encoding of key
+func recoveryPhrase(key *ecdsa.PrivateKey) (string, error) {
+	return bip39.NewMnemonic(crypto.FromECDSA(key))
+}
+
+// keyFromRecoveryPhrase decodes a phrase made by recoveryPhrase. Case and
+// spacing are ignored.
+func keyFromRecoveryPhrase(phrase string) (*ecdsa.PrivateKey, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) != recoveryPhraseWords {
+		return nil, fmt.Errorf("%w: expected %d words, got %d", errInvalidRecoveryPhrase, recoveryPhraseWords, len(words))
+	}
+
+	entropy, err := bip39.EntropyFromMnemonic(strings.Join(words, " "))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRecoveryPhrase, err)
+	}
+	key, err := crypto.ToECDSA(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRecoveryPhrase, err)
+	}
+	return key, nil
+}
+
+// restoredKeyEDG makes the keystore store a given key instead of generating one
+type restoredKeyEDG struct {
+	keystore.EDG
+	key *ecdsa.PrivateKey
+}
+
+func (r restoredKeyEDG) Generate() (*ecdsa.PrivateKey, error) {
+	return r.key, nil
+}
+
+// restoreKeystore writes key as the swarm key of the file keystore in dir
+func restoreKeystore(dir, password string, key *ecdsa.PrivateKey) error {
+	ks := filekeystore.New(dir)
+	exists, err := ks.Exists(swarmKeyName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errIdentityExists
+	}
+
+	// the keystore only encodes keys on bee's own curve implementation
+	beeKey, err := beecrypto.DecodeSecp256k1PrivateKey(crypto.FromECDSA(key))
+	if err != nil {
+		return err
+	}
+	if _, err := ks.SetKey(swarmKeyName, password, restoredKeyEDG{EDG: beecrypto.EDGSecp256_K1, key: beeKey}); err != nil {
+		return fmt.Errorf("failed to write swarm key: %w", err)
+	}
+
+	// read it back the way bee-lite will
+	stored, _, err := ks.Key(swarmKeyName, password, beecrypto.EDGSecp256_K1)
+	if err != nil {
+		return fmt.Errorf("failed to read back swarm key: %w", err)
+	}
+	if crypto.PubkeyToAddress(stored.PublicKey) != crypto.PubkeyToAddress(key.PublicKey) {
+		return errors.New("restored swarm key does not match the recovery phrase")
+	}
+	return nil
+}
+
+// restoreIdentity restores the node key from phrase into the data dir
+func (i *index) restoreIdentity(phrase, password string) (*ecdsa.PrivateKey, error) {
+	if i.nodeConfig.isKeyStoreMem || i.nodeConfig.path == "" {
+		return nil, errNodeKeyUnavailable
+	}
+	key, err := keyFromRecoveryPhrase(phrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreKeystore(filepath.Join(i.nodeConfig.path, "keys"), password, key); err != nil {
+		return nil, err
+	}
+
+	i.logger.Log(fmt.Sprintf("Restored node identity %s", crypto.PubkeyToAddress(key.PublicKey).Hex()))
+	return key, nil
+}
@@ -0,0 +1,77 @@
+package screens
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	beecrypto "github.com/ethersphere/bee/v2/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/v2/pkg/keystore/file"
+)
+
+func TestRecoveryPhraseRoundTrip(t *testing.T) {
+	key := mustGenerateKey(t)
+	phrase, err := recoveryPhrase(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Fields(phrase)); n != recoveryPhraseWords {
+		t.Fatalf("expected %d words, got %d", recoveryPhraseWords, n)
+	}
+
+	// users retype phrases with odd spacing and capitals
+	restored, err := keyFromRecoveryPhrase("  " + strings.ToUpper(strings.ReplaceAll(phrase, " ", "\n ")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(restored.PublicKey) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("restored a different key")
+	}
+}
+
+func TestRecoveryPhraseInvalid(t *testing.T) {
+	phrase, err := recoveryPhrase(mustGenerateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(phrase)
+
+	swapped := append([]string{}, words...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if swapped[0] == swapped[1] {
+		swapped[0] = "zoo"
+	}
+
+	for name, phrase := range map[string]string{
+		"empty":      "",
+		"short":      strings.Join(words[:12], " "),
+		"typo":       strings.Join(append([]string{"notaword"}, words[1:]...), " "),
+		"word order": strings.Join(swapped, " "),
+	} {
+		if _, err := keyFromRecoveryPhrase(phrase); !errors.Is(err, errInvalidRecoveryPhrase) {
+			t.Errorf("%s: expected errInvalidRecoveryPhrase, got %v", name, err)
+		}
+	}
+}
+
+func TestRestoreKeystore(t *testing.T) {
+	dir := t.TempDir()
+	key := mustGenerateKey(t)
+
+	if err := restoreKeystore(dir, "password", key); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, created, err := filekeystore.New(dir).Key(swarmKeyName, "password", beecrypto.EDGSecp256_K1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || crypto.PubkeyToAddress(stored.PublicKey) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("keystore does not hold the restored key")
+	}
+
+	if err := restoreKeystore(dir, "password", mustGenerateKey(t)); !errors.Is(err, errIdentityExists) {
+		t.Fatalf("expected errIdentityExists, got %v", err)
+	}
+}
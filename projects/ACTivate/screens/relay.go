@@ -0,0 +1,83 @@
+package screens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"activate/relayer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	relayerURLPrefKey = "relayerUrl"
+	// relayDeadline is how long a signed request can wait for the relayer
+	relayDeadline = 15 * time.Minute
+)
+
+var (
+	errNoRelayer        = errors.New("no relayer configured")
+	errRelayUnsupported = errors.New("the data contract cannot take relayed notifications")
+)
+
+// relayerURL is the relayer the user set, or the network's
+func (i *index) relayerURL() string {
+	if url := i.getPreferenceString(relayerURLPrefKey); url != "" {
+		return url
+	}
+	return i.network().RelayerURL
+}
+
+// canRelay reports whether notifications can go through a relayer. That takes
+// a relayer and a contract deployed with sendDataToTargetWithSig, earlier
+// deployments revert every relayed request.
+func (i *index) canRelay(ctx context.Context) bool {
+	return i.relayerURL() != "" && i.contractHas(ctx, "sendDataToTargetWithSig")
+}
+
+// relayNotification signs a notification with the node key and has the
+// relayer submit it, so a node without gas can notify. The event is from the
+// node, the relayer only pays for it.
+func relayNotification(ctx context.Context, client *relayer.Client, key *ecdsa.PrivateKey, chainID *big.Int, contract, target common.Address, topic string) (common.Hash, error) {
+	nonce, err := client.Nonce(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	request := &relayer.Request{
+		Target:   target,
+		Topic:    topic,
+		Nonce:    nonce,
+		Deadline: uint64(time.Now().Add(relayDeadline).Unix()),
+	}
+	if err := request.Sign(key, chainID, contract); err != nil {
+		return common.Hash{}, err
+	}
+	return client.Relay(ctx, request)
+}
+
+// relayDataToTarget sends a notification through the relayer of the network
+func (i *index) relayDataToTarget(ctx context.Context, target common.Address, topic string) (common.Hash, error) {
+	url := i.relayerURL()
+	if url == "" {
+		return common.Hash{}, errNoRelayer
+	}
+	if !i.contractHas(ctx, "sendDataToTargetWithSig") {
+		return common.Hash{}, errRelayUnsupported
+	}
+	key, err := i.nodePrivateKey()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	network := i.network()
+	txHash, err := relayNotification(ctx, relayer.NewClient(url), key, new(big.Int).SetUint64(network.ChainID), network.ContractAddress, target, topic)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to relay notification: %w", err)
+	}
+	i.logger.Log(fmt.Sprintf("Notification to %s relayed in %s", target.Hex(), txHash.Hex()))
+	return txHash, nil
+}
@@ -0,0 +1,137 @@
+package screens
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"activate/relayer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newRelayerServer runs a relayer paying from the harness owner in front of
+// the harness contract
+func newRelayerServer(t *testing.T, h *contractHarness) *relayer.Client {
+	t.Helper()
+	r, err := relayer.New(context.Background(), h.chain, h.key, h.address, relayer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(r.Handler())
+	t.Cleanup(server.Close)
+	return relayer.NewClient(server.URL)
+}
+
+func TestRelayNotificationOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	client := newRelayerServer(t, h)
+	sink := h.subscribe(t, testTo)
+	ctx := context.Background()
+
+	// the sender has no gas at all
+	sender, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(sender.PublicKey)
+	if balance, _ := h.chain.BalanceAt(ctx, from, nil); balance.Sign() != 0 {
+		t.Fatalf("sender has %s", balance)
+	}
+
+	txHash, err := relayNotification(ctx, client, sender, h.chain.config.ChainID, h.address, testTo, "relayed topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := nextEvent(t, sink)
+	if event.From != from || event.To != testTo || event.Topic != "relayed topic" || event.Raw.TxHash != txHash {
+		t.Fatalf("got event %+v", event)
+	}
+	// the relayer paid
+	tx, _, err := h.chain.TransactionByHash(ctx, txHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payer, _ := types.Sender(types.LatestSignerForChainID(h.chain.config.ChainID), tx); payer != h.owner {
+		t.Fatalf("paid by %s", payer.Hex())
+	}
+
+	if nonce, err := client.Nonce(ctx, from); err != nil || nonce != 1 {
+		t.Fatalf("nonce %d, %v", nonce, err)
+	}
+	// the next notification uses the next nonce
+	if _, err := relayNotification(ctx, client, sender, h.chain.config.ChainID, h.address, testTo, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if event := nextEvent(t, sink); event.From != from || event.Topic != "second" {
+		t.Fatalf("got event %+v", event)
+	}
+}
+
+func TestRelayRejectsOnChain(t *testing.T) {
+	h := newContractHarness(t, 0)
+	client := newRelayerServer(t, h)
+	ctx := context.Background()
+	sender, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := func(modify func(*relayer.Request)) *relayer.Request {
+		req := &relayer.Request{
+			Target:   testTo,
+			Topic:    "topic",
+			Deadline: uint64(time.Now().Add(time.Hour).Unix()),
+		}
+		modify(req)
+		if err := req.Sign(sender, h.chain.config.ChainID, h.address); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	valid := signed(func(*relayer.Request) {})
+	if _, err := client.Relay(ctx, valid); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request *relayer.Request
+		want    string
+	}{
+		{"replayed", valid, "nonce"},
+		{"stale nonce", signed(func(r *relayer.Request) { r.Nonce = 0 }), "nonce"},
+		{"expired", signed(func(r *relayer.Request) { r.Nonce = 1; r.Deadline = uint64(time.Now().Add(-time.Minute).Unix()) }), "expired"},
+		{"tampered", func() *relayer.Request {
+			r := signed(func(r *relayer.Request) { r.Nonce = 1 })
+			r.Target = common.Address{9}
+			return r
+		}(), "invalid request signature"},
+		{"other contract", func() *relayer.Request {
+			r := &relayer.Request{Target: testTo, Nonce: 1, Deadline: uint64(time.Now().Add(time.Hour).Unix())}
+			if err := r.Sign(sender, h.chain.config.ChainID, common.Address{1}); err != nil {
+				t.Fatal(err)
+			}
+			return r
+		}(), "invalid request signature"},
+		{"too long", signed(func(r *relayer.Request) { r.Nonce = 1; r.Topic = strings.Repeat("x", relayer.DefaultMaxTopicLength+1) }), "too long"},
+		{"zero target", signed(func(r *relayer.Request) { r.Nonce = 1; r.Target = common.Address{} }), "zero address"},
+	}
+	// rejected requests are not paid for
+	sent, _ := h.chain.PendingNonceAt(ctx, h.owner)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.Relay(ctx, tt.request); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if after, _ := h.chain.PendingNonceAt(ctx, h.owner); after != sent {
+		t.Fatalf("relayer sent %d transactions for rejected requests", after-sent)
+	}
+}
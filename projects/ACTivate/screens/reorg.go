@@ -0,0 +1,139 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+/*
+Reorg Handling
+
+Events are only acted on once they are Confirmations blocks deep, but a deeper
+reorganisation can still drop an event that was handled. The event stream then
+delivers the log again with Removed set.
+
+Handling an event stores what it carried in preferences. Every stored value is
+journaled together with the value it replaced, so a removed event can be undone:
+keys no later event wrote get their old value back, keys a later event wrote
+keep the later value, and that event inherits the old value to restore in case
+it is removed as well.
+
+The logs the stream delivered are stored with the event checkpoint, so a reorg
+that happens while the app is closed is still noticed after a restart. The
+preference journal is only kept in memory: after a restart a removed share is
+taken out of the inbox, but the preferences its event wrote stay.
+
+Shares received with a removed event are taken out of the inbox. Chat messages
+are not journaled. Once decrypted, a ratchet message has moved the session on
+and cannot be taken back.
+*/
+
+// maxJournaledEvents bounds how many handled events can be undone
+const maxJournaledEvents = 64
+
+// logID identifies a log in one particular block
+type logID struct {
+	Block     uint64      `json:"block"`
+	BlockHash common.Hash `json:"blockHash"`
+	TxHash    common.Hash `json:"txHash"`
+	Index     uint        `json:"index"`
+}
+
+func logIDOf(l types.Log) logID {
+	return logID{Block: l.BlockNumber, BlockHash: l.BlockHash, TxHash: l.TxHash, Index: l.Index}
+}
+
+// eventEffects are the preferences one handled event wrote
+type eventEffects struct {
+	id     logID
+	before map[string]string
+	after  map[string]string
+}
+
+func newEventEffects(l types.Log) *eventEffects {
+	return &eventEffects{
+		id:     logIDOf(l),
+		before: make(map[string]string),
+		after:  make(map[string]string),
+	}
+}
+
+// set records that key was changed from before to value
+func (e *eventEffects) set(key, before, value string) {
+	if _, ok := e.before[key]; !ok {
+		e.before[key] = before
+	}
+	e.after[key] = value
+}
+
+// eventJournal holds the effects of recently handled events, oldest first
+type eventJournal struct {
+	entries []*eventEffects
+}
+
+func (j *eventJournal) add(e *eventEffects) {
+	if len(e.after) == 0 {
+		return
+	}
+	j.entries = append(j.entries, e)
+	if len(j.entries) > maxJournaledEvents {
+		j.entries = slices.Delete(j.entries, 0, len(j.entries)-maxJournaledEvents)
+	}
+}
+
+// undo forgets the event with id and returns the preferences to restore
+func (j *eventJournal) undo(id logID) (map[string]string, bool) {
+	n := slices.IndexFunc(j.entries, func(e *eventEffects) bool { return e.id == id })
+	if n < 0 {
+		return nil, false
+	}
+	removed := j.entries[n]
+	j.entries = slices.Delete(j.entries, n, n+1)
+
+	restore := make(map[string]string)
+	for key, before := range removed.before {
+		later := slices.IndexFunc(j.entries[n:], func(e *eventEffects) bool {
+			_, ok := e.after[key]
+			return ok
+		})
+		if later >= 0 {
+			j.entries[n+later].before[key] = before
+		} else {
+			restore[key] = before
+		}
+	}
+	return restore, true
+}
+
+// setEventPreference stores a value carried by an event and journals it
+func (i *index) setEventPreference(effects *eventEffects, key, value string) {
+	effects.set(key, i.getPreferenceString(key), value)
+	i.setPreference(key, value)
+}
+
+// revertDataSentToTarget undoes a handled event that a reorg removed
+func (i *index) revertDataSentToTarget(vLog types.Log) {
+	i.logger.Log(fmt.Sprintf("Event in block %d, tx %s was removed by a chain reorganisation", vLog.BlockNumber, vLog.TxHash.Hex()))
+	err := i.updateInbox(func(entries *inbox) bool {
+		return entries.remove(logIDOf(vLog))
+	})
+	if err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to remove the share from the inbox: %v", err))
+	}
+
+	restore, ok := i.eventJournal.undo(logIDOf(vLog))
+	if !ok {
+		i.logger.Log("Nothing stored for the removed event, nothing to undo.")
+		return
+	}
+	for key, value := range restore {
+		i.setPreference(key, value)
+	}
+	i.logger.Log(fmt.Sprintf("Restored %d preferences changed by the removed event.", len(restore)))
+	if i.eventMessageLabel != nil {
+		i.eventMessageLabel.SetText(fmt.Sprintf("The event in block %d was dropped by a chain reorganisation and has been undone.", vLog.BlockNumber))
+	}
+}
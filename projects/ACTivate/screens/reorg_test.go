@@ -0,0 +1,324 @@
+package screens
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// simChain is an in-memory chain that can be forked. Like a node, it sends
+// subscribers the logs of dropped blocks as removed, then the logs of the
+// blocks replacing them as they are mined.
+type simChain struct {
+	mu     sync.Mutex
+	blocks [][]types.Log // logs by block number, block 0 is genesis
+	forks  int
+	live   bool
+	feed   event.Feed
+}
+
+func newSimChain(live bool) *simChain {
+	return &simChain{blocks: [][]types.Log{nil}, live: live}
+}
+
+// commit mines a block with one log per topic and returns the logs
+func (c *simChain) commit(topics ...string) []types.Log {
+	c.mu.Lock()
+	number := uint64(len(c.blocks))
+	blockHash := crypto.Keccak256Hash([]byte(fmt.Sprintf("block %d fork %d", number, c.forks)))
+	var logs []types.Log
+	for n, topic := range topics {
+		logs = append(logs, types.Log{
+			Topics:      []common.Hash{crypto.Keccak256Hash([]byte(topic))},
+			BlockNumber: number,
+			BlockHash:   blockHash,
+			TxHash:      crypto.Keccak256Hash(blockHash[:], []byte(topic)),
+			Index:       uint(n),
+		})
+	}
+	c.blocks = append(c.blocks, logs)
+	c.mu.Unlock()
+
+	if c.live {
+		for _, l := range logs {
+			c.feed.Send(l)
+		}
+	}
+	return logs
+}
+
+// fork drops the blocks after parent
+func (c *simChain) fork(parent uint64) {
+	c.mu.Lock()
+	var dropped []types.Log
+	for _, logs := range c.blocks[parent+1:] {
+		dropped = append(dropped, logs...)
+	}
+	c.blocks = c.blocks[:parent+1]
+	c.forks++
+	c.mu.Unlock()
+
+	if c.live {
+		for _, l := range dropped {
+			l.Removed = true
+			c.feed.Send(l)
+		}
+	}
+}
+
+func (c *simChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *simChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var logs []types.Log
+	for n := q.FromBlock.Uint64(); n <= q.ToBlock.Uint64() && n < uint64(len(c.blocks)); n++ {
+		logs = append(logs, c.blocks[n]...)
+	}
+	return logs, nil
+}
+
+func (c *simChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if !c.live {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	return c.feed.Subscribe(ch), nil
+}
+
+// nextLog returns the next item on the stream that carries a log
+func nextLog(t *testing.T, sink <-chan streamedLog) streamedLog {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case item := <-sink:
+			if item.Log != nil {
+				return item
+			}
+		case <-timeout:
+			t.Fatal("no log delivered")
+		}
+	}
+}
+
+// expectNoLog fails if a log is delivered within d
+func expectNoLog(t *testing.T, sink <-chan streamedLog, d time.Duration) {
+	t.Helper()
+	timeout := time.After(d)
+	for {
+		select {
+		case item := <-sink:
+			if item.Log != nil {
+				t.Fatalf("unexpected log in block %d", item.Log.BlockNumber)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestSubscribeLogsWaitsForConfirmations(t *testing.T) {
+	chain := newSimChain(true)
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), chain, ethereum.FilterQuery{}, logCheckpoint{}, logStreamConfig{PageSize: 500, PollInterval: 10 * time.Millisecond, Confirmations: 2}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	sent := chain.commit("a")
+	chain.commit()
+	expectNoLog(t, sink, 100*time.Millisecond)
+
+	chain.commit()
+	item := nextLog(t, sink)
+	if !sameLog(*item.Log, sent[0]) || item.Cursor != (logCursor{1, 1}) {
+		t.Fatalf("got log %d:%d, cursor %s", item.Log.BlockNumber, item.Log.Index, item.Cursor)
+	}
+}
+
+// checkReorg forks out a delivered log and expects it back as removed, then
+// the log that replaced it
+func checkReorg(t *testing.T, chain *simChain, config logStreamConfig) {
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), chain, ethereum.FilterQuery{}, logCheckpoint{}, config, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	chain.commit()
+	old := chain.commit("old")
+	for range config.Confirmations {
+		chain.commit()
+	}
+	if item := nextLog(t, sink); !sameLog(*item.Log, old[0]) {
+		t.Fatalf("expected the old log, got block %d", item.Log.BlockNumber)
+	}
+
+	chain.fork(1)
+	replacement := chain.commit("new")
+	for range config.Confirmations {
+		chain.commit()
+	}
+
+	item := nextLog(t, sink)
+	if !item.Log.Removed || !sameLog(*item.Log, old[0]) {
+		t.Fatalf("expected the old log as removed, got %+v", item.Log)
+	}
+	if item.Cursor != (logCursor{Block: 2}) {
+		t.Fatalf("stream did not rewind to the reorged block, cursor %s", item.Cursor)
+	}
+	item = nextLog(t, sink)
+	if item.Log.Removed || !sameLog(*item.Log, replacement[0]) {
+		t.Fatalf("expected the replacement log, got %+v", item.Log)
+	}
+}
+
+func TestSubscribeLogsReorgLive(t *testing.T) {
+	checkReorg(t, newSimChain(true), logStreamConfig{PageSize: 500, PollInterval: time.Minute})
+}
+
+func TestSubscribeLogsReorgConfirmed(t *testing.T) {
+	checkReorg(t, newSimChain(true), logStreamConfig{PageSize: 500, PollInterval: 10 * time.Millisecond, Confirmations: 1})
+}
+
+func TestSubscribeLogsReorgPolling(t *testing.T) {
+	checkReorg(t, newSimChain(false), logStreamConfig{PageSize: 500, PollInterval: 10 * time.Millisecond})
+}
+
+func TestSubscribeLogsReorgAfterRestart(t *testing.T) {
+	chain := newSimChain(false)
+	config := logStreamConfig{PageSize: 500, PollInterval: 10 * time.Millisecond}
+	sink := make(chan streamedLog)
+	sub, err := subscribeLogs(context.Background(), chain, ethereum.FilterQuery{}, logCheckpoint{}, config, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.commit()
+	old := chain.commit("old")
+	item := nextLog(t, sink)
+	sub.Unsubscribe()
+	if !sameLog(*item.Log, old[0]) {
+		t.Fatalf("expected the old log, got block %d", item.Log.BlockNumber)
+	}
+	stored, err := item.checkpoint().marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the log is reorged out while the app is not running
+	chain.fork(1)
+	replacement := chain.commit("new")
+
+	checkpoint, err := parseLogCheckpoint(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink = make(chan streamedLog)
+	sub, err = subscribeLogs(context.Background(), chain, ethereum.FilterQuery{}, checkpoint, config, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	if item := nextLog(t, sink); !item.Log.Removed || !sameLog(*item.Log, old[0]) {
+		t.Fatalf("expected the old log as removed, got %+v", item.Log)
+	}
+	if item := nextLog(t, sink); item.Log.Removed || !sameLog(*item.Log, replacement[0]) {
+		t.Fatalf("expected the replacement log, got %+v", item.Log)
+	}
+}
+
+func TestLogCheckpointLegacyCursor(t *testing.T) {
+	checkpoint, err := parseLogCheckpoint("40581246:7")
+	if err != nil || checkpoint.Cursor != (logCursor{Block: 40581246, Index: 7}) || len(checkpoint.Recent) != 0 {
+		t.Fatalf("got %+v, %v", checkpoint, err)
+	}
+}
+
+func TestDeliverRemovedKeepsEarlierLogs(t *testing.T) {
+	chain := newSimChain(false)
+	chain.commit()
+	logs := chain.commit("first", "second", "third")
+	chain.commit()
+
+	sink := make(chan streamedLog, 10)
+	s := &logStream{client: chain, pageSize: 500, cursor: logCursor{Block: 1}, sink: sink}
+	if err := s.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	drain := func() (delivered []types.Log) {
+		for {
+			select {
+			case item := <-sink:
+				if item.Log != nil {
+					delivered = append(delivered, *item.Log)
+				}
+			default:
+				return delivered
+			}
+		}
+	}
+	if got := drain(); len(got) != 3 || len(s.recent) != 3 {
+		t.Fatalf("delivered %d logs, %d recent", len(got), len(s.recent))
+	}
+
+	// only the second log is dropped, the stream rewinds to it and not to
+	// the start of its block
+	if !s.deliverRemoved(context.Background(), logs[1]) {
+		t.Fatal("stream stopped")
+	}
+	if s.cursor != (logCursor{Block: 2, Index: 1}) {
+		t.Fatalf("rewound to %s", s.cursor)
+	}
+	if err := s.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := drain()
+	if len(got) != 2 || !got[0].Removed || !sameLog(got[0], logs[1]) || got[1].Removed || !sameLog(got[1], logs[1]) {
+		t.Fatalf("after the rewind got %+v", got)
+	}
+}
+
+func TestEventJournalUndo(t *testing.T) {
+	first := newEventEffects(types.Log{BlockNumber: 1})
+	first.set("actRef", "", "a1")
+	first.set("topic", "", "t1")
+	second := newEventEffects(types.Log{BlockNumber: 2})
+	second.set("actRef", "a1", "a2")
+
+	var journal eventJournal
+	journal.add(first)
+	journal.add(second)
+	journal.add(newEventEffects(types.Log{BlockNumber: 3}))
+	if len(journal.entries) != 2 {
+		t.Fatalf("journaled %d events, want 2", len(journal.entries))
+	}
+
+	// the later event keeps its actRef, but has to restore the original
+	restore, ok := journal.undo(first.id)
+	if !ok || !maps.Equal(restore, map[string]string{"topic": ""}) {
+		t.Fatalf("undoing the first event restored %v", restore)
+	}
+	restore, ok = journal.undo(second.id)
+	if !ok || !maps.Equal(restore, map[string]string{"actRef": ""}) {
+		t.Fatalf("undoing the second event restored %v", restore)
+	}
+	if _, ok := journal.undo(second.id); ok {
+		t.Fatal("undid an event twice")
+	}
+}
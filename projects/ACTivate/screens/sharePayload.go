@@ -0,0 +1,329 @@
+package screens
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	beecrypto "github.com/ethersphere/bee/v2/pkg/crypto"
+)
+
+/*
+Signed Share Payloads
+
+What a sender publishes through the topic field of DataSentToTarget is a share
+payload: an ACT reference, a message or an invite. It is signed with the
+bee-lite node key (the key behind bl.PublicKey(), which is also the ACT
+publisher) using the bee signer, so receivers can tell who sent it.
+
+Topic string: "actv1:" followed by the hex encoding of
+
+	offset  size  field
+	0       1     payload kind
+	1       33    publisher public key (compressed)
+	34      1     reference length, then the Swarm reference
+	...     1     history reference length, then the history reference
+	...     2     body length (big endian), then the body
+	...     65    signature
+
+The signature covers a domain tag, the target address and every field above,
+so a payload cannot be replayed to another target or altered.
+
+With a padding policy the topic is "actv2:" followed by the hex encoding of the
+same bytes padded as described in padding.go, hiding the message length.
+
+Sent on chain the topic is encrypted to the target's public key: "acte1:"
+followed by the hex encoding of an envelope (see envelope.go) holding the
+signed topic. The owner and actref event fields are left zero, so the chain
+only shows that someone notified the target address.
+
+DataSentToTargetV2 events carry the same bytes without the hex encodings, see
+notification.go.
+
+Topics without a prefix are the unsigned publisher key + reference format of
+earlier builds and are reported as unverified.
+*/
+
+const (
+	signedPayloadPrefix  = "actv1:"
+	paddedPayloadPrefix  = "actv2:"
+	encryptedTopicPrefix = "acte1:"
+	payloadSignDomain    = "ACTivate share payload v1"
+	payloadSigSize       = 65
+)
+
+// payloadKind tells what a share payload carries
+type payloadKind byte
+
+const (
+	payloadKindACT payloadKind = iota + 1
+	payloadKindMessage
+	payloadKindInvite
+	// payloadKindSession references a double ratchet message on Swarm
+	payloadKindSession
+	// payloadKindGroupKeys references the group key bundle a new member is in
+	payloadKindGroupKeys
+)
+
+func (k payloadKind) String() string {
+	switch k {
+	case payloadKindACT:
+		return "ACT reference"
+	case payloadKindMessage:
+		return "message"
+	case payloadKindInvite:
+		return "invite"
+	case payloadKindSession:
+		return "session message"
+	case payloadKindGroupKeys:
+		return "group keys"
+	default:
+		return fmt.Sprintf("unknown (%d)", byte(k))
+	}
+}
+
+// verificationStatus is the outcome of checking a payload's signature
+type verificationStatus int
+
+const (
+	// payloadUnverified carries no signature
+	payloadUnverified verificationStatus = iota
+	// payloadVerified is signed by the publisher it names
+	payloadVerified
+	// payloadForged has a signature that does not match its content or publisher
+	payloadForged
+)
+
+func (s verificationStatus) String() string {
+	switch s {
+	case payloadVerified:
+		return "verified"
+	case payloadForged:
+		return "forged"
+	default:
+		return "unverified"
+	}
+}
+
+var errInvalidPayload = errors.New("invalid share payload")
+
+// sharePayload is a reference, message or invite sent to a target
+type sharePayload struct {
+	Kind      payloadKind
+	Publisher *ecdsa.PublicKey
+	Reference []byte
+	History   []byte
+	Body      []byte
+	Signature []byte
+}
+
+// fields encodes everything but the signature
+func (p *sharePayload) fields() ([]byte, error) {
+	if p.Publisher == nil {
+		return nil, fmt.Errorf("%w: missing publisher", errInvalidPayload)
+	}
+	if len(p.Reference) > math.MaxUint8 || len(p.History) > math.MaxUint8 || len(p.Body) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: field too large", errInvalidPayload)
+	}
+
+	b := []byte{byte(p.Kind)}
+	b = append(b, crypto.CompressPubkey(p.Publisher)...)
+	b = append(b, byte(len(p.Reference)))
+	b = append(b, p.Reference...)
+	b = append(b, byte(len(p.History)))
+	b = append(b, p.History...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(p.Body)))
+	b = append(b, p.Body...)
+	return b, nil
+}
+
+// signedData is what the publisher signs for a given target
+func (p *sharePayload) signedData(target common.Address) ([]byte, error) {
+	fields, err := p.fields()
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(payloadSignDomain), target.Bytes()...)
+	return append(data, fields...), nil
+}
+
+// sign signs the payload for target with the publisher's private key
+func (p *sharePayload) sign(key *ecdsa.PrivateKey, target common.Address) error {
+	p.Publisher = &key.PublicKey
+	data, err := p.signedData(target)
+	if err != nil {
+		return err
+	}
+	p.Signature, err = beecrypto.NewDefaultSigner(key).Sign(data)
+	return err
+}
+
+// verify checks that the payload was signed by its publisher for target
+func (p *sharePayload) verify(target common.Address) verificationStatus {
+	if len(p.Signature) == 0 {
+		return payloadUnverified
+	}
+	data, err := p.signedData(target)
+	if err != nil {
+		return payloadForged
+	}
+	signer, err := beecrypto.Recover(p.Signature, data)
+	if err != nil || crypto.PubkeyToAddress(*signer) != crypto.PubkeyToAddress(*p.Publisher) {
+		return payloadForged
+	}
+	return payloadVerified
+}
+
+// binary encodes the signed payload, the fields followed by the signature
+func (p *sharePayload) binary() ([]byte, error) {
+	if len(p.Signature) != payloadSigSize {
+		return nil, fmt.Errorf("%w: payload is not signed", errInvalidPayload)
+	}
+	fields, err := p.fields()
+	if err != nil {
+		return nil, err
+	}
+	return append(fields, p.Signature...), nil
+}
+
+// topic encodes the signed payload for the topic field, padded by policy
+func (p *sharePayload) topic(policy paddingPolicy) (string, error) {
+	b, err := p.binary()
+	if err != nil {
+		return "", err
+	}
+	if policy == paddingNone {
+		return signedPayloadPrefix + hex.EncodeToString(b), nil
+	}
+	return paddedPayloadPrefix + hex.EncodeToString(policy.pad(b)), nil
+}
+
+// encryptTopic encrypts a topic string to the target's public key
+func (e *EncryptionUtils) encryptTopic(topic string, target *ecdsa.PublicKey) (string, error) {
+	sealed, err := e.EncryptData([]byte(topic), target)
+	if err != nil {
+		return "", err
+	}
+	return encryptedTopicPrefix + hex.EncodeToString(sealed), nil
+}
+
+// isEncryptedTopic reports whether a topic was encrypted with encryptTopic
+func isEncryptedTopic(topic string) bool {
+	return strings.HasPrefix(topic, encryptedTopicPrefix)
+}
+
+// decryptTopic decrypts a topic encrypted with encryptTopic. It fails with
+// errWrongRecipient if the topic is addressed to another key.
+func (e *EncryptionUtils) decryptTopic(topic string, key *ecdsa.PrivateKey) (string, error) {
+	sealed, err := hex.DecodeString(strings.TrimPrefix(topic, encryptedTopicPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	if !isEnvelope(sealed) {
+		return "", fmt.Errorf("%w: not an envelope", errInvalidPayload)
+	}
+	plaintext, err := e.DecryptData(sealed, key)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// parseSharePayload decodes a topic string, either signed, signed and padded
+// or in the legacy publisher key + reference format
+func parseSharePayload(topic string) (*sharePayload, error) {
+	var padded bool
+	switch {
+	case strings.HasPrefix(topic, signedPayloadPrefix):
+		topic = strings.TrimPrefix(topic, signedPayloadPrefix)
+	case strings.HasPrefix(topic, paddedPayloadPrefix):
+		topic = strings.TrimPrefix(topic, paddedPayloadPrefix)
+		padded = true
+	default:
+		return parseLegacySharePayload(topic)
+	}
+
+	b, err := hex.DecodeString(topic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	if padded {
+		if b, err = unpad(b); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+		}
+	}
+	return parseBinarySharePayload(b)
+}
+
+// parseBinarySharePayload decodes a signed payload encoded by binary
+func parseBinarySharePayload(b []byte) (*sharePayload, error) {
+	var err error
+	r := &payloadReader{b: b}
+	p := &sharePayload{Kind: payloadKind(r.next(1)[0])}
+	p.Publisher, err = crypto.DecompressPubkey(r.next(compressedKeySize))
+	if r.err == nil && err != nil {
+		return nil, fmt.Errorf("%w: publisher: %v", errInvalidPayload, err)
+	}
+	p.Reference = r.next(int(r.next(1)[0]))
+	p.History = r.next(int(r.next(1)[0]))
+	p.Body = r.next(int(binary.BigEndian.Uint16(r.next(2))))
+	p.Signature = r.next(payloadSigSize)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.b) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", errInvalidPayload, len(r.b))
+	}
+	return p, nil
+}
+
+// parseLegacySharePayload reads the unsigned publisher key (130 hex chars) +
+// 32-byte reference (64 hex chars) topic of earlier builds
+func parseLegacySharePayload(topic string) (*sharePayload, error) {
+	if len(topic) < 194 || topic[:2] != "04" {
+		return nil, fmt.Errorf("%w: topic too short (%d chars) to contain publicKey + 32-byte hex", errInvalidPayload, len(topic))
+	}
+
+	publisherBytes, err := hex.DecodeString(topic[:130])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	publisher, err := crypto.UnmarshalPubkey(publisherBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: publisher: %v", errInvalidPayload, err)
+	}
+	reference, err := hex.DecodeString(topic[130:194])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+
+	return &sharePayload{
+		Kind:      payloadKindACT,
+		Publisher: publisher,
+		Reference: reference,
+	}, nil
+}
+
+// payloadReader consumes fields from a byte slice, remembering the first error
+type payloadReader struct {
+	b   []byte
+	err error
+}
+
+func (r *payloadReader) next(n int) []byte {
+	if r.err != nil || len(r.b) < n {
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: truncated", errInvalidPayload)
+		}
+		return make([]byte, n)
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
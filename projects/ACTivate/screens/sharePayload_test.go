@@ -0,0 +1,196 @@
+package screens
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testTarget = common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+func signedTestPayload(t *testing.T) (*sharePayload, string) {
+	t.Helper()
+	p := &sharePayload{
+		Kind:      payloadKindACT,
+		Reference: bytes.Repeat([]byte{0xb1}, 32),
+		History:   bytes.Repeat([]byte{0x14}, 32),
+	}
+	if err := p.sign(mustGenerateKey(t), testTarget); err != nil {
+		t.Fatal(err)
+	}
+	topic, err := p.topic(paddingNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, topic
+}
+
+func TestSharePayloadSignVerify(t *testing.T) {
+	sent, topic := signedTestPayload(t)
+
+	got, err := parseSharePayload(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != sent.Kind || !bytes.Equal(got.Reference, sent.Reference) || !bytes.Equal(got.History, sent.History) || crypto.PubkeyToAddress(*got.Publisher) != crypto.PubkeyToAddress(*sent.Publisher) {
+		t.Fatalf("payload changed in transit: %+v", got)
+	}
+	if status := got.verify(testTarget); status != payloadVerified {
+		t.Fatalf("expected verified, got %s", status)
+	}
+}
+
+func TestSharePayloadMessageBody(t *testing.T) {
+	p := &sharePayload{Kind: payloadKindMessage, Body: []byte("hello grantees")}
+	if err := p.sign(mustGenerateKey(t), testTarget); err != nil {
+		t.Fatal(err)
+	}
+	topic, err := p.topic(paddingNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseSharePayload(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Body) != "hello grantees" || got.verify(testTarget) != payloadVerified {
+		t.Fatalf("unexpected message payload %+v", got)
+	}
+}
+
+func TestSharePayloadForged(t *testing.T) {
+	_, topic := signedTestPayload(t)
+
+	t.Run("other target", func(t *testing.T) {
+		got, err := parseSharePayload(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := got.verify(common.HexToAddress("0x01")); status != payloadForged {
+			t.Fatalf("replay to another target: %s", status)
+		}
+	})
+
+	t.Run("swapped publisher", func(t *testing.T) {
+		got, err := parseSharePayload(topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Publisher = &mustGenerateKey(t).PublicKey
+		if status := got.verify(testTarget); status != payloadForged {
+			t.Fatalf("impersonation: %s", status)
+		}
+	})
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(topic, signedPayloadPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip a byte in the kind, the reference, the history and the signature
+	for _, pos := range []int{0, 40, 80, len(raw) - 10} {
+		tampered := append([]byte{}, raw...)
+		tampered[pos] ^= 0x01
+		got, err := parseSharePayload(signedPayloadPrefix + hex.EncodeToString(tampered))
+		if err != nil {
+			continue
+		}
+		if status := got.verify(testTarget); status != payloadForged {
+			t.Fatalf("tampering byte %d: %s", pos, status)
+		}
+	}
+}
+
+func TestSharePayloadLegacyTopic(t *testing.T) {
+	key := mustGenerateKey(t)
+	reference := bytes.Repeat([]byte{0xb1}, 32)
+	topic := hex.EncodeToString(crypto.FromECDSAPub(&key.PublicKey)) + hex.EncodeToString(reference)
+
+	got, err := parseSharePayload(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*got.Publisher) != crypto.PubkeyToAddress(key.PublicKey) || !bytes.Equal(got.Reference, reference) {
+		t.Fatalf("legacy topic not parsed: %+v", got)
+	}
+	if status := got.verify(testTarget); status != payloadUnverified {
+		t.Fatalf("expected unverified, got %s", status)
+	}
+}
+
+func TestSharePayloadInvalid(t *testing.T) {
+	_, topic := signedTestPayload(t)
+
+	for name, topic := range map[string]string{
+		"empty":     "",
+		"short":     "04abcd",
+		"truncated": topic[:len(topic)-2],
+		"trailing":  topic + "00",
+		"not hex":   signedPayloadPrefix + "zz",
+	} {
+		if _, err := parseSharePayload(topic); !errors.Is(err, errInvalidPayload) {
+			t.Errorf("%s: expected errInvalidPayload, got %v", name, err)
+		}
+	}
+
+	if _, err := (&sharePayload{Kind: payloadKindACT}).topic(paddingNone); !errors.Is(err, errInvalidPayload) {
+		t.Errorf("unsigned payload encoded: %v", err)
+	}
+}
+
+func TestEncryptedTopic(t *testing.T) {
+	e := &EncryptionUtils{padding: paddingPadme}
+	recipient := mustGenerateKey(t)
+	target := crypto.PubkeyToAddress(recipient.PublicKey)
+
+	p := &sharePayload{
+		Kind:      payloadKindACT,
+		Reference: bytes.Repeat([]byte{0xb1}, 32),
+		History:   bytes.Repeat([]byte{0x14}, 32),
+	}
+	if err := p.sign(mustGenerateKey(t), target); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := p.topic(paddingNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic, err := e.encryptTopic(signed, &recipient.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedTopic(topic) || isEncryptedTopic(signed) {
+		t.Fatal("encrypted topics not told apart")
+	}
+	if strings.Contains(topic, hex.EncodeToString(p.Reference)) {
+		t.Fatal("reference visible in encrypted topic")
+	}
+
+	decrypted, err := e.decryptTopic(topic, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseSharePayload(decrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.History, p.History) || got.verify(target) != payloadVerified {
+		t.Fatalf("unexpected payload %+v", got)
+	}
+
+	if _, err := e.decryptTopic(topic, mustGenerateKey(t)); !errors.Is(err, errWrongRecipient) {
+		t.Fatalf("expected errWrongRecipient, got %v", err)
+	}
+
+	tampered := topic[:len(topic)-2] + "00"
+	if tampered == topic {
+		tampered = topic[:len(topic)-2] + "01"
+	}
+	if _, err := e.decryptTopic(tampered, recipient); err == nil {
+		t.Fatal("tampered topic decrypted")
+	}
+}
@@ -0,0 +1,484 @@
+package screens
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+/*
+Chunked Streaming File Encryption (STREAM construction)
+
+Files are encrypted with a random 32-byte file key in fixed-size segments, so
+neither the uploader nor the downloader needs the whole file in memory.
+
+Header:
+
+	offset  size  field
+	0       1     magic (0xAC)
+	1       1     format version
+	2       1     algorithm ID (AlgStreamAESGCM)
+	3       4     segment size (big endian)
+	7       7     nonce prefix
+	14      1     number of key slots
+	15      ...   key slots: 2 byte length + envelope of the file key per recipient
+	              or group key (see groupKey.go)
+
+Segments follow the header. Each is sealed with AES-256-GCM under the file key
+using nonce = prefix || segment counter (4 bytes) || last segment flag (1 byte),
+with the header as additional data. Every segment but the last carries exactly
+segment size bytes of plaintext; the last one may be empty.
+
+Because the counter and the last segment flag are part of the nonce,
+reordered, dropped, duplicated or truncated segments fail authentication.
+
+In format version 2 the plaintext is padded (see padding.go) before it is split
+into segments, so the stream length only reveals the padding bucket.
+*/
+
+const (
+	// AlgStreamAESGCM is the STREAM construction over AES-256-GCM
+	AlgStreamAESGCM byte = 0x02
+
+	defaultSegmentSize   = 64 * 1024
+	maxSegmentSize       = 16 * 1024 * 1024
+	streamNoncePrefixLen = 7
+	streamFixedHeaderLen = 3 + 4 + streamNoncePrefixLen + 1
+	fileKeySize          = 32
+)
+
+var (
+	errStreamTruncated = errors.New("encrypted stream is truncated")
+	errStreamCorrupted = errors.New("encrypted stream segment failed authentication")
+	errNoKeySlot       = errors.New("file is not encrypted for this key")
+)
+
+// streamHeader describes an encrypted stream
+type streamHeader struct {
+	padded      bool
+	segmentSize uint32
+	noncePrefix [streamNoncePrefixLen]byte
+	keySlots    [][]byte
+}
+
+func (h *streamHeader) marshal() ([]byte, error) {
+	if len(h.keySlots) > math.MaxUint8 {
+		return nil, fmt.Errorf("too many key slots: %d", len(h.keySlots))
+	}
+
+	version := envelopeVersion1
+	if h.padded {
+		version = envelopeVersion2
+	}
+
+	b := make([]byte, 0, streamFixedHeaderLen)
+	b = append(b, envelopeMagic, version, AlgStreamAESGCM)
+	b = binary.BigEndian.AppendUint32(b, h.segmentSize)
+	b = append(b, h.noncePrefix[:]...)
+	b = append(b, byte(len(h.keySlots)))
+	for _, slot := range h.keySlots {
+		if len(slot) > math.MaxUint16 {
+			return nil, fmt.Errorf("key slot too large: %d bytes", len(slot))
+		}
+		b = binary.BigEndian.AppendUint16(b, uint16(len(slot)))
+		b = append(b, slot...)
+	}
+	return b, nil
+}
+
+// readStreamHeader reads a stream header and returns it with its raw bytes
+func readStreamHeader(r io.Reader) (*streamHeader, []byte, error) {
+	fixed := make([]byte, streamFixedHeaderLen)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, nil, fmt.Errorf("read stream header: %w", err)
+	}
+	if fixed[0] != envelopeMagic {
+		return nil, nil, fmt.Errorf("invalid envelope magic byte 0x%02x", fixed[0])
+	}
+	if fixed[1] != envelopeVersion1 && fixed[1] != envelopeVersion2 {
+		return nil, nil, fmt.Errorf("%w: %d", errEnvelopeVersion, fixed[1])
+	}
+	if fixed[2] != AlgStreamAESGCM {
+		return nil, nil, fmt.Errorf("%w: 0x%02x", errEnvelopeAlgorithm, fixed[2])
+	}
+
+	h := &streamHeader{
+		padded:      fixed[1] == envelopeVersion2,
+		segmentSize: binary.BigEndian.Uint32(fixed[3:7]),
+	}
+	if h.segmentSize == 0 || h.segmentSize > maxSegmentSize {
+		return nil, nil, fmt.Errorf("invalid segment size %d", h.segmentSize)
+	}
+	copy(h.noncePrefix[:], fixed[7:7+streamNoncePrefixLen])
+
+	raw := fixed
+	slotCount := int(fixed[streamFixedHeaderLen-1])
+	for n := 0; n < slotCount; n++ {
+		var length [2]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return nil, nil, fmt.Errorf("read key slot: %w", err)
+		}
+		slot := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(r, slot); err != nil {
+			return nil, nil, fmt.Errorf("read key slot: %w", err)
+		}
+		h.keySlots = append(h.keySlots, slot)
+		raw = append(append(raw, length[:]...), slot...)
+	}
+
+	return h, raw, nil
+}
+
+// isEncryptedStream reports whether prefix starts an encrypted stream header
+func isEncryptedStream(prefix []byte) bool {
+	return len(prefix) >= 3 && prefix[0] == envelopeMagic &&
+		(prefix[1] == envelopeVersion1 || prefix[1] == envelopeVersion2) && prefix[2] == AlgStreamAESGCM
+}
+
+// EncryptStream returns a writer that encrypts everything written to it into w.
+// A fresh file key is generated and wrapped for every recipient. Close must be
+// called to write the final segment.
+func (e *EncryptionUtils) EncryptStream(w io.Writer, recipients []*ecdsa.PublicKey) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients for encrypted stream")
+	}
+	return e.paddedStream(e.encryptStream(w, defaultSegmentSize, e.recipientSlots(recipients)))
+}
+
+// EncryptGroupStream is like EncryptStream but wraps the file key with the
+// group content key, plus once for each additional recipient. bundleRef is
+// the Swarm reference of the bundle members can recover the group key from.
+func (e *EncryptionUtils) EncryptGroupStream(w io.Writer, g *groupKey, bundleRef []byte, recipients []*ecdsa.PublicKey) (io.WriteCloser, error) {
+	wrapRecipients := e.recipientSlots(recipients)
+	return e.paddedStream(e.encryptStream(w, defaultSegmentSize, func(fileKey []byte) ([][]byte, error) {
+		groupSlot, err := e.sealWithGroupKey(rand.Reader, fileKey, g, bundleRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap file key: %w", err)
+		}
+		slots, err := wrapRecipients(fileKey)
+		if err != nil {
+			return nil, err
+		}
+		return append([][]byte{groupSlot}, slots...), nil
+	}))
+}
+
+// paddedStream pads the plaintext of streams written in format version 2
+func (e *EncryptionUtils) paddedStream(s *streamWriter, err error) (io.WriteCloser, error) {
+	if err != nil {
+		return nil, err
+	}
+	if s.header.padded {
+		return newPadWriter(s, e.padding), nil
+	}
+	return s, nil
+}
+
+// recipientSlots wraps the file key for each recipient public key
+func (e *EncryptionUtils) recipientSlots(recipients []*ecdsa.PublicKey) func([]byte) ([][]byte, error) {
+	return func(fileKey []byte) ([][]byte, error) {
+		var slots [][]byte
+		for _, recipient := range recipients {
+			slot, err := e.EncryptData(fileKey, recipient)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wrap file key: %w", err)
+			}
+			slots = append(slots, slot)
+		}
+		return slots, nil
+	}
+}
+
+func (e *EncryptionUtils) encryptStream(w io.Writer, segmentSize uint32, wrap func(fileKey []byte) ([][]byte, error)) (*streamWriter, error) {
+	fileKey := make([]byte, fileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, fmt.Errorf("failed to generate file key: %w", err)
+	}
+
+	h := &streamHeader{
+		padded:      e.padding != paddingNone,
+		segmentSize: segmentSize,
+	}
+	if _, err := rand.Read(h.noncePrefix[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+	slots, err := wrap(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	h.keySlots = slots
+
+	return newStreamWriter(w, fileKey, h)
+}
+
+// keyring holds the keys a reader can unwrap file keys with
+type keyring struct {
+	privateKey *ecdsa.PrivateKey
+	groupKeys  []*groupKey
+	// fetchGroupKeys, if set, is called with the bundle reference of a slot
+	// sealed under an unknown group key
+	fetchGroupKeys func(bundleRef []byte) ([]*groupKey, error)
+}
+
+// DecryptStream reads an encrypted stream header from r, unwraps the file key
+// with the private key and returns a reader of the plaintext
+func (e *EncryptionUtils) DecryptStream(r io.Reader, privateKey *ecdsa.PrivateKey) (io.Reader, error) {
+	return e.decryptStream(r, &keyring{privateKey: privateKey})
+}
+
+func (e *EncryptionUtils) decryptStream(r io.Reader, keys *keyring) (io.Reader, error) {
+	h, raw, err := readStreamHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := e.unwrapFileKey(h.keySlots, keys)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := newStreamReader(r, fileKey, h, raw)
+	if err != nil {
+		return nil, err
+	}
+	if h.padded {
+		return newUnpadReader(plaintext), nil
+	}
+	return plaintext, nil
+}
+
+// unwrapFileKey opens the first key slot addressed to a key in the keyring
+func (e *EncryptionUtils) unwrapFileKey(slots [][]byte, keys *keyring) ([]byte, error) {
+	var fingerprint [fingerprintSize]byte
+	if keys.privateKey != nil {
+		fingerprint = keyFingerprint(&keys.privateKey.PublicKey)
+	}
+
+	var groupSlots [][]byte
+	for _, slot := range slots {
+		slotHeader, _, err := parseEnvelopeHeader(slot)
+		if err != nil {
+			continue
+		}
+		switch {
+		case slotHeader.algorithm == AlgGroupKeyAESGCM:
+			groupSlots = append(groupSlots, slot)
+		case keys.privateKey != nil && slotHeader.fingerprint == fingerprint:
+			fileKey, err := e.DecryptData(slot, keys.privateKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unwrap file key: %w", err)
+			}
+			return fileKey, nil
+		}
+	}
+
+	for _, slot := range groupSlots {
+		fileKey, err := e.openWithGroupKeys(slot, keys.groupKeys)
+		if errors.Is(err, errUnknownGroupKey) && keys.fetchGroupKeys != nil {
+			bundleRef, _ := groupKeyBundleRef(slot)
+			fetched, fetchErr := keys.fetchGroupKeys(bundleRef)
+			if fetchErr != nil {
+				if errors.Is(fetchErr, errNoKeySlot) {
+					continue
+				}
+				return nil, fetchErr
+			}
+			keys.groupKeys = append(keys.groupKeys, fetched...)
+			fileKey, err = e.openWithGroupKeys(slot, keys.groupKeys)
+		}
+		if errors.Is(err, errUnknownGroupKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap file key: %w", err)
+		}
+		return fileKey, nil
+	}
+
+	return nil, errNoKeySlot
+}
+
+// streamWriter seals plaintext segments as they fill up
+type streamWriter struct {
+	w           io.Writer
+	aead        cipher.AEAD
+	header      *streamHeader
+	rawHeader   []byte
+	buf         []byte
+	counter     uint32
+	wroteHeader bool
+	closed      bool
+}
+
+func newStreamWriter(w io.Writer, key []byte, h *streamHeader) (*streamWriter, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := h.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamWriter{
+		w:         w,
+		aead:      aead,
+		header:    h,
+		rawHeader: raw,
+		buf:       make([]byte, 0, h.segmentSize),
+	}, nil
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// a full buffer is only flushed once more data arrives, so the
+		// last segment can always be marked as such on Close
+		if len(s.buf) == cap(s.buf) {
+			if err := s.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(s.buf[len(s.buf):cap(s.buf)], p)
+		s.buf = s.buf[:len(s.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the final segment. It does not close the underlying writer.
+func (s *streamWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+func (s *streamWriter) flush(last bool) error {
+	if !s.wroteHeader {
+		if _, err := s.w.Write(s.rawHeader); err != nil {
+			return err
+		}
+		s.wroteHeader = true
+	}
+	if s.counter == math.MaxUint32 {
+		return errors.New("encrypted stream too long")
+	}
+
+	segment := s.aead.Seal(nil, s.header.segmentNonce(s.counter, last), s.buf, s.rawHeader)
+	if _, err := s.w.Write(segment); err != nil {
+		return err
+	}
+	s.counter++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// streamReader opens segments one at a time
+type streamReader struct {
+	r         *bufio.Reader
+	aead      cipher.AEAD
+	header    *streamHeader
+	rawHeader []byte
+	segment   []byte
+	plain     []byte
+	counter   uint32
+	done      bool
+}
+
+func newStreamReader(r io.Reader, key []byte, h *streamHeader, raw []byte) (*streamReader, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		r:         bufio.NewReader(r),
+		aead:      aead,
+		header:    h,
+		rawHeader: raw,
+		segment:   make([]byte, int(h.segmentSize)+aead.Overhead()),
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+func (s *streamReader) next() error {
+	n, err := io.ReadFull(s.r, s.segment)
+	last := false
+	switch {
+	case errors.Is(err, io.EOF):
+		// the previous segment was not marked as the last one
+		return errStreamTruncated
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err := s.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := s.aead.Open(nil, s.header.segmentNonce(s.counter, last), s.segment[:n], s.rawHeader)
+	if err != nil {
+		// a valid segment that was not sealed as the last one means the
+		// stream was cut off at a segment boundary
+		if _, err := s.aead.Open(nil, s.header.segmentNonce(s.counter, !last), s.segment[:n], s.rawHeader); err == nil && last {
+			return errStreamTruncated
+		}
+		return errStreamCorrupted
+	}
+	if !last && len(plain) != int(s.header.segmentSize) {
+		return errStreamCorrupted
+	}
+
+	s.plain = plain
+	s.counter++
+	s.done = last
+	return nil
+}
+
+func (h *streamHeader) segmentNonce(counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, envelopeNonceSize)
+	nonce = append(nonce, h.noncePrefix[:]...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// peekEncryptedStream reports whether r starts with an encrypted stream header
+// and returns a reader that still yields the full content
+func peekEncryptedStream(r io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(r)
+	prefix, _ := br.Peek(3)
+	return br, isEncryptedStream(prefix)
+}
@@ -0,0 +1,256 @@
+package screens
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testSegmentSize = 16
+
+func encryptTestStream(t *testing.T, data []byte, recipients ...*ecdsa.PublicKey) (ciphertext []byte, headerLen int) {
+	t.Helper()
+	e := &EncryptionUtils{}
+	var buf bytes.Buffer
+	w, err := e.encryptStream(&buf, testSegmentSize, e.recipientSlots(recipients))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), len(w.rawHeader)
+}
+
+func decryptTestStream(ciphertext []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	e := &EncryptionUtils{}
+	r, err := e.DecryptStream(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	_, key, err := (&EncryptionUtils{}).GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, testSegmentSize - 1, testSegmentSize, testSegmentSize + 1, 3 * testSegmentSize, 100} {
+		data := randomBytes(t, size)
+		ciphertext, headerLen := encryptTestStream(t, data, &key.PublicKey)
+
+		segments := size/testSegmentSize + 1
+		if size > 0 && size%testSegmentSize == 0 {
+			segments--
+		}
+		if want := headerLen + size + segments*eciesTagSize; len(ciphertext) != want {
+			t.Fatalf("size %d: got ciphertext length %d, want %d", size, len(ciphertext), want)
+		}
+
+		got, err := decryptTestStream(ciphertext, key)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestStreamDefaultSegmentSize(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, key, err := e.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randomBytes(t, 3*defaultSegmentSize+123)
+	var buf bytes.Buffer
+	w, err := e.EncryptStream(&buf, []*ecdsa.PublicKey{&key.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write in odd-sized pieces to cross segment boundaries
+	if _, err := io.CopyBuffer(w, bytes.NewReader(data), make([]byte, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := peekEncryptedStream(bytes.NewReader(buf.Bytes()))
+	if !ok {
+		t.Fatal("encrypted stream not detected")
+	}
+	plain, err := e.DecryptStream(r, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("plaintext mismatch")
+	}
+
+	if _, ok := peekEncryptedStream(bytes.NewReader([]byte("plain file"))); ok {
+		t.Fatal("plain content detected as encrypted stream")
+	}
+}
+
+func TestStreamMultipleRecipients(t *testing.T) {
+	e := &EncryptionUtils{}
+	_, alice, _ := e.GenerateKeyPair()
+	_, bob, _ := e.GenerateKeyPair()
+	_, eve, _ := e.GenerateKeyPair()
+
+	data := randomBytes(t, 40)
+	ciphertext, _ := encryptTestStream(t, data, &alice.PublicKey, &bob.PublicKey)
+
+	for _, key := range []*ecdsa.PrivateKey{alice, bob} {
+		got, err := decryptTestStream(ciphertext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatal("plaintext mismatch")
+		}
+	}
+
+	if _, err := decryptTestStream(ciphertext, eve); !errors.Is(err, errNoKeySlot) {
+		t.Fatalf("got error %v, want %v", err, errNoKeySlot)
+	}
+}
+
+func TestStreamTruncation(t *testing.T) {
+	_, key, err := (&EncryptionUtils{}).GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randomBytes(t, 3*testSegmentSize+5)
+	ciphertext, headerLen := encryptTestStream(t, data, &key.PublicKey)
+	sealedSegment := testSegmentSize + eciesTagSize
+
+	for _, tc := range []struct {
+		name string
+		cut  int
+		want error
+	}{
+		{"drop last segment", headerLen + 3*sealedSegment, errStreamTruncated},
+		{"drop two segments", headerLen + 2*sealedSegment, errStreamTruncated},
+		{"cut inside segment", headerLen + sealedSegment + 3, errStreamCorrupted},
+		{"cut inside last segment", len(ciphertext) - 1, errStreamCorrupted},
+		{"header only", headerLen, errStreamTruncated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decryptTestStream(ciphertext[:tc.cut], key)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got error %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	if _, err := decryptTestStream(ciphertext[:headerLen-1], key); err == nil {
+		t.Fatal("expected error for truncated header")
+	}
+}
+
+func TestStreamReordering(t *testing.T) {
+	_, key, err := (&EncryptionUtils{}).GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randomBytes(t, 3*testSegmentSize+5)
+	ciphertext, headerLen := encryptTestStream(t, data, &key.PublicKey)
+	sealedSegment := testSegmentSize + eciesTagSize
+	segment := func(n int) []byte {
+		start := headerLen + n*sealedSegment
+		return ciphertext[start : start+sealedSegment]
+	}
+
+	var swapped []byte
+	swapped = append(swapped, ciphertext[:headerLen]...)
+	swapped = append(swapped, segment(1)...)
+	swapped = append(swapped, segment(0)...)
+	swapped = append(swapped, ciphertext[headerLen+2*sealedSegment:]...)
+	if _, err := decryptTestStream(swapped, key); !errors.Is(err, errStreamCorrupted) {
+		t.Fatalf("swapped segments: got error %v, want %v", err, errStreamCorrupted)
+	}
+
+	var duplicated []byte
+	duplicated = append(duplicated, ciphertext[:headerLen]...)
+	duplicated = append(duplicated, segment(0)...)
+	duplicated = append(duplicated, ciphertext[headerLen:]...)
+	if _, err := decryptTestStream(duplicated, key); !errors.Is(err, errStreamCorrupted) {
+		t.Fatalf("duplicated segment: got error %v, want %v", err, errStreamCorrupted)
+	}
+
+	tamperedHeader := bytes.Clone(ciphertext)
+	tamperedHeader[8] ^= 0x01 // nonce prefix
+	if _, err := decryptTestStream(tamperedHeader, key); !errors.Is(err, errStreamCorrupted) {
+		t.Fatalf("tampered header: got error %v, want %v", err, errStreamCorrupted)
+	}
+}
+
+// endlessReader counts its reads and never ends
+type endlessReader struct {
+	reads atomic.Int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+	return len(p), nil
+}
+
+func TestEncryptFileStreamClose(t *testing.T) {
+	_, key, err := (&EncryptionUtils{}).GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &EncryptionUtils{}
+	encrypt := func(w io.Writer) (io.WriteCloser, error) {
+		return e.EncryptStream(w, []*ecdsa.PublicKey{&key.PublicKey})
+	}
+
+	// an upload that fails after reading part of the file
+	src := &endlessReader{}
+	stream := (&index{}).encryptFileStream(src, encrypt)
+	if _, err := io.ReadFull(stream, make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+	uploadErr := errors.New("upload failed")
+	if err := stream.CloseWithError(uploadErr); err != nil {
+		t.Fatal(err)
+	}
+	// the source can be closed, nothing reads it anymore
+	reads := src.reads.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := src.reads.Load(); got != reads {
+		t.Fatalf("source read %d times after close", got-reads)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,138 @@
+package screens
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func transactionsSubtitle(records txRecords) string {
+	return fmt.Sprintf("%d transactions, %d pending", len(records), records.pending())
+}
+
+// showTransactionsCard lists the journaled transactions, newest first
+func (i *index) showTransactionsCard() *widget.Card {
+	var mu sync.Mutex
+	var records txRecords
+	if i.txJournal != nil {
+		loaded, err := i.txJournal.records()
+		if err != nil {
+			i.logger.Log(fmt.Sprintf("Error loading transaction journal: %v", err))
+		}
+		records = loaded
+	}
+
+	txList := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(records)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template status description\nhash and time")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			mu.Lock()
+			defer mu.Unlock()
+			if id >= len(records) {
+				return
+			}
+			rec := records[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("%s: %s\n%s, %s", rec.Status, rec.Description,
+				shortenHashOrAddress(rec.Hash.Hex()), rec.Sent.Format("2006-01-02 15:04")))
+		},
+	)
+	txList.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		var rec *txRecord
+		if id < len(records) {
+			rec = records[id]
+		}
+		mu.Unlock()
+		if rec != nil {
+			i.showTransactionDetails(rec)
+		}
+		txList.UnselectAll()
+	}
+	txScroll := container.NewScroll(txList)
+	txScroll.SetMinSize(fyne.NewSize(350, 150))
+
+	card := widget.NewCard("Transactions", transactionsSubtitle(records), txScroll)
+	if i.txJournal != nil {
+		i.txJournal.setOnChange(func(changed txRecords) {
+			mu.Lock()
+			records = changed
+			mu.Unlock()
+			txList.Refresh()
+			card.SetSubTitle(transactionsSubtitle(changed))
+		})
+	}
+	return card
+}
+
+// showTransactionDetails shows a journaled transaction, with speed up and
+// cancel for pending ones
+func (i *index) showTransactionDetails(rec *txRecord) {
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Status: %s", rec.Status)),
+		widget.NewLabel(fmt.Sprintf("Description: %s", rec.Description)),
+		i.copyDialog(fmt.Sprintf("Hash: %s", shortenHashOrAddress(rec.Hash.Hex())), rec.Hash.Hex()),
+		widget.NewLabel(fmt.Sprintf("Nonce: %d, gas limit: %d", rec.Nonce, rec.GasLimit)),
+		widget.NewLabel(fmt.Sprintf("Priority fee: %s, max fee: %s", formatGwei(rec.GasTipCap), formatGwei(rec.GasFeeCap))),
+		widget.NewLabel(fmt.Sprintf("Sent: %s", rec.Sent.Format("2006-01-02 15:04:05"))),
+	)
+	if rec.Params != "" {
+		params := widget.NewLabel(fmt.Sprintf("Call: %s", rec.Params))
+		params.Wrapping = fyne.TextWrapWord
+		content.Add(params)
+	}
+	if rec.Block != 0 {
+		content.Add(widget.NewLabel(fmt.Sprintf("Block: %d", rec.Block)))
+	}
+	if link, err := url.Parse(i.network().txURL(rec.Hash)); err == nil && link.Host != "" {
+		content.Add(widget.NewHyperlink("View on explorer", link))
+	}
+	if rec.Replaces != (common.Hash{}) {
+		content.Add(widget.NewLabel(fmt.Sprintf("Replaces: %s", shortenHashOrAddress(rec.Replaces.Hex()))))
+	}
+	if rec.ReplacedBy != (common.Hash{}) {
+		content.Add(widget.NewLabel(fmt.Sprintf("Replaced by: %s", shortenHashOrAddress(rec.ReplacedBy.Hex()))))
+	}
+
+	var d dialog.Dialog
+	replace := func(cancel bool) {
+		d.Hide()
+		i.showProgressWithMessage("Sending replacement transaction...")
+		go func() {
+			defer i.hideProgress()
+			replacement, err := i.replacePendingTransaction(context.Background(), rec.Hash, cancel)
+			if err != nil {
+				i.showError(err)
+				return
+			}
+			dialog.ShowInformation("Replacement Sent",
+				fmt.Sprintf("Sent %s with a priority fee of %s.\nThe transaction mined first settles the other.", replacement.Hash.Hex(), formatGwei(replacement.GasTipCap)),
+				i.Window)
+		}()
+	}
+
+	buttons := []fyne.CanvasObject{widget.NewButton("Close", func() { d.Hide() })}
+	if rec.Status == txPending {
+		buttons = append(buttons,
+			widget.NewButton("Cancel Transaction", func() { replace(true) }),
+			widget.NewButton("Speed Up", func() { replace(false) }),
+		)
+	}
+	custom := dialog.NewCustomWithoutButtons("Transaction", content, i.Window)
+	custom.SetButtons(buttons)
+	d = custom
+	d.Resize(fyne.NewSize(500, 350))
+	d.Show()
+}
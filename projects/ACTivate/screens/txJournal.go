@@ -0,0 +1,454 @@
+package screens
+
+/*
+Transaction Journal
+
+Every transaction the app sends is written to a journal in the preferences
+before its receipt is awaited. If the app closes while waiting, the journal
+still has the transaction and the watcher settles it after the next start.
+
+A pending transaction is settled when its receipt shows up, or when another
+transaction with the same nonce is mined, which replaced it. Speeding up or
+cancelling a transaction sends such a replacement with higher fees.
+*/
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	txJournalPrefKey = "txJournal"
+	// maxJournaledTxs bounds the journal, the oldest settled transactions are dropped
+	maxJournaledTxs = 200
+	// txCheckInterval is how often pending transactions are checked
+	txCheckInterval = 15 * time.Second
+	// replacementBumpPercent is the least fee increase nodes accept for a
+	// transaction that replaces a pending one
+	replacementBumpPercent = 10
+	cancelGasLimit         = 21000
+)
+
+var errTxNotPending = errors.New("transaction is not pending")
+
+type txStatus string
+
+const (
+	txPending   txStatus = "pending"
+	txConfirmed txStatus = "confirmed"
+	txReverted  txStatus = "reverted"
+	txReplaced  txStatus = "replaced"
+	txCancelled txStatus = "cancelled"
+)
+
+// txRecord is a journaled transaction
+type txRecord struct {
+	Hash        common.Hash    `json:"hash"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Data        hexutil.Bytes  `json:"data"`
+	Description string         `json:"description"`
+	Params      string         `json:"params,omitempty"`
+	Nonce       uint64         `json:"nonce"`
+	GasLimit    uint64         `json:"gasLimit"`
+	GasTipCap   *big.Int       `json:"gasTipCap,omitempty"`
+	GasFeeCap   *big.Int       `json:"gasFeeCap,omitempty"`
+	Sent        time.Time      `json:"sent"`
+	Status      txStatus       `json:"status"`
+	Block       uint64         `json:"block,omitempty"`
+	// Replaces is the transaction a speed up or cancel was sent for
+	Replaces   common.Hash `json:"replaces,omitempty"`
+	Cancel     bool        `json:"cancel,omitempty"`
+	ReplacedBy common.Hash `json:"replacedBy,omitempty"`
+}
+
+// txRecords holds the journaled transactions, newest first
+type txRecords []*txRecord
+
+func (r txRecords) byHash(hash common.Hash) *txRecord {
+	for _, rec := range r {
+		if rec.Hash == hash {
+			return rec
+		}
+	}
+	return nil
+}
+
+func (r txRecords) pending() int {
+	n := 0
+	for _, rec := range r {
+		if rec.Status == txPending {
+			n++
+		}
+	}
+	return n
+}
+
+// add stores a transaction and drops the oldest settled ones beyond the limit
+func (r *txRecords) add(rec *txRecord) bool {
+	if r.byHash(rec.Hash) != nil {
+		return false
+	}
+	*r = slices.Insert(*r, 0, rec)
+	for n := len(*r) - 1; n >= 0 && len(*r) > maxJournaledTxs; n-- {
+		if (*r)[n].Status != txPending {
+			*r = slices.Delete(*r, n, n+1)
+		}
+	}
+	return true
+}
+
+// settle records the receipt of a transaction. Pending transactions with the
+// same nonce can no longer be mined, they were replaced by it.
+func (r txRecords) settle(receipt *types.Receipt) bool {
+	mined := r.byHash(receipt.TxHash)
+	if mined == nil || mined.Status != txPending {
+		return false
+	}
+	mined.Status = txConfirmed
+	if receipt.Status == types.ReceiptStatusFailed {
+		mined.Status = txReverted
+	}
+	if receipt.BlockNumber != nil {
+		mined.Block = receipt.BlockNumber.Uint64()
+	}
+
+	for _, rec := range r {
+		if rec == mined || rec.Status != txPending || rec.From != mined.From || rec.Nonce != mined.Nonce {
+			continue
+		}
+		rec.Status = txReplaced
+		if mined.Cancel {
+			rec.Status = txCancelled
+		}
+		rec.ReplacedBy = mined.Hash
+	}
+	return true
+}
+
+// txJournal stores the transaction records. The transaction senders, the
+// watcher and the UI all write to it, so changes are serialised.
+type txJournal struct {
+	mu       sync.Mutex
+	load     func() string
+	store    func(string)
+	onChange func(txRecords)
+}
+
+func newTxJournal(load func() string, store func(string)) *txJournal {
+	return &txJournal{load: load, store: store}
+}
+
+// setOnChange sets a function called with the records after every change
+func (j *txJournal) setOnChange(fn func(txRecords)) {
+	j.mu.Lock()
+	j.onChange = fn
+	j.mu.Unlock()
+}
+
+func (j *txJournal) records() (txRecords, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.read()
+}
+
+func (j *txJournal) read() (txRecords, error) {
+	records := txRecords{}
+	stored := j.load()
+	if stored == "" {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(stored), &records); err != nil {
+		return nil, fmt.Errorf("failed to read transaction journal: %w", err)
+	}
+	return records, nil
+}
+
+func (j *txJournal) update(fn func(records *txRecords) bool) error {
+	j.mu.Lock()
+	records, err := j.read()
+	if err != nil || !fn(&records) {
+		j.mu.Unlock()
+		return err
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		j.mu.Unlock()
+		return err
+	}
+	j.store(string(data))
+	onChange := j.onChange
+	j.mu.Unlock()
+
+	if onChange != nil {
+		onChange(records)
+	}
+	return nil
+}
+
+func (j *txJournal) add(rec *txRecord) error {
+	return j.update(func(records *txRecords) bool {
+		return records.add(rec)
+	})
+}
+
+func (j *txJournal) settle(receipt *types.Receipt) error {
+	return j.update(func(records *txRecords) bool {
+		return records.settle(receipt)
+	})
+}
+
+// txStateClient is the part of the chain client checking transactions needs
+type txStateClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// check settles the pending transactions that were mined or replaced since
+// they were sent and returns the settled records
+func (j *txJournal) check(ctx context.Context, client txStateClient) ([]*txRecord, error) {
+	records, err := j.records()
+	if err != nil {
+		return nil, err
+	}
+
+	// The nonces are read before the receipts, so a transaction mined in
+	// between is found by its receipt rather than taken as replaced.
+	nonces := map[common.Address]uint64{}
+	for _, rec := range records {
+		if _, ok := nonces[rec.From]; ok || rec.Status != txPending {
+			continue
+		}
+		nonce, err := client.NonceAt(ctx, rec.From, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get nonce of %s: %w", rec.From.Hex(), err)
+		}
+		nonces[rec.From] = nonce
+	}
+
+	var receipts []*types.Receipt
+	for _, rec := range records {
+		if rec.Status != txPending {
+			continue
+		}
+		receipt, err := client.TransactionReceipt(ctx, rec.Hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt of %s: %w", rec.Hash.Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	var settled []*txRecord
+	err = j.update(func(records *txRecords) bool {
+		before := map[common.Hash]txStatus{}
+		for _, rec := range *records {
+			before[rec.Hash] = rec.Status
+		}
+		for _, receipt := range receipts {
+			records.settle(receipt)
+		}
+		// a used nonce with no receipt means a transaction outside the
+		// journal replaced this one
+		for _, rec := range *records {
+			if nonce, ok := nonces[rec.From]; ok && rec.Status == txPending && rec.Nonce < nonce {
+				rec.Status = txReplaced
+			}
+		}
+		for _, rec := range *records {
+			if status, ok := before[rec.Hash]; ok && status != rec.Status {
+				settled = append(settled, rec)
+			}
+		}
+		return len(settled) > 0
+	})
+	return settled, err
+}
+
+// describeCall formats the method and arguments of contract call data
+func describeCall(contractABI abi.ABI, data []byte) string {
+	method, err := contractABI.MethodById(data)
+	if err != nil {
+		return ""
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return method.Name
+	}
+	args := make([]string, len(values))
+	for n, value := range values {
+		var arg string
+		switch v := value.(type) {
+		case common.Address:
+			arg = v.Hex()
+		case [32]byte:
+			arg = fmt.Sprintf("0x%x", v)
+		case string:
+			arg = fmt.Sprintf("%d characters", len(v))
+		case []common.Address:
+			arg = fmt.Sprintf("%d addresses", len(v))
+		case []string:
+			arg = fmt.Sprintf("%d strings", len(v))
+		default:
+			arg = fmt.Sprint(v)
+		}
+		args[n] = fmt.Sprintf("%s: %s", method.Inputs[n].Name, arg)
+	}
+	return fmt.Sprintf("%s(%s)", method.Name, strings.Join(args, ", "))
+}
+
+// replacementClient is the part of the chain client replacing a transaction needs
+type replacementClient interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// outbid returns the larger of suggested and the least a replacement of a
+// transaction paying old must pay
+func outbid(old, suggested *big.Int) *big.Int {
+	if old == nil {
+		return suggested
+	}
+	least := boost(old, replacementBumpPercent)
+	least.Add(least, big.NewInt(1))
+	if suggested.Cmp(least) > 0 {
+		return suggested
+	}
+	return least
+}
+
+// replaceTransaction sends a transaction with the nonce of rec and higher
+// fees. It repeats the call of rec, or for cancel sends nothing to the sender.
+func replaceTransaction(ctx context.Context, client replacementClient, key *ecdsa.PrivateKey, rec *txRecord, cancel bool) (*txRecord, error) {
+	if rec.Status != txPending {
+		return nil, errTxNotPending
+	}
+	if from := crypto.PubkeyToAddress(key.PublicKey); from != rec.From {
+		return nil, fmt.Errorf("transaction was sent by %s, not by %s", rec.From.Hex(), from.Hex())
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	suggestedPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	suggestedTip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get priority fee: %w", err)
+	}
+
+	replacement := &txRecord{
+		From:        rec.From,
+		To:          rec.To,
+		Data:        rec.Data,
+		Description: "Speed up: " + rec.Description,
+		Params:      rec.Params,
+		Nonce:       rec.Nonce,
+		GasLimit:    rec.GasLimit,
+		Sent:        time.Now(),
+		Status:      txPending,
+		Replaces:    rec.Hash,
+		Cancel:      cancel,
+	}
+	if cancel {
+		replacement.To = rec.From
+		replacement.Data = nil
+		replacement.Description = "Cancel: " + rec.Description
+		replacement.Params = ""
+		replacement.GasLimit = cancelGasLimit
+	}
+	replacement.GasTipCap = outbid(rec.GasTipCap, suggestedTip)
+	replacement.GasFeeCap = outbid(rec.GasFeeCap, new(big.Int).Add(suggestedPrice, replacement.GasTipCap))
+
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     replacement.Nonce,
+		GasTipCap: replacement.GasTipCap,
+		GasFeeCap: replacement.GasFeeCap,
+		Gas:       replacement.GasLimit,
+		To:        &replacement.To,
+		Value:     big.NewInt(0),
+		Data:      replacement.Data,
+	}), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign replacement: %w", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to send replacement: %w", err)
+	}
+	replacement.Hash = tx.Hash()
+	return replacement, nil
+}
+
+// replacePendingTransaction speeds up or cancels a journaled transaction
+func (i *index) replacePendingTransaction(ctx context.Context, hash common.Hash, cancel bool) (*txRecord, error) {
+	client := i.contractClient()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to the chain")
+	}
+	records, err := i.txJournal.records()
+	if err != nil {
+		return nil, err
+	}
+	rec := records.byHash(hash)
+	if rec == nil {
+		return nil, fmt.Errorf("transaction %s is not in the journal", hash.Hex())
+	}
+	key, err := i.nodePrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign replacement: %w", err)
+	}
+	replacement, err := replaceTransaction(ctx, client, key, rec, cancel)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.txJournal.add(replacement); err != nil {
+		i.logger.Log(fmt.Sprintf("Failed to journal transaction %s: %v", replacement.Hash.Hex(), err))
+	}
+	i.logger.Log(fmt.Sprintf("Sent %s for transaction %s", replacement.Hash.Hex(), hash.Hex()))
+	return replacement, nil
+}
+
+// watchTransactions settles the pending transactions of the journal, those
+// left from before a restart first
+func (i *index) watchTransactions(ctx context.Context) {
+	ticker := time.NewTicker(txCheckInterval)
+	defer ticker.Stop()
+	for {
+		if client := i.contractClient(); client != nil {
+			settled, err := i.txJournal.check(ctx, client)
+			if err != nil && ctx.Err() == nil {
+				i.logger.Log(fmt.Sprintf("Failed to check pending transactions: %v", err))
+			}
+			for _, rec := range settled {
+				i.logger.Log(fmt.Sprintf("Transaction %s (%s) %s", rec.Hash.Hex(), rec.Description, rec.Status))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
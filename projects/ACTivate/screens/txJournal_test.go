@@ -0,0 +1,259 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeTxClient has the receipts of mined transactions and the account nonces
+type fakeTxClient struct {
+	receipts map[common.Hash]*types.Receipt
+	nonces   map[common.Address]uint64
+	tip      *big.Int
+	price    *big.Int
+	sent     []*types.Transaction
+}
+
+func (c *fakeTxClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if r, ok := c.receipts[txHash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeTxClient) NonceAt(_ context.Context, account common.Address, _ *big.Int) (uint64, error) {
+	return c.nonces[account], nil
+}
+
+func (c *fakeTxClient) ChainID(context.Context) (*big.Int, error) { return big.NewInt(100), nil }
+
+func (c *fakeTxClient) SuggestGasPrice(context.Context) (*big.Int, error) { return c.price, nil }
+
+func (c *fakeTxClient) SuggestGasTipCap(context.Context) (*big.Int, error) { return c.tip, nil }
+
+func (c *fakeTxClient) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+// memoryTxJournal is a journal that keeps the stored json in memory, as
+// preferences would across restarts
+func memoryTxJournal(stored *string) *txJournal {
+	return newTxJournal(func() string { return *stored }, func(s string) { *stored = s })
+}
+
+func pendingTx(hash byte, nonce uint64) *txRecord {
+	return &txRecord{
+		Hash:        common.Hash{hash},
+		From:        testFrom,
+		To:          testTo,
+		Description: "sendDataToTarget",
+		Nonce:       nonce,
+		GasLimit:    100000,
+		GasTipCap:   big.NewInt(1000),
+		GasFeeCap:   big.NewInt(5000),
+		Sent:        time.Now(),
+		Status:      txPending,
+	}
+}
+
+func statuses(t *testing.T, j *txJournal) map[common.Hash]txStatus {
+	t.Helper()
+	records, err := j.records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[common.Hash]txStatus{}
+	for _, rec := range records {
+		got[rec.Hash] = rec.Status
+	}
+	return got
+}
+
+func TestTxJournalSettlesAfterRestart(t *testing.T) {
+	var stored string
+	j := memoryTxJournal(&stored)
+	for _, rec := range []*txRecord{pendingTx(1, 1), pendingTx(2, 2), pendingTx(3, 3), pendingTx(4, 4), pendingTx(5, 5)} {
+		if err := j.add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// tx 4 is replaced by a speed up, cancelling 5 is pending
+	speedUp := pendingTx(6, 4)
+	speedUp.Replaces = common.Hash{4}
+	cancel := pendingTx(7, 5)
+	cancel.Replaces = common.Hash{5}
+	cancel.Cancel = true
+	for _, rec := range []*txRecord{speedUp, cancel} {
+		if err := j.add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the app restarts, the journal is read from the stored preference
+	restarted := memoryTxJournal(&stored)
+	client := &fakeTxClient{
+		receipts: map[common.Hash]*types.Receipt{
+			{1}: {TxHash: common.Hash{1}, Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(10)},
+			{2}: {TxHash: common.Hash{2}, Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(11)},
+			{6}: {TxHash: common.Hash{6}, Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(12)},
+		},
+		// nonce 3 was used by a transaction outside the journal
+		nonces: map[common.Address]uint64{testFrom: 5},
+	}
+	settled, err := restarted.check(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settled) != 5 {
+		t.Fatalf("settled %d transactions, want 5", len(settled))
+	}
+	want := map[common.Hash]txStatus{
+		{1}: txConfirmed,
+		{2}: txReverted,
+		{3}: txReplaced,
+		{4}: txReplaced,
+		{5}: txPending,
+		{6}: txConfirmed,
+		{7}: txPending,
+	}
+	got := statuses(t, restarted)
+	for hash, status := range want {
+		if got[hash] != status {
+			t.Errorf("transaction %x is %s, want %s", hash[0], got[hash], status)
+		}
+	}
+
+	records, _ := restarted.records()
+	if rec := records.byHash(common.Hash{4}); rec.ReplacedBy != (common.Hash{6}) {
+		t.Fatalf("replaced by %s", rec.ReplacedBy.Hex())
+	}
+	if rec := records.byHash(common.Hash{1}); rec.Block != 10 {
+		t.Fatalf("mined in block %d", rec.Block)
+	}
+
+	// the cancel is mined
+	client.receipts[common.Hash{7}] = &types.Receipt{TxHash: common.Hash{7}, Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(13)}
+	client.nonces[testFrom] = 6
+	if _, err := restarted.check(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	got = statuses(t, restarted)
+	if got[common.Hash{5}] != txCancelled || got[common.Hash{7}] != txConfirmed {
+		t.Fatalf("got %v", got)
+	}
+
+	// nothing pending, nothing to settle
+	settled, err = restarted.check(context.Background(), client)
+	if err != nil || len(settled) != 0 {
+		t.Fatalf("settled %d, %v", len(settled), err)
+	}
+}
+
+func TestTxJournalKeepsPending(t *testing.T) {
+	var records txRecords
+	records.add(pendingTx(0, 0))
+	for n := 1; n <= maxJournaledTxs+10; n++ {
+		rec := pendingTx(byte(n), uint64(n))
+		rec.Hash = common.BigToHash(big.NewInt(int64(n)))
+		rec.Status = txConfirmed
+		records.add(rec)
+	}
+	if len(records) != maxJournaledTxs {
+		t.Fatalf("kept %d records", len(records))
+	}
+	if records.byHash(common.Hash{0}) == nil {
+		t.Fatal("dropped the pending transaction")
+	}
+	if records.add(pendingTx(0, 0)) {
+		t.Fatal("added the same transaction twice")
+	}
+}
+
+func TestReplaceTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := pendingTx(1, 7)
+	rec.From = crypto.PubkeyToAddress(key.PublicKey)
+	rec.Data = []byte{1, 2, 3, 4}
+
+	tests := []struct {
+		name    string
+		cancel  bool
+		tip     int64
+		price   int64
+		wantTip int64
+		wantCap int64
+	}{
+		// the old fees plus the 10% bump outbid the suggestion
+		{"speed up", false, 500, 2000, 1101, 5501},
+		// the suggestion is higher than the bump
+		{"speed up at suggested fees", false, 3000, 4000, 3000, 7000},
+		{"cancel", true, 500, 2000, 1101, 5501},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeTxClient{tip: big.NewInt(tt.tip), price: big.NewInt(tt.price)}
+			replacement, err := replaceTransaction(context.Background(), client, key, rec, tt.cancel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(client.sent) != 1 {
+				t.Fatalf("sent %d transactions", len(client.sent))
+			}
+			tx := client.sent[0]
+			sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil || sender != rec.From {
+				t.Fatalf("signed by %s, %v", sender.Hex(), err)
+			}
+			if tx.Nonce() != rec.Nonce || tx.Hash() != replacement.Hash || replacement.Replaces != rec.Hash {
+				t.Fatalf("nonce %d, hash %s, replaces %s", tx.Nonce(), tx.Hash().Hex(), replacement.Replaces.Hex())
+			}
+			if tx.GasTipCap().Int64() != tt.wantTip || tx.GasFeeCap().Int64() != tt.wantCap {
+				t.Fatalf("tip %s, fee cap %s", tx.GasTipCap(), tx.GasFeeCap())
+			}
+			if tt.cancel {
+				if *tx.To() != rec.From || len(tx.Data()) != 0 || tx.Gas() != cancelGasLimit || !strings.HasPrefix(replacement.Description, "Cancel") {
+					t.Fatalf("cancel sent to %s with %d bytes", tx.To().Hex(), len(tx.Data()))
+				}
+			} else if *tx.To() != rec.To || string(tx.Data()) != string(rec.Data) || tx.Gas() != rec.GasLimit {
+				t.Fatalf("speed up sent to %s with %x", tx.To().Hex(), tx.Data())
+			}
+		})
+	}
+
+	settled := *rec
+	settled.Status = txConfirmed
+	if _, err := replaceTransaction(context.Background(), &fakeTxClient{}, key, &settled, false); !errors.Is(err, errTxNotPending) {
+		t.Fatalf("replaced a settled transaction: %v", err)
+	}
+}
+
+func TestDescribeCall(t *testing.T) {
+	contractABI, err := ParseContractABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := contractABI.Pack("sendDataToTargets", []common.Address{testTo, testFrom}, [32]byte{1}, [32]byte{}, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := describeCall(contractABI, data)
+	if !strings.HasPrefix(got, "sendDataToTargets(") || !strings.Contains(got, "2 addresses") || !strings.Contains(got, "2 strings") {
+		t.Fatalf("described as %q", got)
+	}
+	if got := describeCall(contractABI, []byte{1, 2}); got != "" {
+		t.Fatalf("described unknown data as %q", got)
+	}
+}
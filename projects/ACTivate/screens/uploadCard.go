@@ -1,8 +1,8 @@
 package screens
 
 import (
-	"bytes"
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +12,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"github.com/ethersphere/bee/v2/pkg/swarm"
 )
@@ -22,6 +23,7 @@ type uploadedItem struct {
 	Size      int64
 	Timestamp time.Time
 	Mimetype  string
+	Encrypted bool
 }
 
 func (i *index) showUploadCard() *widget.Card {
@@ -33,12 +35,12 @@ func (i *index) showUploadCard() *widget.Card {
 func (i *index) uploadForm() *widget.Form {
 	filepath := ""
 	mimetype := ""
-	fileSize := int64(0)
 	var pathBind = binding.BindString(&filepath)
 	path := widget.NewEntry()
 	path.Bind(pathBind)
 	path.Disable()
-	var file io.Reader
+	// only the URI is kept, the file is streamed from it on submit
+	var fileURI fyne.URI
 	openFileButton := widget.NewButton("File Open", func() {
 		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 			if err != nil {
@@ -49,27 +51,25 @@ func (i *index) uploadForm() *widget.Form {
 				return
 			}
 			defer reader.Close()
-			data, err := io.ReadAll(reader)
-			if err != nil {
-				i.showError(err)
-				return
-			}
-			fileSize = int64(len(data))
 			mimetype = reader.URI().MimeType()
 			err = pathBind.Set(reader.URI().Name())
 			if err != nil {
 				i.showError(err)
 				return
 			}
-			file = bytes.NewReader(data)
+			fileURI = reader.URI()
 		}, i.Window)
 		fd.Show()
 	})
+	encryptCheck := widget.NewCheck("Encrypt for me and the grantees", nil)
+	paddingSelect := i.paddingPolicySelect()
 
 	upForm := &widget.Form{
 		Items: []*widget.FormItem{
 			{Text: "Add file", Widget: path, HintText: "Filepath"},
 			{Text: "Choose File", Widget: openFileButton},
+			{Text: "Encryption", Widget: encryptCheck, HintText: "End-to-end encryption on top of ACT"},
+			{Text: "Padding", Widget: paddingSelect, HintText: "Hides the size of encrypted files and messages"},
 		},
 	}
 	upForm.OnSubmit = func() {
@@ -79,9 +79,9 @@ func (i *index) uploadForm() *widget.Form {
 				if err != nil {
 					i.logger.Log(fmt.Sprintf("failed to bind path: %s", err.Error()))
 				}
-				file = nil
+				fileURI = nil
 			}()
-			if file == nil {
+			if fileURI == nil {
 				i.showError(fmt.Errorf("please select a file"))
 				return
 			}
@@ -93,12 +93,43 @@ func (i *index) uploadForm() *widget.Form {
 			filename := path.Text
 			i.logger.Log(fmt.Sprintf("stamp selected: %s", batchID))
 			i.showProgressWithMessage(fmt.Sprintf("Uploading %s", filename))
-			ref, _, err := i.bl.AddFileBzz(context.Background(), batchID, filename, mimetype, false, swarm.ZeroAddress, false, 0, file)
+
+			source, err := storage.Reader(fileURI)
 			if err != nil {
 				i.hideProgress()
 				i.showError(err)
 				return
 			}
+			defer source.Close()
+			file := &countingReader{r: source}
+
+			var body io.Reader = file
+			var stream *encryptedFileStream
+			contentType := mimetype
+			encrypted := encryptCheck.Checked
+			if encrypted {
+				encrypt, err := i.fileEncrypter(context.Background())
+				if err != nil {
+					i.hideProgress()
+					i.showError(err)
+					return
+				}
+				stream = i.encryptFileStream(file, encrypt)
+				// runs before source.Close, once the encryption stopped reading it
+				defer stream.Close()
+				body = stream
+				contentType = "application/octet-stream"
+			}
+
+			ref, _, err := i.bl.AddFileBzz(context.Background(), batchID, filename, contentType, false, swarm.ZeroAddress, false, 0, body)
+			if err != nil {
+				if stream != nil {
+					stream.CloseWithError(err)
+				}
+				i.hideProgress()
+				i.showError(err)
+				return
+			}
 			i.logger.Log(fmt.Sprintf("reference of the uploaded file: %s", ref.String()))
 			uploadedSrt := i.getPreferenceString(uploadsPrefKey)
 			uploads := []uploadedItem{}
@@ -112,8 +143,9 @@ func (i *index) uploadForm() *widget.Form {
 				Name:      filename,
 				Reference: ref.String(),
 				Timestamp: time.Now(),
-				Size:      fileSize,
+				Size:      file.n,
 				Mimetype:  mimetype,
+				Encrypted: encrypted,
 			})
 			data, err := json.Marshal(uploads)
 			if err != nil {
@@ -131,6 +163,91 @@ func (i *index) uploadForm() *widget.Form {
 	return upForm
 }
 
+// fileRecipients returns the keys uploaded files are encrypted for: this node and every grantee
+func (i *index) fileRecipients(ctx context.Context) ([]*ecdsa.PublicKey, error) {
+	grantees, err := i.granteePublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*ecdsa.PublicKey{i.bl.PublicKey()}, grantees...), nil
+}
+
+// fileEncrypter returns how uploaded files are encrypted: under the group
+// content key once a group key bundle was published, otherwise for this node
+// and every grantee individually
+func (i *index) fileEncrypter(ctx context.Context) (func(io.Writer) (io.WriteCloser, error), error) {
+	encryptionUtils := i.encryptionUtils()
+	groupKey, bundleRef, err := i.currentGroupKey()
+	if err != nil {
+		return nil, err
+	}
+	if groupKey != nil {
+		return func(w io.Writer) (io.WriteCloser, error) {
+			return encryptionUtils.EncryptGroupStream(w, groupKey, bundleRef, []*ecdsa.PublicKey{i.bl.PublicKey()})
+		}, nil
+	}
+
+	recipients, err := i.fileRecipients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return encryptionUtils.EncryptStream(w, recipients)
+	}, nil
+}
+
+// encryptFileStream encrypts src on the fly, segment by segment, so the file
+// is never held in memory as a whole. The stream must be closed, which stops
+// the encryption and returns once src is no longer read.
+func (i *index) encryptFileStream(src io.Reader, encrypt func(io.Writer) (io.WriteCloser, error)) *encryptedFileStream {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w, err := encrypt(pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Close())
+	}()
+	return &encryptedFileStream{PipeReader: pr, done: done}
+}
+
+// encryptedFileStream is the reading end of encryptFileStream
+type encryptedFileStream struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// CloseWithError stops the encryption, which fails its next write with err,
+// and waits for it to stop reading the source
+func (s *encryptedFileStream) CloseWithError(err error) error {
+	closeErr := s.PipeReader.CloseWithError(err)
+	<-s.done
+	return closeErr
+}
+
+func (s *encryptedFileStream) Close() error {
+	return s.CloseWithError(nil)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func (i *index) listUploadsButton(minSize fyne.Size) *widget.Button {
 	button := widget.NewButton("All Uploads", func() {
 		uploadedContent := container.NewVBox()
@@ -145,6 +262,9 @@ func (i *index) listUploadsButton(minSize fyne.Size) *widget.Button {
 			for _, v := range uploads {
 				ref := v.Reference
 				name := v.Name
+				if v.Encrypted {
+					name += " (encrypted)"
+				}
 				label := widget.NewLabel(fmt.Sprintf("%s\n%s", name, shortenHashOrAddress(ref)))
 				label.Wrapping = fyne.TextWrapWord
 				item := container.NewBorder(label, nil, nil, i.copyButton(ref))
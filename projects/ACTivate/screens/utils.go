@@ -1,6 +1,7 @@
 package screens
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"runtime/debug"
@@ -153,3 +154,44 @@ func (i *index) setPreference(key string, value interface{}) {
 		}
 	}
 }
+
+// loadSealedPreference decodes a preference stored with storeSealedPreference
+// into v, leaving v as is if the preference is not set
+func (i *index) loadSealedPreference(prefKey string, v any) error {
+	stored := i.getPreferenceString(prefKey)
+	if stored == "" {
+		return nil
+	}
+
+	key, err := i.nodePrivateKey()
+	if err != nil {
+		return err
+	}
+
+	encryptionUtils := &EncryptionUtils{}
+	data, err := encryptionUtils.DecryptString(stored, key)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt stored %s: %w", prefKey, err)
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// storeSealedPreference stores v in preferences encrypted to the node's own key
+func (i *index) storeSealedPreference(prefKey string, v any) error {
+	key, err := i.nodePrivateKey()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	encryptionUtils := &EncryptionUtils{}
+	sealed, err := encryptionUtils.EncryptString(string(data), &key.PublicKey)
+	if err != nil {
+		return err
+	}
+	i.setPreference(prefKey, sealed)
+	return nil
+}